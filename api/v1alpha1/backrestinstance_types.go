@@ -0,0 +1,151 @@
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// BackrestInstance is a cluster-scoped description of a Backrest server. Bindings select it by name
+// via spec.backrest.instanceRef instead of repeating the URL and credentials.
+type BackrestInstance struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   BackrestInstanceSpec   `json:"spec,omitempty"`
+	Status BackrestInstanceStatus `json:"status,omitempty"`
+}
+
+type BackrestInstanceList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []BackrestInstance `json:"items"`
+}
+
+type BackrestInstanceSpec struct {
+	URL string `json:"url"`
+
+	// AuthRef references a Secret with either 'token' or 'username'/'password' keys.
+	// BackrestInstance is cluster-scoped, so the Secret namespace must be set explicitly.
+	AuthRef *NamespacedSecretRef `json:"authRef,omitempty"`
+
+	TLS *BackrestTLSSpec `json:"tls,omitempty"`
+
+	// TimeoutSeconds overrides the Backrest API request timeout. Defaults to 120 seconds.
+	// Repo tasks are effectively synchronous, so short timeouts are not recommended.
+	TimeoutSeconds *int32 `json:"timeoutSeconds,omitempty"`
+
+	// RateLimit caps the request rate the operator sends to this instance across all bindings.
+	RateLimit *BackrestRateLimitSpec `json:"rateLimit,omitempty"`
+}
+
+type NamespacedSecretRef struct {
+	Namespace string `json:"namespace"`
+	Name      string `json:"name"`
+}
+
+type BackrestTLSSpec struct {
+	// InsecureSkipVerify disables server certificate verification.
+	InsecureSkipVerify bool `json:"insecureSkipVerify,omitempty"`
+	// CASecretRef references a Secret whose 'ca.crt' key holds PEM encoded CA certificates.
+	CASecretRef *NamespacedSecretRef `json:"caSecretRef,omitempty"`
+	// ServerName overrides the name used to verify the server certificate.
+	ServerName string `json:"serverName,omitempty"`
+}
+
+type BackrestRateLimitSpec struct {
+	// QPS is the sustained number of requests per second.
+	QPS int32 `json:"qps"`
+	// Burst is the maximum number of requests sent at once. Defaults to QPS.
+	Burst int32 `json:"burst,omitempty"`
+}
+
+type BackrestInstanceStatus struct {
+	ObservedGeneration int64              `json:"observedGeneration,omitempty"`
+	Conditions         []metav1.Condition `json:"conditions,omitempty"`
+
+	// InstanceID is the instance name Backrest reports in its config.
+	InstanceID string `json:"instanceID,omitempty"`
+	// ConfigVersion is the Backrest config format version. Backrest does not expose its release
+	// version over the API; this is the closest stable signal.
+	ConfigVersion int32 `json:"configVersion,omitempty"`
+	// RepoCount is the total number of repos in the Backrest config.
+	RepoCount int32 `json:"repoCount,omitempty"`
+	// BoundRepoCount is the number of repos in the Backrest config that belong to bindings selecting this instance.
+	BoundRepoCount int32        `json:"boundRepoCount,omitempty"`
+	LastProbeTime  *metav1.Time `json:"lastProbeTime,omitempty"`
+	LastErrorHash  string       `json:"lastErrorHash,omitempty"`
+}
+
+// DeepCopyInto, DeepCopy, and DeepCopyObject are implemented manually to avoid requiring codegen.
+func (in *BackrestInstance) DeepCopyInto(out *BackrestInstance) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	out.Spec = in.Spec
+	if in.Spec.AuthRef != nil {
+		v := *in.Spec.AuthRef
+		out.Spec.AuthRef = &v
+	}
+	if in.Spec.TLS != nil {
+		v := *in.Spec.TLS
+		if in.Spec.TLS.CASecretRef != nil {
+			ref := *in.Spec.TLS.CASecretRef
+			v.CASecretRef = &ref
+		}
+		out.Spec.TLS = &v
+	}
+	if in.Spec.TimeoutSeconds != nil {
+		v := *in.Spec.TimeoutSeconds
+		out.Spec.TimeoutSeconds = &v
+	}
+	if in.Spec.RateLimit != nil {
+		v := *in.Spec.RateLimit
+		out.Spec.RateLimit = &v
+	}
+	out.Status = in.Status
+	if in.Status.Conditions != nil {
+		out.Status.Conditions = make([]metav1.Condition, len(in.Status.Conditions))
+		copy(out.Status.Conditions, in.Status.Conditions)
+	}
+	if in.Status.LastProbeTime != nil {
+		out.Status.LastProbeTime = in.Status.LastProbeTime.DeepCopy()
+	}
+}
+
+func (in *BackrestInstance) DeepCopy() *BackrestInstance {
+	if in == nil {
+		return nil
+	}
+	out := new(BackrestInstance)
+	in.DeepCopyInto(out)
+	return out
+}
+
+func (in *BackrestInstance) DeepCopyObject() runtime.Object {
+	return in.DeepCopy()
+}
+
+func (in *BackrestInstanceList) DeepCopyInto(out *BackrestInstanceList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		out.Items = make([]BackrestInstance, len(in.Items))
+		for i := range in.Items {
+			in.Items[i].DeepCopyInto(&out.Items[i])
+		}
+	}
+}
+
+func (in *BackrestInstanceList) DeepCopy() *BackrestInstanceList {
+	if in == nil {
+		return nil
+	}
+	out := new(BackrestInstanceList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+func (in *BackrestInstanceList) DeepCopyObject() runtime.Object {
+	return in.DeepCopy()
+}
@@ -20,14 +20,33 @@ type BackrestVolSyncBindingList struct {
 }
 
 type BackrestVolSyncBindingSpec struct {
-	Backrest BackrestConnection `json:"backrest"`
-	Source   VolSyncSourceRef   `json:"source"`
-	Repo     BackrestRepoSpec   `json:"repo,omitempty"`
+	// Backrest is the primary Backrest target. It may be left empty when Targets is set.
+	Backrest BackrestConnection `json:"backrest,omitempty"`
+	// Targets registers the same repository in additional Backrest instances. Each target is
+	// applied independently and reported under status.targets. Removing a target leaves the repo
+	// registered in its Backrest.
+	Targets []BackrestTarget `json:"targets,omitempty"`
+	Source  VolSyncSourceRef `json:"source"`
+	Repo    BackrestRepoSpec `json:"repo,omitempty"`
+}
+
+type BackrestTarget struct {
+	// Name identifies the target in status.targets. Must be unique within the binding;
+	// "default" is reserved for spec.backrest.
+	Name               string `json:"name"`
+	BackrestConnection `json:",inline"`
 }
 
 type BackrestConnection struct {
-	URL     string     `json:"url"`
+	URL     string     `json:"url,omitempty"`
 	AuthRef *SecretRef `json:"authRef,omitempty"`
+	// InstanceRef selects a cluster-scoped BackrestInstance for URL, auth, TLS and rate limits.
+	// Mutually exclusive with url and authRef.
+	InstanceRef *InstanceRef `json:"instanceRef,omitempty"`
+}
+
+type InstanceRef struct {
+	Name string `json:"name"`
 }
 
 type SecretRef struct {
@@ -50,18 +69,114 @@ type BackrestRepoSpec struct {
 	AutoUnlock     *bool `json:"autoUnlock,omitempty"`
 	AutoInitialize *bool `json:"autoInitialize,omitempty"`
 	// TriggerTasksOnSnapshot enables enqueueing Backrest INDEX_SNAPSHOTS and STATS
-	// tasks when a bound ReplicationSource reports a new completed snapshot/sync marker.
+	// tasks when the bound ReplicationSource reports a new completed snapshot/sync marker,
+	// or the bound ReplicationDestination completes a new restore.
 	// Disabled by default.
 	TriggerTasksOnSnapshot *bool    `json:"triggerTasksOnSnapshot,omitempty"`
 	ExtraFlags             []string `json:"extraFlags,omitempty"`
 	EnvAllowlist           []string `json:"envAllowlist,omitempty"`
+
+	// URIRewrites are applied in order to RESTIC_REPOSITORY before it is registered in Backrest,
+	// after the operator-wide rules from BackrestVolSyncOperatorConfig spec.uriRewrites.
+	URIRewrites []URIRewriteRule `json:"uriRewrites,omitempty"`
+
+	// EnvFrom adds Backrest repo env entries from other Secrets/ConfigMaps in the binding's namespace.
+	// Later entries override earlier ones; keys from the VolSync repository Secret override all of them.
+	EnvFrom []EnvFromSource `json:"envFrom,omitempty"`
+}
+
+// EnvFromSource reads env entries from a Secret or ConfigMap. Exactly one of SecretRef or ConfigMapRef must be set.
+type EnvFromSource struct {
+	SecretRef    *SecretRef    `json:"secretRef,omitempty"`
+	ConfigMapRef *ConfigMapRef `json:"configMapRef,omitempty"`
+	// Keys restricts which keys are read. All keys are read when empty.
+	Keys []string `json:"keys,omitempty"`
+	// Prefix is prepended to every key to form the env name.
+	Prefix string `json:"prefix,omitempty"`
+	// Optional tolerates a missing Secret or ConfigMap.
+	Optional bool `json:"optional,omitempty"`
+}
+
+type ConfigMapRef struct {
+	Name string `json:"name"`
+}
+
+func (in *BackrestRepoSpec) DeepCopyInto(out *BackrestRepoSpec) {
+	*out = *in
+	if in.AutoUnlock != nil {
+		v := *in.AutoUnlock
+		out.AutoUnlock = &v
+	}
+	if in.AutoInitialize != nil {
+		v := *in.AutoInitialize
+		out.AutoInitialize = &v
+	}
+	if in.TriggerTasksOnSnapshot != nil {
+		v := *in.TriggerTasksOnSnapshot
+		out.TriggerTasksOnSnapshot = &v
+	}
+	if in.ExtraFlags != nil {
+		out.ExtraFlags = append([]string(nil), in.ExtraFlags...)
+	}
+	if in.EnvAllowlist != nil {
+		out.EnvAllowlist = append([]string(nil), in.EnvAllowlist...)
+	}
+	if in.URIRewrites != nil {
+		out.URIRewrites = append([]URIRewriteRule(nil), in.URIRewrites...)
+	}
+	out.EnvFrom = DeepCopyEnvFrom(in.EnvFrom)
+}
+
+func (in *EnvFromSource) DeepCopyInto(out *EnvFromSource) {
+	*out = *in
+	if in.SecretRef != nil {
+		out.SecretRef = &SecretRef{Name: in.SecretRef.Name}
+	}
+	if in.ConfigMapRef != nil {
+		out.ConfigMapRef = &ConfigMapRef{Name: in.ConfigMapRef.Name}
+	}
+	if in.Keys != nil {
+		out.Keys = append([]string(nil), in.Keys...)
+	}
+}
+
+// DeepCopyEnvFrom copies an EnvFrom list.
+func DeepCopyEnvFrom(in []EnvFromSource) []EnvFromSource {
+	if in == nil {
+		return nil
+	}
+	out := make([]EnvFromSource, len(in))
+	for i := range in {
+		in[i].DeepCopyInto(&out[i])
+	}
+	return out
+}
+
+// URIRewriteRule rewrites the restic repository URI, for example to replace an in-cluster
+// endpoint with one reachable from Backrest. Exactly one of Match or Regex must be set.
+type URIRewriteRule struct {
+	// Match is a literal substring; every occurrence is replaced with Replace.
+	Match string `json:"match,omitempty"`
+	// Regex is a Go regular expression; Replace may reference capture groups ($1, ${name}).
+	Regex   string `json:"regex,omitempty"`
+	Replace string `json:"replace"`
 }
 
 type BackrestVolSyncBindingStatus struct {
 	ObservedGeneration int64              `json:"observedGeneration,omitempty"`
 	Conditions         []metav1.Condition `json:"conditions,omitempty"`
 
-	ResolvedRepositorySecret    string       `json:"resolvedRepositorySecret,omitempty"`
+	ResolvedRepositorySecret string `json:"resolvedRepositorySecret,omitempty"`
+	// RepoID is the Backrest repo ID in use. Once set it only changes through spec.repo.idOverride
+	// or the backrest.garethgeorge.com/repo-id-migrate annotation.
+	RepoID string `json:"repoID,omitempty"`
+	// RepoShareKey groups bindings of the same restic repository when repo sharing is enabled.
+	// It is a keyed digest; the URI and password cannot be derived from it.
+	RepoShareKey string `json:"repoShareKey,omitempty"`
+	// RepoReferences is the number of bindings using RepoID, including this one.
+	RepoReferences int32 `json:"repoReferences,omitempty"`
+	// ResolvedRepositoryURI is the repository URI after uriRewrites, with any password redacted.
+	ResolvedRepositoryURI       string       `json:"resolvedRepositoryURI,omitempty"`
 	LastAppliedInputHash        string       `json:"lastAppliedInputHash,omitempty"`
 	LastApplyTime               *metav1.Time `json:"lastApplyTime,omitempty"`
 	LastErrorHash               string       `json:"lastErrorHash,omitempty"`
@@ -71,6 +186,115 @@ type BackrestVolSyncBindingStatus struct {
 	LastSnapshotSyncTime        string       `json:"lastSnapshotSyncTime,omitempty"`
 	LastRepoTaskTriggerTime     *metav1.Time `json:"lastRepoTaskTriggerTime,omitempty"`
 	LastRepoTaskErrorHash       string       `json:"lastRepoTaskErrorHash,omitempty"`
+
+	// SecretKeys reports how the repository Secret keys were handed to Backrest.
+	SecretKeys *RepositorySecretKeys `json:"secretKeys,omitempty"`
+
+	// Sync mirrors the sync status of the bound VolSync object.
+	Sync *VolSyncStatus `json:"sync,omitempty"`
+
+	// ManualSync reports progress of the manual sync requested with the
+	// backrest.garethgeorge.com/sync-request annotation.
+	ManualSync *ManualSyncStatus `json:"manualSync,omitempty"`
+
+	// LastRestoredSnapshot and LastRestoreTime record the latest completed restore of a bound
+	// ReplicationDestination: its status.latestImage name and lastSyncTime.
+	LastRestoredSnapshot string `json:"lastRestoredSnapshot,omitempty"`
+	LastRestoreTime      string `json:"lastRestoreTime,omitempty"`
+
+	// Targets reports apply and task-trigger state per Backrest target. The top-level
+	// apply and snapshot fields mirror the first target for compatibility.
+	Targets []BackrestTargetStatus `json:"targets,omitempty"`
+}
+
+// RepositorySecretKeys lists repository Secret key names by how they were handled.
+// Only names are reported, never values.
+type RepositorySecretKeys struct {
+	// Forwarded keys are passed to Backrest as repo env entries.
+	Forwarded []string `json:"forwarded,omitempty"`
+	// Mapped keys are translated into restic flags on the Backrest repo.
+	Mapped []string `json:"mapped,omitempty"`
+	// Dropped keys are ignored (denied, unknown RESTIC_* keys, or not in envAllowlist).
+	Dropped []string `json:"dropped,omitempty"`
+}
+
+func (in *RepositorySecretKeys) DeepCopy() *RepositorySecretKeys {
+	if in == nil {
+		return nil
+	}
+	return &RepositorySecretKeys{
+		Forwarded: append([]string(nil), in.Forwarded...),
+		Mapped:    append([]string(nil), in.Mapped...),
+		Dropped:   append([]string(nil), in.Dropped...),
+	}
+}
+
+// VolSyncStatus is the sync state reported by the bound ReplicationSource or ReplicationDestination.
+type VolSyncStatus struct {
+	LastSyncTime      string `json:"lastSyncTime,omitempty"`
+	LastSyncStartTime string `json:"lastSyncStartTime,omitempty"`
+	LastSyncDuration  string `json:"lastSyncDuration,omitempty"`
+	NextSyncTime      string `json:"nextSyncTime,omitempty"`
+	LastManualSync    string `json:"lastManualSync,omitempty"`
+	// LastResult is the result of the latest mover run (Successful or Failed).
+	LastResult string `json:"lastResult,omitempty"`
+	// Synchronizing and SynchronizingReason mirror VolSync's Synchronizing condition.
+	Synchronizing       string `json:"synchronizing,omitempty"`
+	SynchronizingReason string `json:"synchronizingReason,omitempty"`
+}
+
+func (in *VolSyncStatus) DeepCopy() *VolSyncStatus {
+	if in == nil {
+		return nil
+	}
+	out := *in
+	return &out
+}
+
+// ManualSyncStatus tracks one manual sync request.
+type ManualSyncStatus struct {
+	// Request is the annotation value this status belongs to.
+	Request string `json:"request,omitempty"`
+	// Trigger is the value written to the ReplicationSource spec.trigger.manual.
+	Trigger string `json:"trigger,omitempty"`
+	// Phase is Pending, Syncing, Indexing, Completed or Failed.
+	Phase          string       `json:"phase,omitempty"`
+	Message        string       `json:"message,omitempty"`
+	RequestTime    *metav1.Time `json:"requestTime,omitempty"`
+	CompletionTime *metav1.Time `json:"completionTime,omitempty"`
+}
+
+func (in *ManualSyncStatus) DeepCopy() *ManualSyncStatus {
+	if in == nil {
+		return nil
+	}
+	out := *in
+	if in.RequestTime != nil {
+		out.RequestTime = in.RequestTime.DeepCopy()
+	}
+	if in.CompletionTime != nil {
+		out.CompletionTime = in.CompletionTime.DeepCopy()
+	}
+	return &out
+}
+
+type BackrestTargetStatus struct {
+	Name       string             `json:"name"`
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+
+	LastAppliedInputHash        string       `json:"lastAppliedInputHash,omitempty"`
+	LastApplyTime               *metav1.Time `json:"lastApplyTime,omitempty"`
+	LastErrorHash               string       `json:"lastErrorHash,omitempty"`
+	LastIndexedSnapshotMarker   string       `json:"lastIndexedSnapshotMarker,omitempty"`
+	LastIndexedSnapshotSyncTime string       `json:"lastIndexedSnapshotSyncTime,omitempty"`
+	LastSnapshotMarker          string       `json:"lastSnapshotMarker,omitempty"`
+	LastSnapshotSyncTime        string       `json:"lastSnapshotSyncTime,omitempty"`
+	LastRepoTaskTriggerTime     *metav1.Time `json:"lastRepoTaskTriggerTime,omitempty"`
+	LastRepoTaskErrorHash       string       `json:"lastRepoTaskErrorHash,omitempty"`
+
+	// LastAppliedCredentialDigest is a keyed digest of the repository URI and password last
+	// registered in this target. A change triggers validation before the new password is applied.
+	LastAppliedCredentialDigest string `json:"lastAppliedCredentialDigest,omitempty"`
 }
 
 // DeepCopyInto, DeepCopy, and DeepCopyObject are implemented manually to avoid requiring codegen.
@@ -83,6 +307,10 @@ func (in *BackrestVolSyncBinding) DeepCopyInto(out *BackrestVolSyncBinding) {
 	out.Status = BackrestVolSyncBindingStatus{
 		ObservedGeneration:          in.Status.ObservedGeneration,
 		ResolvedRepositorySecret:    in.Status.ResolvedRepositorySecret,
+		ResolvedRepositoryURI:       in.Status.ResolvedRepositoryURI,
+		RepoID:                      in.Status.RepoID,
+		RepoShareKey:                in.Status.RepoShareKey,
+		RepoReferences:              in.Status.RepoReferences,
 		LastAppliedInputHash:        in.Status.LastAppliedInputHash,
 		LastErrorHash:               in.Status.LastErrorHash,
 		LastIndexedSnapshotMarker:   in.Status.LastIndexedSnapshotMarker,
@@ -90,6 +318,8 @@ func (in *BackrestVolSyncBinding) DeepCopyInto(out *BackrestVolSyncBinding) {
 		LastSnapshotMarker:          in.Status.LastSnapshotMarker,
 		LastSnapshotSyncTime:        in.Status.LastSnapshotSyncTime,
 		LastRepoTaskErrorHash:       in.Status.LastRepoTaskErrorHash,
+		LastRestoredSnapshot:        in.Status.LastRestoredSnapshot,
+		LastRestoreTime:             in.Status.LastRestoreTime,
 	}
 	if in.Status.LastApplyTime != nil {
 		out.Status.LastApplyTime = in.Status.LastApplyTime.DeepCopy()
@@ -101,15 +331,37 @@ func (in *BackrestVolSyncBinding) DeepCopyInto(out *BackrestVolSyncBinding) {
 		out.Status.Conditions = make([]metav1.Condition, len(in.Status.Conditions))
 		copy(out.Status.Conditions, in.Status.Conditions)
 	}
+	out.Status.SecretKeys = in.Status.SecretKeys.DeepCopy()
+	out.Status.Sync = in.Status.Sync.DeepCopy()
+	out.Status.ManualSync = in.Status.ManualSync.DeepCopy()
 	if in.Spec.Repo.ExtraFlags != nil {
 		out.Spec.Repo.ExtraFlags = append([]string(nil), in.Spec.Repo.ExtraFlags...)
 	}
 	if in.Spec.Repo.EnvAllowlist != nil {
 		out.Spec.Repo.EnvAllowlist = append([]string(nil), in.Spec.Repo.EnvAllowlist...)
 	}
+	if in.Spec.Repo.URIRewrites != nil {
+		out.Spec.Repo.URIRewrites = append([]URIRewriteRule(nil), in.Spec.Repo.URIRewrites...)
+	}
+	out.Spec.Repo.EnvFrom = DeepCopyEnvFrom(in.Spec.Repo.EnvFrom)
 	if in.Spec.Backrest.AuthRef != nil {
 		out.Spec.Backrest.AuthRef = &SecretRef{Name: in.Spec.Backrest.AuthRef.Name}
 	}
+	if in.Spec.Backrest.InstanceRef != nil {
+		out.Spec.Backrest.InstanceRef = &InstanceRef{Name: in.Spec.Backrest.InstanceRef.Name}
+	}
+	if in.Spec.Targets != nil {
+		out.Spec.Targets = make([]BackrestTarget, len(in.Spec.Targets))
+		for i := range in.Spec.Targets {
+			in.Spec.Targets[i].DeepCopyInto(&out.Spec.Targets[i])
+		}
+	}
+	if in.Status.Targets != nil {
+		out.Status.Targets = make([]BackrestTargetStatus, len(in.Status.Targets))
+		for i := range in.Status.Targets {
+			in.Status.Targets[i].DeepCopyInto(&out.Status.Targets[i])
+		}
+	}
 	if in.Spec.Repo.AutoUnlock != nil {
 		v := *in.Spec.Repo.AutoUnlock
 		out.Spec.Repo.AutoUnlock = &v
@@ -124,6 +376,30 @@ func (in *BackrestVolSyncBinding) DeepCopyInto(out *BackrestVolSyncBinding) {
 	}
 }
 
+func (in *BackrestTarget) DeepCopyInto(out *BackrestTarget) {
+	*out = *in
+	if in.AuthRef != nil {
+		out.AuthRef = &SecretRef{Name: in.AuthRef.Name}
+	}
+	if in.InstanceRef != nil {
+		out.InstanceRef = &InstanceRef{Name: in.InstanceRef.Name}
+	}
+}
+
+func (in *BackrestTargetStatus) DeepCopyInto(out *BackrestTargetStatus) {
+	*out = *in
+	if in.Conditions != nil {
+		out.Conditions = make([]metav1.Condition, len(in.Conditions))
+		copy(out.Conditions, in.Conditions)
+	}
+	if in.LastApplyTime != nil {
+		out.LastApplyTime = in.LastApplyTime.DeepCopy()
+	}
+	if in.LastRepoTaskTriggerTime != nil {
+		out.LastRepoTaskTriggerTime = in.LastRepoTaskTriggerTime.DeepCopy()
+	}
+}
+
 func (in *BackrestVolSyncBinding) DeepCopy() *BackrestVolSyncBinding {
 	if in == nil {
 		return nil
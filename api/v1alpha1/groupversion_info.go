@@ -23,6 +23,10 @@ func addKnownTypes(s *runtime.Scheme) error {
 		&BackrestVolSyncBindingList{},
 		&BackrestVolSyncOperatorConfig{},
 		&BackrestVolSyncOperatorConfigList{},
+		&BackrestInstance{},
+		&BackrestInstanceList{},
+		&BackrestVolSyncNamespaceConfig{},
+		&BackrestVolSyncNamespaceConfigList{},
 	)
 	metav1.AddToGroupVersion(s, GroupVersion)
 	return nil
@@ -0,0 +1,122 @@
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// BackrestVolSyncNamespaceConfig overrides the auto-binding defaults of the cluster
+// BackrestVolSyncOperatorConfig for VolSync objects in its namespace. Fields that are unset
+// inherit the cluster value; fields locked by the cluster config are ignored.
+type BackrestVolSyncNamespaceConfig struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   BackrestVolSyncNamespaceConfigSpec   `json:"spec,omitempty"`
+	Status BackrestVolSyncNamespaceConfigStatus `json:"status,omitempty"`
+}
+
+type BackrestVolSyncNamespaceConfigList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []BackrestVolSyncNamespaceConfig `json:"items"`
+}
+
+type BackrestVolSyncNamespaceConfigSpec struct {
+	// DefaultBackrest replaces the cluster defaultBackrest as a whole. authRef names a Secret in
+	// this namespace.
+	DefaultBackrest *BackrestConnection `json:"defaultBackrest,omitempty"`
+
+	BindingGeneration NamespaceBindingGenerationSpec `json:"bindingGeneration,omitempty"`
+}
+
+type NamespaceBindingGenerationSpec struct {
+	// Policy overrides the cluster policy (Disabled, Annotated or All). A cluster policy of
+	// Disabled turns auto-binding off everywhere regardless of this field.
+	Policy string `json:"policy,omitempty"`
+
+	// Kinds overrides the cluster kinds when non-empty.
+	Kinds []string `json:"kinds,omitempty"`
+
+	// DefaultRepo is merged field by field over the cluster defaultRepo: set fields win, lists
+	// replace the cluster list.
+	DefaultRepo BackrestRepoSpec `json:"defaultRepo,omitempty"`
+
+	// RepoIDTemplate overrides the cluster repoIDTemplate.
+	RepoIDTemplate string `json:"repoIDTemplate,omitempty"`
+}
+
+type BackrestVolSyncNamespaceConfigStatus struct {
+	ObservedGeneration int64              `json:"observedGeneration,omitempty"`
+	Conditions         []metav1.Condition `json:"conditions,omitempty"`
+	// IgnoredFields lists the fields set here that the cluster config locks.
+	IgnoredFields []string `json:"ignoredFields,omitempty"`
+}
+
+// DeepCopyInto, DeepCopy, and DeepCopyObject are implemented manually to avoid requiring codegen.
+func (in *BackrestVolSyncNamespaceConfig) DeepCopyInto(out *BackrestVolSyncNamespaceConfig) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	out.Spec = in.Spec
+	if in.Spec.DefaultBackrest != nil {
+		v := *in.Spec.DefaultBackrest
+		if in.Spec.DefaultBackrest.AuthRef != nil {
+			v.AuthRef = &SecretRef{Name: in.Spec.DefaultBackrest.AuthRef.Name}
+		}
+		if in.Spec.DefaultBackrest.InstanceRef != nil {
+			v.InstanceRef = &InstanceRef{Name: in.Spec.DefaultBackrest.InstanceRef.Name}
+		}
+		out.Spec.DefaultBackrest = &v
+	}
+	if in.Spec.BindingGeneration.Kinds != nil {
+		out.Spec.BindingGeneration.Kinds = append([]string(nil), in.Spec.BindingGeneration.Kinds...)
+	}
+	in.Spec.BindingGeneration.DefaultRepo.DeepCopyInto(&out.Spec.BindingGeneration.DefaultRepo)
+	out.Status = in.Status
+	if in.Status.Conditions != nil {
+		out.Status.Conditions = make([]metav1.Condition, len(in.Status.Conditions))
+		copy(out.Status.Conditions, in.Status.Conditions)
+	}
+	if in.Status.IgnoredFields != nil {
+		out.Status.IgnoredFields = append([]string(nil), in.Status.IgnoredFields...)
+	}
+}
+
+func (in *BackrestVolSyncNamespaceConfig) DeepCopy() *BackrestVolSyncNamespaceConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(BackrestVolSyncNamespaceConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+func (in *BackrestVolSyncNamespaceConfig) DeepCopyObject() runtime.Object {
+	return in.DeepCopy()
+}
+
+func (in *BackrestVolSyncNamespaceConfigList) DeepCopyInto(out *BackrestVolSyncNamespaceConfigList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		out.Items = make([]BackrestVolSyncNamespaceConfig, len(in.Items))
+		for i := range in.Items {
+			in.Items[i].DeepCopyInto(&out.Items[i])
+		}
+	}
+}
+
+func (in *BackrestVolSyncNamespaceConfigList) DeepCopy() *BackrestVolSyncNamespaceConfigList {
+	if in == nil {
+		return nil
+	}
+	out := new(BackrestVolSyncNamespaceConfigList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+func (in *BackrestVolSyncNamespaceConfigList) DeepCopyObject() runtime.Object {
+	return in.DeepCopy()
+}
@@ -27,6 +27,68 @@ type BackrestVolSyncOperatorConfigSpec struct {
 	DefaultBackrest BackrestConnection `json:"defaultBackrest,omitempty"`
 
 	BindingGeneration BindingGenerationSpec `json:"bindingGeneration,omitempty"`
+
+	// URIRewrites are applied in order to RESTIC_REPOSITORY of every binding, before the
+	// binding's own spec.repo.uriRewrites.
+	URIRewrites []URIRewriteRule `json:"uriRewrites,omitempty"`
+
+	// RepoSharing registers bindings that point at the same restic repository as one Backrest repo.
+	RepoSharing RepoSharingSpec `json:"repoSharing,omitempty"`
+
+	// CompletionMarker customizes how completed VolSync syncs are detected, for VolSync forks and
+	// wrapper controllers with a different status layout.
+	CompletionMarker CompletionMarkerSpec `json:"completionMarker,omitempty"`
+
+	// NamespaceOverrides controls what BackrestVolSyncNamespaceConfig resources may change.
+	NamespaceOverrides NamespaceOverridesSpec `json:"namespaceOverrides,omitempty"`
+
+	// ClusterName identifies this cluster in bindingGeneration.repoIDTemplate ({{ .Cluster }}).
+	ClusterName string `json:"clusterName,omitempty"`
+}
+
+type RepoSharingSpec struct {
+	// Match selects which bindings share a Backrest repo.
+	//
+	// Allowed values:
+	// - Disabled: every binding gets its own repo ID (default)
+	// - URI: bindings with the same resolved RESTIC_REPOSITORY share one repo ID
+	// - URIAndPassword: as URI, and RESTIC_PASSWORD must match too
+	Match string `json:"match,omitempty"`
+
+	// RemoveUnreferencedRepos removes a repo from Backrest when the last binding using its repo ID
+	// is deleted. Bindings get a finalizer while enabled. Disabled by default.
+	RemoveUnreferencedRepos bool `json:"removeUnreferencedRepos,omitempty"`
+}
+
+type NamespaceOverridesSpec struct {
+	// LockedFields lists BackrestVolSyncNamespaceConfig spec fields that namespaces may not
+	// override, e.g. "defaultBackrest", "bindingGeneration.policy" or
+	// "bindingGeneration.defaultRepo.extraFlags". "bindingGeneration.defaultRepo" locks all of
+	// defaultRepo.
+	LockedFields []string `json:"lockedFields,omitempty"`
+}
+
+type CompletionMarkerSpec struct {
+	// Rules are tried in order on ReplicationSources; the first one yielding a non-empty string
+	// becomes the marker "<name>=<value>". Defaults to lastSnapshotID, lastSnapshot, latestImage
+	// (.status.latestImage.name) and lastManualSync.
+	Rules []MarkerRule `json:"rules,omitempty"`
+
+	// DestinationRules are tried in order on ReplicationDestinations; the first one yielding a
+	// non-empty string is combined with the last sync time into "<name>=<value>,lastSyncTime=<time>".
+	// Defaults to latestImage (.status.latestImage.name).
+	DestinationRules []MarkerRule `json:"destinationRules,omitempty"`
+
+	// SyncTime lists JSONPath expressions for the last sync time of both kinds, tried in order.
+	// Defaults to {.status.lastSyncTime}.
+	SyncTime []string `json:"syncTime,omitempty"`
+}
+
+type MarkerRule struct {
+	// Name prefixes the marker value, e.g. "lastSnapshotID".
+	Name string `json:"name"`
+	// JSONPath is a kubectl-style JSONPath expression, e.g. {.status.lastSnapshotID}.
+	JSONPath string `json:"jsonPath"`
 }
 
 type BindingGenerationSpec struct {
@@ -47,13 +109,88 @@ type BindingGenerationSpec struct {
 	// If omitted or empty, both kinds are allowed.
 	Kinds []string `json:"kinds,omitempty"`
 
+	// NamespaceSelector restricts auto-binding to VolSync objects in namespaces matching the
+	// selector. Omitted selects all namespaces.
+	NamespaceSelector *metav1.LabelSelector `json:"namespaceSelector,omitempty"`
+
+	// ObjectSelector restricts auto-binding to VolSync objects whose labels match the selector.
+	// Omitted selects all objects.
+	ObjectSelector *metav1.LabelSelector `json:"objectSelector,omitempty"`
+
+	// OrphanPolicy controls managed bindings whose VolSync object is no longer eligible (policy
+	// Disabled, kind removed, opt-out annotation, or outside the selectors).
+	//
+	// Allowed values:
+	// - Keep: leave the binding as it is (default)
+	// - Detach: detach the binding; it keeps working but is no longer managed by the operator until
+	//   the VolSync object is eligible again
+	// - Delete: delete the binding
+	OrphanPolicy string `json:"orphanPolicy,omitempty"`
+
+	// AllowedOverrides lists the override annotations on VolSync objects that are honored for
+	// generated bindings: repoID, triggerTasksOnSnapshot, envAllowlist, extraFlags and
+	// backrestInstance. Empty honors none.
+	AllowedOverrides []string `json:"allowedOverrides,omitempty"`
+
+	// DryRun computes the bindings auto-binding would create, update or delete without writing
+	// them. The result is reported in status.dryRun and as events on the VolSync objects.
+	DryRun bool `json:"dryRun,omitempty"`
+
 	// DefaultRepo provides defaults for generated bindings. Fields are optional.
 	DefaultRepo BackrestRepoSpec `json:"defaultRepo,omitempty"`
+
+	// RepoIDTemplate is a Go text/template for the Backrest repo ID of generated bindings. It can
+	// use .Namespace, .Kind, .Name, .PVC, .Cluster, .Labels and .Annotations, and the functions
+	// lower and replace. The ID is resolved once and kept in the binding status.
+	RepoIDTemplate string `json:"repoIDTemplate,omitempty"`
 }
 
 type BackrestVolSyncOperatorConfigStatus struct {
 	ObservedGeneration int64              `json:"observedGeneration,omitempty"`
 	Conditions         []metav1.Condition `json:"conditions,omitempty"`
+
+	// Bindings counts all BackrestVolSyncBindings; ManagedBindings those generated by auto-binding.
+	Bindings        int32 `json:"bindings,omitempty"`
+	ManagedBindings int32 `json:"managedBindings,omitempty"`
+	// ReadyBindings and FailingBindings count bindings whose Ready condition is True, or False for
+	// a reason other than Paused.
+	ReadyBindings   int32 `json:"readyBindings,omitempty"`
+	FailingBindings int32 `json:"failingBindings,omitempty"`
+	// BackrestInstances counts the distinct Backrest instances (instanceRef names and URLs) bindings
+	// register repos in.
+	BackrestInstances int32 `json:"backrestInstances,omitempty"`
+	// FailingBindingSample lists some failing bindings as namespace/name, sorted.
+	FailingBindingSample []string `json:"failingBindingSample,omitempty"`
+
+	// DryRun reports what auto-binding would change while spec.bindingGeneration.dryRun is set.
+	DryRun *DryRunStatus `json:"dryRun,omitempty"`
+
+	// AutoBindingSkips summarizes why auto-binding skipped VolSync objects.
+	AutoBindingSkips *AutoBindingSkipStatus `json:"autoBindingSkips,omitempty"`
+}
+
+// DryRunStatus lists bindings as namespace/name. The lists are capped; the totals are not.
+type DryRunStatus struct {
+	WouldCreate      []string `json:"wouldCreate,omitempty"`
+	WouldUpdate      []string `json:"wouldUpdate,omitempty"`
+	WouldDelete      []string `json:"wouldDelete,omitempty"`
+	WouldCreateTotal int32    `json:"wouldCreateTotal,omitempty"`
+	WouldUpdateTotal int32    `json:"wouldUpdateTotal,omitempty"`
+	WouldDeleteTotal int32    `json:"wouldDeleteTotal,omitempty"`
+}
+
+// AutoBindingSkipStatus counts the VolSync objects auto-binding skipped, per reason.
+type AutoBindingSkipStatus struct {
+	Total   int32             `json:"total,omitempty"`
+	Reasons []SkipReasonCount `json:"reasons,omitempty"`
+}
+
+type SkipReasonCount struct {
+	// Reason is e.g. PolicyDisabled, KindNotAllowed, NotAnnotated or NoDefaultBackrest.
+	Reason string `json:"reason"`
+	Count  int32  `json:"count"`
+	// Objects lists some skipped objects as "<kind> <namespace>/<name>", sorted.
+	Objects []string `json:"objects,omitempty"`
 }
 
 // DeepCopyInto, DeepCopy, and DeepCopyObject are implemented manually to avoid requiring codegen.
@@ -62,20 +199,68 @@ func (in *BackrestVolSyncOperatorConfig) DeepCopyInto(out *BackrestVolSyncOperat
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
 	out.Spec = in.Spec
-	out.Status = BackrestVolSyncOperatorConfigStatus{ObservedGeneration: in.Status.ObservedGeneration}
+	out.Status = in.Status
 	if in.Status.Conditions != nil {
 		out.Status.Conditions = make([]metav1.Condition, len(in.Status.Conditions))
 		copy(out.Status.Conditions, in.Status.Conditions)
 	}
+	if in.Status.FailingBindingSample != nil {
+		out.Status.FailingBindingSample = append([]string(nil), in.Status.FailingBindingSample...)
+	}
+	if in.Status.DryRun != nil {
+		v := *in.Status.DryRun
+		v.WouldCreate = append([]string(nil), in.Status.DryRun.WouldCreate...)
+		v.WouldUpdate = append([]string(nil), in.Status.DryRun.WouldUpdate...)
+		v.WouldDelete = append([]string(nil), in.Status.DryRun.WouldDelete...)
+		out.Status.DryRun = &v
+	}
+	if in.Status.AutoBindingSkips != nil {
+		v := *in.Status.AutoBindingSkips
+		if in.Status.AutoBindingSkips.Reasons != nil {
+			v.Reasons = make([]SkipReasonCount, len(in.Status.AutoBindingSkips.Reasons))
+			for i, r := range in.Status.AutoBindingSkips.Reasons {
+				r.Objects = append([]string(nil), r.Objects...)
+				v.Reasons[i] = r
+			}
+		}
+		out.Status.AutoBindingSkips = &v
+	}
 	if in.Spec.DefaultBackrest.AuthRef != nil {
 		out.Spec.DefaultBackrest.AuthRef = &SecretRef{Name: in.Spec.DefaultBackrest.AuthRef.Name}
 	}
+	if in.Spec.DefaultBackrest.InstanceRef != nil {
+		out.Spec.DefaultBackrest.InstanceRef = &InstanceRef{Name: in.Spec.DefaultBackrest.InstanceRef.Name}
+	}
 	if in.Spec.BindingGeneration.DefaultRepo.ExtraFlags != nil {
 		out.Spec.BindingGeneration.DefaultRepo.ExtraFlags = append([]string(nil), in.Spec.BindingGeneration.DefaultRepo.ExtraFlags...)
 	}
 	if in.Spec.BindingGeneration.Kinds != nil {
 		out.Spec.BindingGeneration.Kinds = append([]string(nil), in.Spec.BindingGeneration.Kinds...)
 	}
+	out.Spec.BindingGeneration.NamespaceSelector = in.Spec.BindingGeneration.NamespaceSelector.DeepCopy()
+	out.Spec.BindingGeneration.ObjectSelector = in.Spec.BindingGeneration.ObjectSelector.DeepCopy()
+	if in.Spec.BindingGeneration.AllowedOverrides != nil {
+		out.Spec.BindingGeneration.AllowedOverrides = append([]string(nil), in.Spec.BindingGeneration.AllowedOverrides...)
+	}
+	if in.Spec.URIRewrites != nil {
+		out.Spec.URIRewrites = append([]URIRewriteRule(nil), in.Spec.URIRewrites...)
+	}
+	if in.Spec.BindingGeneration.DefaultRepo.URIRewrites != nil {
+		out.Spec.BindingGeneration.DefaultRepo.URIRewrites = append([]URIRewriteRule(nil), in.Spec.BindingGeneration.DefaultRepo.URIRewrites...)
+	}
+	if in.Spec.NamespaceOverrides.LockedFields != nil {
+		out.Spec.NamespaceOverrides.LockedFields = append([]string(nil), in.Spec.NamespaceOverrides.LockedFields...)
+	}
+	if in.Spec.CompletionMarker.Rules != nil {
+		out.Spec.CompletionMarker.Rules = append([]MarkerRule(nil), in.Spec.CompletionMarker.Rules...)
+	}
+	if in.Spec.CompletionMarker.DestinationRules != nil {
+		out.Spec.CompletionMarker.DestinationRules = append([]MarkerRule(nil), in.Spec.CompletionMarker.DestinationRules...)
+	}
+	if in.Spec.CompletionMarker.SyncTime != nil {
+		out.Spec.CompletionMarker.SyncTime = append([]string(nil), in.Spec.CompletionMarker.SyncTime...)
+	}
+	out.Spec.BindingGeneration.DefaultRepo.EnvFrom = DeepCopyEnvFrom(in.Spec.BindingGeneration.DefaultRepo.EnvFrom)
 	if in.Spec.BindingGeneration.DefaultRepo.EnvAllowlist != nil {
 		out.Spec.BindingGeneration.DefaultRepo.EnvAllowlist = append([]string(nil), in.Spec.BindingGeneration.DefaultRepo.EnvAllowlist...)
 	}
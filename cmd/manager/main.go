@@ -2,6 +2,7 @@ package main
 
 import (
 	"flag"
+	"fmt"
 	"os"
 	"strings"
 	"time"
@@ -12,7 +13,9 @@ import (
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/client-go/discovery"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/rest"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/healthz"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
@@ -20,6 +23,7 @@ import (
 
 	"github.com/jogotcha/backrest-volsync-operator/api/v1alpha1"
 	"github.com/jogotcha/backrest-volsync-operator/controllers"
+	"github.com/jogotcha/backrest-volsync-operator/pkg/volsync"
 )
 
 func main() {
@@ -49,7 +53,9 @@ func main() {
 	utilruntime.Must(corev1.AddToScheme(scheme))
 	utilruntime.Must(v1alpha1.AddToScheme(scheme))
 
-	mgr, err := ctrl.NewManager(ctrl.GetConfigOrDie(), ctrl.Options{
+	restConfig := ctrl.GetConfigOrDie()
+
+	mgr, err := ctrl.NewManager(restConfig, ctrl.Options{
 		Scheme:                 scheme,
 		Metrics:                metricsserver.Options{BindAddress: metricsAddr},
 		HealthProbeBindAddress: probeAddr,
@@ -64,23 +70,105 @@ func main() {
 		os.Exit(1)
 	}
 
-	if err := (&controllers.BackrestVolSyncBindingReconciler{
+	operatorConfig := types.NamespacedName{Namespace: operatorConfigNamespace, Name: operatorConfigName}
+	bindingReconciler := &controllers.BackrestVolSyncBindingReconciler{
 		Client:         mgr.GetClient(),
 		Scheme:         mgr.GetScheme(),
 		Recorder:       mgr.GetEventRecorder("backrest-volsync-binding"),
-		OperatorConfig: types.NamespacedName{Namespace: operatorConfigNamespace, Name: operatorConfigName},
+		OperatorConfig: operatorConfig,
+		InputHashKey:   []byte(os.Getenv("INPUT_HASH_KEY")),
+	}
+	if err := bindingReconciler.SetupIndexes(mgr); err != nil {
+		logger.Error(err, "unable to set up binding indexes")
+		os.Exit(1)
+	}
+
+	// Auto-binding records what a dry run would change and why it skipped objects; the OperatorConfig
+	// controller reports both.
+	dryRun := controllers.NewDryRunRecorder()
+	skips := controllers.NewSkipRecorder()
+
+	// The VolSync-dependent controllers need the VolSync CRDs to start their informers. When VolSync is
+	// not installed yet the operator runs degraded and starts them once the CRDs are established. A
+	// failed start is retried; controller names are unique, so controllers registered by an earlier
+	// attempt are skipped.
+	var bindingStarted, autoBindingStarted bool
+	startVolSyncControllers := func(api volsync.API) error {
+		if !bindingStarted {
+			bindingReconciler.VolSync = api
+			if err := bindingReconciler.SetupControllerWithManager(mgr); err != nil {
+				return fmt.Errorf("binding controller: %w", err)
+			}
+			bindingStarted = true
+		}
+		if autoBindingStarted {
+			return nil
+		}
+		if err := (&controllers.VolSyncAutoBindingReconciler{
+			Client:         mgr.GetClient(),
+			Scheme:         mgr.GetScheme(),
+			Recorder:       mgr.GetEventRecorder("volsync-autobinding"),
+			OperatorConfig: operatorConfig,
+			VolSync:        api,
+			DryRun:         dryRun,
+			Skips:          skips,
+		}).SetupWithManager(mgr); err != nil {
+			return fmt.Errorf("VolSync auto-binding controller: %w", err)
+		}
+		autoBindingStarted = true
+		return nil
+	}
+	volsyncGate := &controllers.VolSyncCRDReconciler{
+		Client:         mgr.GetClient(),
+		OperatorConfig: operatorConfig,
+		Discover: func() (volsync.API, error) {
+			return discoverVolSyncAPI(restConfig, logger)
+		},
+		StartControllers: startVolSyncControllers,
+	}
+	if api, err := discoverVolSyncAPI(restConfig, logger); err != nil {
+		logger.Info("VolSync API not available; running degraded until the VolSync CRDs are installed", "reason", err.Error())
+	} else {
+		if err := startVolSyncControllers(api); err != nil {
+			logger.Error(err, "unable to create controller")
+			os.Exit(1)
+		}
+		volsyncGate.MarkStarted()
+	}
+	if err := volsyncGate.SetupWithManager(mgr); err != nil {
+		logger.Error(err, "unable to create VolSync CRD controller")
+		os.Exit(1)
+	}
+
+	if err := (&controllers.BackrestInstanceReconciler{
+		Client:         mgr.GetClient(),
+		Scheme:         mgr.GetScheme(),
+		Recorder:       mgr.GetEventRecorder("backrest-instance"),
+		OperatorConfig: operatorConfig,
 	}).SetupWithManager(mgr); err != nil {
-		logger.Error(err, "unable to create controller")
+		logger.Error(err, "unable to create BackrestInstance controller")
 		os.Exit(1)
 	}
 
-	if err := (&controllers.VolSyncAutoBindingReconciler{
+	if err := (&controllers.OperatorConfigReconciler{
 		Client:         mgr.GetClient(),
 		Scheme:         mgr.GetScheme(),
-		Recorder:       mgr.GetEventRecorder("volsync-autobinding"),
-		OperatorConfig: types.NamespacedName{Namespace: operatorConfigNamespace, Name: operatorConfigName},
+		Recorder:       mgr.GetEventRecorder("backrest-volsync-operatorconfig"),
+		OperatorConfig: operatorConfig,
+		DryRun:         dryRun,
+		Skips:          skips,
 	}).SetupWithManager(mgr); err != nil {
-		logger.Error(err, "unable to create VolSync auto-binding controller")
+		logger.Error(err, "unable to create BackrestVolSyncOperatorConfig controller")
+		os.Exit(1)
+	}
+
+	if err := (&controllers.NamespaceConfigReconciler{
+		Client:         mgr.GetClient(),
+		Scheme:         mgr.GetScheme(),
+		Recorder:       mgr.GetEventRecorder("backrest-volsync-namespaceconfig"),
+		OperatorConfig: operatorConfig,
+	}).SetupWithManager(mgr); err != nil {
+		logger.Error(err, "unable to create BackrestVolSyncNamespaceConfig controller")
 		os.Exit(1)
 	}
 
@@ -92,6 +180,10 @@ func main() {
 		logger.Error(err, "unable to set up ready check")
 		os.Exit(1)
 	}
+	if err := mgr.AddReadyzCheck("volsync", volsyncGate.ReadyzCheck); err != nil {
+		logger.Error(err, "unable to set up VolSync ready check")
+		os.Exit(1)
+	}
 
 	logger.Info("starting manager")
 	if err := mgr.Start(ctrl.SetupSignalHandler()); err != nil {
@@ -102,6 +194,27 @@ func main() {
 
 func ptr[T any](v T) *T { return &v }
 
+// discoverVolSyncAPI looks up the served VolSync API versions. An error means VolSync is not (fully)
+// installed; versions with an unknown field layout are logged and read as the default version.
+func discoverVolSyncAPI(cfg *rest.Config, logger logr.Logger) (volsync.API, error) {
+	dc, err := discovery.NewDiscoveryClientForConfig(cfg)
+	if err != nil {
+		return volsync.API{}, fmt.Errorf("create discovery client: %w", err)
+	}
+	api, err := volsync.Discover(dc)
+	if err != nil {
+		return volsync.API{}, err
+	}
+	if unknown := api.UnknownVersions(); len(unknown) > 0 {
+		logger.Info("VolSync serves an API version with unknown field layout; reading it as "+volsync.Version, "versions", unknown)
+	}
+	logger.Info("using VolSync API",
+		"replicationSource", api.APIVersion(volsync.KindReplicationSource),
+		"replicationDestination", api.APIVersion(volsync.KindReplicationDestination),
+	)
+	return api, nil
+}
+
 func newLogger(level string) logr.Logger {
 	level = strings.ToLower(strings.TrimSpace(level))
 	zl := zapcore.InfoLevel
@@ -0,0 +1,322 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	v1 "github.com/garethgeorge/backrest/gen/go/v1"
+	"github.com/jogotcha/backrest-volsync-operator/api/v1alpha1"
+	"github.com/jogotcha/backrest-volsync-operator/pkg/backrest"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/events"
+	"k8s.io/client-go/util/flowcontrol"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+const (
+	conditionReachable = "Reachable"
+
+	indexBackrestInstance = "spec.backrest.instanceRef"
+
+	defaultInstanceProbeInterval = 5 * time.Minute
+)
+
+// BackrestInstanceReconciler probes BackrestInstance endpoints and reports reachability in status.
+type BackrestInstanceReconciler struct {
+	client.Client
+	Scheme                *runtime.Scheme
+	Recorder              events.EventRecorder
+	BackrestClientFactory func(baseURL string, auth backrest.Auth, opts backrest.Options) backrestInstanceClient
+
+	OperatorConfig types.NamespacedName
+
+	// ProbeInterval controls how often reachability is re-checked. Defaults to 5 minutes.
+	ProbeInterval time.Duration
+}
+
+type backrestInstanceClient interface {
+	GetConfig(ctx context.Context) (*v1.Config, error)
+}
+
+func (r *BackrestInstanceReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	logger := log.FromContext(ctx)
+
+	var inst v1alpha1.BackrestInstance
+	if err := r.Get(ctx, req.NamespacedName, &inst); err != nil {
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+
+	interval := r.ProbeInterval
+	if interval <= 0 {
+		interval = defaultInstanceProbeInterval
+	}
+
+	if cfg, err := LoadOperatorConfig(ctx, r.Client, r.OperatorConfig); err != nil {
+		return ctrl.Result{}, err
+	} else if cfg.Paused {
+		meta.SetStatusCondition(&inst.Status.Conditions, metav1.Condition{
+			Type:               conditionReachable,
+			Status:             metav1.ConditionUnknown,
+			Reason:             "Paused",
+			Message:            "Operator is paused by BackrestVolSyncOperatorConfig",
+			ObservedGeneration: inst.Generation,
+			LastTransitionTime: metav1.Now(),
+		})
+		inst.Status.ObservedGeneration = inst.Generation
+		return r.updateStatus(ctx, &inst, 0)
+	}
+
+	// Status writes of the probe itself must not shorten the interval; spec changes and resuming after a
+	// pause probe right away.
+	if wait := nextProbeIn(&inst, interval); wait > 0 {
+		return ctrl.Result{RequeueAfter: wait}, nil
+	}
+
+	conn, err := loadInstanceConnection(ctx, r.Client, &inst, nil)
+	if err != nil {
+		return r.probeFailed(ctx, &inst, "InvalidConnection", err, interval)
+	}
+	brClient, err := r.newBackrestClient(conn)
+	if err != nil {
+		return r.probeFailed(ctx, &inst, "InvalidConnection", err, interval)
+	}
+
+	cfg, err := brClient.GetConfig(ctx)
+	if err != nil {
+		return r.probeFailed(ctx, &inst, "Unreachable", err, interval)
+	}
+
+	boundRepos, err := r.countBoundRepos(ctx, inst.Name, cfg)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	now := metav1.Now()
+	inst.Status.InstanceID = cfg.GetInstance()
+	inst.Status.ConfigVersion = cfg.GetVersion()
+	inst.Status.RepoCount = int32(len(cfg.GetRepos()))
+	inst.Status.BoundRepoCount = boundRepos
+	inst.Status.LastProbeTime = &now
+	inst.Status.LastErrorHash = ""
+	meta.SetStatusCondition(&inst.Status.Conditions, metav1.Condition{
+		Type:               conditionReachable,
+		Status:             metav1.ConditionTrue,
+		Reason:             "Reachable",
+		Message:            "Backrest API responded to GetConfig",
+		ObservedGeneration: inst.Generation,
+		LastTransitionTime: now,
+	})
+	inst.Status.ObservedGeneration = inst.Generation
+	logger.V(1).Info("Backrest instance reachable", "instance", inst.Name, "repoCount", inst.Status.RepoCount)
+	return r.updateStatus(ctx, &inst, interval)
+}
+
+// nextProbeIn returns how long until the instance is due for a probe, or 0 when it is due now.
+func nextProbeIn(inst *v1alpha1.BackrestInstance, interval time.Duration) time.Duration {
+	if inst.Status.LastProbeTime == nil || inst.Status.ObservedGeneration != inst.Generation {
+		return 0
+	}
+	if cond := meta.FindStatusCondition(inst.Status.Conditions, conditionReachable); cond == nil || cond.Reason == "Paused" {
+		return 0
+	}
+	if wait := time.Until(inst.Status.LastProbeTime.Add(interval)); wait > 0 {
+		return wait
+	}
+	return 0
+}
+
+func (r *BackrestInstanceReconciler) newBackrestClient(conn backrestConnection) (backrestInstanceClient, error) {
+	if r.BackrestClientFactory != nil {
+		return r.BackrestClientFactory(conn.URL, conn.Auth, conn.Options), nil
+	}
+	return backrest.NewWithOptions(conn.URL, conn.Auth, conn.Options)
+}
+
+// countBoundRepos counts repos in the Backrest config that belong to bindings selecting the instance.
+func (r *BackrestInstanceReconciler) countBoundRepos(ctx context.Context, instance string, cfg *v1.Config) (int32, error) {
+	var list v1alpha1.BackrestVolSyncBindingList
+	if err := r.List(ctx, &list, client.MatchingFields{indexBackrestInstance: instance}); err != nil {
+		return 0, err
+	}
+	wanted := make(map[string]struct{}, len(list.Items))
+	for i := range list.Items {
+		wanted[desiredRepoID(&list.Items[i])] = struct{}{}
+	}
+	var n int32
+	for _, repo := range cfg.GetRepos() {
+		if _, ok := wanted[repo.GetId()]; ok {
+			n++
+		}
+	}
+	return n, nil
+}
+
+func (r *BackrestInstanceReconciler) probeFailed(ctx context.Context, inst *v1alpha1.BackrestInstance, reason string, err error, interval time.Duration) (ctrl.Result, error) {
+	errHash := hashString(err.Error())
+	if r.Recorder != nil && inst.Status.LastErrorHash != errHash {
+		r.Recorder.Eventf(inst, nil, corev1.EventTypeWarning, reason, "Probe", "Backrest instance probe failed (errorHash=%s)", errHash)
+	}
+	log.FromContext(ctx).Info("Backrest instance probe failed", "instance", inst.Name, "reason", reason, "errorHash", errHash)
+	now := metav1.Now()
+	inst.Status.LastProbeTime = &now
+	inst.Status.LastErrorHash = errHash
+	meta.SetStatusCondition(&inst.Status.Conditions, metav1.Condition{
+		Type:               conditionReachable,
+		Status:             metav1.ConditionFalse,
+		Reason:             reason,
+		Message:            fmt.Sprintf("%s (details omitted; errorHash=%s)", reason, errHash),
+		ObservedGeneration: inst.Generation,
+		LastTransitionTime: now,
+	})
+	inst.Status.ObservedGeneration = inst.Generation
+	return r.updateStatus(ctx, inst, interval)
+}
+
+func (r *BackrestInstanceReconciler) updateStatus(ctx context.Context, inst *v1alpha1.BackrestInstance, requeueAfter time.Duration) (ctrl.Result, error) {
+	if err := r.Status().Update(ctx, inst); err != nil {
+		if apierrors.IsConflict(err) {
+			return ctrl.Result{RequeueAfter: 200 * time.Millisecond}, nil
+		}
+		return ctrl.Result{}, err
+	}
+	return ctrl.Result{RequeueAfter: requeueAfter}, nil
+}
+
+func (r *BackrestInstanceReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&v1alpha1.BackrestInstance{}, builder.WithPredicates(predicate.GenerationChangedPredicate{})).
+		// A paused instance is not requeued; resuming probes every instance right away.
+		Watches(&v1alpha1.BackrestVolSyncOperatorConfig{}, handler.EnqueueRequestsFromMapFunc(func(ctx context.Context, _ client.Object) []reconcile.Request {
+			return r.allInstances(ctx)
+		}), builder.WithPredicates(operatorConfigChanged(r.OperatorConfig, instanceConfigFields))).
+		Complete(r)
+}
+
+// instanceConfigFields are the OperatorConfig fields BackrestInstanceReconciler reads.
+func instanceConfigFields(spec *v1alpha1.BackrestVolSyncOperatorConfigSpec) any {
+	return spec.Paused
+}
+
+// allInstances enqueues every BackrestInstance.
+func (r *BackrestInstanceReconciler) allInstances(ctx context.Context) []reconcile.Request {
+	var list v1alpha1.BackrestInstanceList
+	if err := r.List(ctx, &list); err != nil {
+		return nil
+	}
+	reqs := make([]reconcile.Request, 0, len(list.Items))
+	for i := range list.Items {
+		reqs = append(reqs, reconcile.Request{NamespacedName: types.NamespacedName{Name: list.Items[i].Name}})
+	}
+	return reqs
+}
+
+// backrestConnection is a fully resolved set of Backrest client parameters.
+type backrestConnection struct {
+	URL     string
+	Auth    backrest.Auth
+	Options backrest.Options
+}
+
+// loadInstanceConnection resolves auth, TLS, timeout and rate limit settings of a BackrestInstance.
+// A nil limiters cache disables client-side rate limiting.
+func loadInstanceConnection(ctx context.Context, c client.Client, inst *v1alpha1.BackrestInstance, limiters *rateLimiterCache) (backrestConnection, error) {
+	conn := backrestConnection{URL: inst.Spec.URL}
+	if conn.URL == "" {
+		return backrestConnection{}, fmt.Errorf("BackrestInstance %s has no spec.url", inst.Name)
+	}
+	if ref := inst.Spec.AuthRef; ref != nil && ref.Name != "" {
+		auth, err := loadAuthSecret(ctx, c, types.NamespacedName{Namespace: ref.Namespace, Name: ref.Name})
+		if err != nil {
+			return backrestConnection{}, err
+		}
+		conn.Auth = auth
+	}
+	if tlsSpec := inst.Spec.TLS; tlsSpec != nil {
+		conn.Options.InsecureSkipVerify = tlsSpec.InsecureSkipVerify
+		conn.Options.ServerName = tlsSpec.ServerName
+		if ref := tlsSpec.CASecretRef; ref != nil && ref.Name != "" {
+			var sec corev1.Secret
+			if err := c.Get(ctx, types.NamespacedName{Namespace: ref.Namespace, Name: ref.Name}, &sec); err != nil {
+				return backrestConnection{}, err
+			}
+			ca := sec.Data["ca.crt"]
+			if len(ca) == 0 {
+				return backrestConnection{}, fmt.Errorf("CA secret must contain 'ca.crt'")
+			}
+			conn.Options.CAData = ca
+		}
+	}
+	if inst.Spec.TimeoutSeconds != nil && *inst.Spec.TimeoutSeconds > 0 {
+		conn.Options.Timeout = time.Duration(*inst.Spec.TimeoutSeconds) * time.Second
+	}
+	if limiters != nil {
+		conn.Options.RateLimiter = limiters.get(inst.Name, inst.Spec.RateLimit)
+	}
+	return conn, nil
+}
+
+func loadAuthSecret(ctx context.Context, c client.Client, nn types.NamespacedName) (backrest.Auth, error) {
+	var sec corev1.Secret
+	if err := c.Get(ctx, nn, &sec); err != nil {
+		return backrest.Auth{}, err
+	}
+	// Supported keys:
+	// - token (bearer)
+	// - username/password (basic)
+	if b, ok := sec.Data["token"]; ok && len(b) > 0 {
+		return backrest.Auth{BearerToken: string(b)}, nil
+	}
+	user := string(sec.Data["username"])
+	pass := string(sec.Data["password"])
+	if user == "" && pass == "" {
+		return backrest.Auth{}, fmt.Errorf("auth secret must contain either 'token' or 'username'/'password'")
+	}
+	return backrest.Auth{BasicUsername: user, BasicPassword: pass}, nil
+}
+
+// rateLimiterCache shares one token bucket per BackrestInstance across reconciles.
+type rateLimiterCache struct {
+	mu       sync.Mutex
+	limiters map[string]cachedRateLimiter
+}
+
+type cachedRateLimiter struct {
+	spec    v1alpha1.BackrestRateLimitSpec
+	limiter flowcontrol.RateLimiter
+}
+
+func (c *rateLimiterCache) get(instance string, spec *v1alpha1.BackrestRateLimitSpec) flowcontrol.RateLimiter {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if spec == nil || spec.QPS <= 0 {
+		delete(c.limiters, instance)
+		return nil
+	}
+	if c.limiters == nil {
+		c.limiters = make(map[string]cachedRateLimiter)
+	}
+	if cached, ok := c.limiters[instance]; ok && cached.spec == *spec {
+		return cached.limiter
+	}
+	burst := spec.Burst
+	if burst <= 0 {
+		burst = spec.QPS
+	}
+	limiter := flowcontrol.NewTokenBucketRateLimiter(float32(spec.QPS), int(burst))
+	c.limiters[instance] = cachedRateLimiter{spec: *spec, limiter: limiter}
+	return limiter
+}
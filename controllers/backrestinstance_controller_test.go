@@ -0,0 +1,316 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	v1 "github.com/garethgeorge/backrest/gen/go/v1"
+	"github.com/jogotcha/backrest-volsync-operator/api/v1alpha1"
+	"github.com/jogotcha/backrest-volsync-operator/pkg/backrest"
+	"github.com/jogotcha/backrest-volsync-operator/pkg/volsync"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+)
+
+type fakeBackrestInstanceClient struct {
+	cfg *v1.Config
+	err error
+}
+
+func (f *fakeBackrestInstanceClient) GetConfig(_ context.Context) (*v1.Config, error) {
+	return f.cfg, f.err
+}
+
+func instanceRefIndex(obj client.Object) []string {
+	return bindingInstanceRefs(obj.(*v1alpha1.BackrestVolSyncBinding))
+}
+
+func TestBackrestInstanceReconcile_ReportsReachableAndBoundRepos(t *testing.T) {
+	ctx := context.Background()
+	scheme := bindingTestScheme(t)
+
+	inst := &v1alpha1.BackrestInstance{}
+	inst.Name = "primary"
+	inst.Spec.URL = "http://backrest.invalid"
+
+	bound := &v1alpha1.BackrestVolSyncBinding{}
+	bound.Namespace = "workload"
+	bound.Name = "b"
+	bound.Spec.Backrest.InstanceRef = &v1alpha1.InstanceRef{Name: "primary"}
+	bound.Spec.Source = v1alpha1.VolSyncSourceRef{Kind: "ReplicationSource", Name: "demo"}
+
+	other := &v1alpha1.BackrestVolSyncBinding{}
+	other.Namespace = "workload"
+	other.Name = "other"
+	other.Spec.Backrest.URL = "http://elsewhere.invalid"
+	other.Spec.Source = v1alpha1.VolSyncSourceRef{Kind: "ReplicationSource", Name: "other"}
+
+	c := fake.NewClientBuilder().WithScheme(scheme).
+		WithStatusSubresource(&v1alpha1.BackrestInstance{}).
+		WithIndex(&v1alpha1.BackrestVolSyncBinding{}, indexBackrestInstance, instanceRefIndex).
+		WithObjects(inst, bound, other).
+		Build()
+
+	br := &fakeBackrestInstanceClient{cfg: &v1.Config{
+		Instance: "backrest-1",
+		Version:  4,
+		Repos: []*v1.Repo{
+			{Id: desiredRepoID(bound)},
+			{Id: desiredRepoID(other)},
+			{Id: "unrelated"},
+		},
+	}}
+	var gotURL string
+	var probes int
+	r := &BackrestInstanceReconciler{
+		Client: c,
+		Scheme: scheme,
+		BackrestClientFactory: func(baseURL string, _ backrest.Auth, _ backrest.Options) backrestInstanceClient {
+			gotURL = baseURL
+			probes++
+			return br
+		},
+	}
+
+	res, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: types.NamespacedName{Name: inst.Name}})
+	if err != nil {
+		t.Fatalf("reconcile: %v", err)
+	}
+	if res.RequeueAfter != defaultInstanceProbeInterval {
+		t.Fatalf("expected periodic requeue, got %v", res.RequeueAfter)
+	}
+	if gotURL != "http://backrest.invalid" {
+		t.Fatalf("expected instance url, got %q", gotURL)
+	}
+
+	var got v1alpha1.BackrestInstance
+	if err := c.Get(ctx, types.NamespacedName{Name: inst.Name}, &got); err != nil {
+		t.Fatalf("get: %v", err)
+	}
+	if !meta.IsStatusConditionTrue(got.Status.Conditions, conditionReachable) {
+		t.Fatalf("expected Reachable=True, got %#v", got.Status.Conditions)
+	}
+	if got.Status.InstanceID != "backrest-1" || got.Status.ConfigVersion != 4 {
+		t.Fatalf("unexpected instance info: %#v", got.Status)
+	}
+	if got.Status.RepoCount != 3 || got.Status.BoundRepoCount != 1 {
+		t.Fatalf("expected repoCount=3 boundRepoCount=1, got %d/%d", got.Status.RepoCount, got.Status.BoundRepoCount)
+	}
+
+	// The status update reconciles the instance again; it is not probed before the interval passed.
+	res, err = r.Reconcile(ctx, ctrl.Request{NamespacedName: types.NamespacedName{Name: inst.Name}})
+	if err != nil {
+		t.Fatalf("reconcile after status update: %v", err)
+	}
+	if probes != 1 {
+		t.Fatalf("expected a single probe, got %d", probes)
+	}
+	if res.RequeueAfter <= 0 || res.RequeueAfter > defaultInstanceProbeInterval {
+		t.Fatalf("expected a requeue for the remaining interval, got %v", res.RequeueAfter)
+	}
+
+	past := metav1.NewTime(got.Status.LastProbeTime.Add(-defaultInstanceProbeInterval))
+	got.Status.LastProbeTime = &past
+	if err := c.Status().Update(ctx, &got); err != nil {
+		t.Fatalf("update status: %v", err)
+	}
+	br.err = errors.New("connection refused")
+	if _, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: types.NamespacedName{Name: inst.Name}}); err != nil {
+		t.Fatalf("reconcile #2: %v", err)
+	}
+	if err := c.Get(ctx, types.NamespacedName{Name: inst.Name}, &got); err != nil {
+		t.Fatalf("get: %v", err)
+	}
+	cond := meta.FindStatusCondition(got.Status.Conditions, conditionReachable)
+	if cond == nil || cond.Status != metav1.ConditionFalse || cond.Reason != "Unreachable" {
+		t.Fatalf("expected Reachable=False/Unreachable, got %#v", cond)
+	}
+	if got.Status.LastErrorHash == "" {
+		t.Fatalf("expected LastErrorHash set")
+	}
+}
+
+func TestBackrestInstanceReconcile_ResumesAfterPause(t *testing.T) {
+	ctx := context.Background()
+	scheme := bindingTestScheme(t)
+
+	cfg := &v1alpha1.BackrestVolSyncOperatorConfig{}
+	cfg.Namespace = "backrest-volsync-operator"
+	cfg.Name = "backrest-volsync-operator"
+	cfg.Generation = 1
+	cfg.Spec.Paused = true
+	inst := &v1alpha1.BackrestInstance{}
+	inst.Name = "primary"
+	inst.Spec.URL = "http://backrest.invalid"
+
+	c := fake.NewClientBuilder().WithScheme(scheme).
+		WithStatusSubresource(&v1alpha1.BackrestInstance{}).
+		WithIndex(&v1alpha1.BackrestVolSyncBinding{}, indexBackrestInstance, instanceRefIndex).
+		WithObjects(cfg, inst).
+		Build()
+	var probes int
+	nn := types.NamespacedName{Namespace: cfg.Namespace, Name: cfg.Name}
+	r := &BackrestInstanceReconciler{
+		Client:         c,
+		Scheme:         scheme,
+		OperatorConfig: nn,
+		BackrestClientFactory: func(string, backrest.Auth, backrest.Options) backrestInstanceClient {
+			probes++
+			return &fakeBackrestInstanceClient{cfg: &v1.Config{}}
+		},
+	}
+	req := ctrl.Request{NamespacedName: types.NamespacedName{Name: inst.Name}}
+
+	if _, err := r.Reconcile(ctx, req); err != nil {
+		t.Fatalf("reconcile: %v", err)
+	}
+	if probes != 0 {
+		t.Fatalf("expected no probe while paused, got %d", probes)
+	}
+
+	// Unpausing enqueues every instance, which is probed right away.
+	resumed := cfg.DeepCopy()
+	resumed.Generation++
+	resumed.Spec.Paused = false
+	if !operatorConfigChanged(nn, instanceConfigFields).Update(event.UpdateEvent{ObjectOld: cfg, ObjectNew: resumed}) {
+		t.Fatalf("expected unpausing to pass the predicate")
+	}
+	if reqs := r.allInstances(ctx); len(reqs) != 1 || reqs[0] != req {
+		t.Fatalf("expected the instance enqueued, got %v", reqs)
+	}
+	var current v1alpha1.BackrestVolSyncOperatorConfig
+	if err := c.Get(ctx, nn, &current); err != nil {
+		t.Fatalf("get config: %v", err)
+	}
+	current.Spec.Paused = false
+	if err := c.Update(ctx, &current); err != nil {
+		t.Fatalf("update config: %v", err)
+	}
+	if _, err := r.Reconcile(ctx, req); err != nil {
+		t.Fatalf("reconcile: %v", err)
+	}
+	var got v1alpha1.BackrestInstance
+	if err := c.Get(ctx, req.NamespacedName, &got); err != nil {
+		t.Fatalf("get: %v", err)
+	}
+	if probes != 1 || !meta.IsStatusConditionTrue(got.Status.Conditions, conditionReachable) {
+		t.Fatalf("expected a probe after resuming, got %d probes, conditions %#v", probes, got.Status.Conditions)
+	}
+}
+
+func TestBackrestVolSyncBindingReconcile_UsesInstanceRef(t *testing.T) {
+	ctx := context.Background()
+	scheme := bindingTestScheme(t)
+
+	authSecret := &corev1.Secret{}
+	authSecret.Namespace = "backups"
+	authSecret.Name = "backrest-auth"
+	authSecret.Data = map[string][]byte{"token": []byte("t0ken")}
+
+	inst := &v1alpha1.BackrestInstance{}
+	inst.Name = "primary"
+	inst.Spec.URL = "http://instance.invalid"
+	inst.Spec.AuthRef = &v1alpha1.NamespacedSecretRef{Namespace: "backups", Name: "backrest-auth"}
+	inst.Spec.RateLimit = &v1alpha1.BackrestRateLimitSpec{QPS: 5}
+
+	b := &v1alpha1.BackrestVolSyncBinding{}
+	b.Namespace = "workload"
+	b.Name = "b"
+	b.Spec.Backrest.InstanceRef = &v1alpha1.InstanceRef{Name: "primary"}
+	b.Spec.Source = v1alpha1.VolSyncSourceRef{Kind: "ReplicationSource", Name: "demo"}
+
+	vs := &unstructured.Unstructured{Object: map[string]any{
+		"apiVersion": volsync.Group + "/" + volsync.Version,
+		"kind":       "ReplicationSource",
+		"metadata": map[string]any{
+			"name":      "demo",
+			"namespace": "workload",
+		},
+		"spec": map[string]any{
+			"restic": map[string]any{
+				"repository": "repo-secret",
+			},
+		},
+	}}
+
+	sec := &corev1.Secret{}
+	sec.Namespace = "workload"
+	sec.Name = "repo-secret"
+	sec.Data = map[string][]byte{
+		"RESTIC_REPOSITORY": []byte("s3://bucket/repo"),
+		"RESTIC_PASSWORD":   []byte("pass"),
+	}
+
+	c := fake.NewClientBuilder().WithScheme(scheme).
+		WithStatusSubresource(&v1alpha1.BackrestVolSyncBinding{}).
+		WithObjects(authSecret, inst, b, vs, sec).
+		Build()
+
+	br := &fakeBackrestRepoClient{}
+	var gotURL string
+	var gotAuth backrest.Auth
+	var gotOpts backrest.Options
+	r := &BackrestVolSyncBindingReconciler{
+		Client: c,
+		Scheme: scheme,
+		BackrestClientFactory: func(baseURL string, auth backrest.Auth, opts backrest.Options) backrestRepoClient {
+			gotURL, gotAuth, gotOpts = baseURL, auth, opts
+			return br
+		},
+	}
+
+	req := ctrl.Request{NamespacedName: types.NamespacedName{Namespace: b.Namespace, Name: b.Name}}
+	if _, err := r.Reconcile(ctx, req); err != nil {
+		t.Fatalf("reconcile: %v", err)
+	}
+	if gotURL != "http://instance.invalid" || gotAuth.BearerToken != "t0ken" {
+		t.Fatalf("expected instance connection, got url=%q auth=%#v", gotURL, gotAuth)
+	}
+	if gotOpts.RateLimiter == nil {
+		t.Fatalf("expected rate limiter from instance spec")
+	}
+	if br.addRepoCalls != 1 {
+		t.Fatalf("expected addRepoCalls=1, got %d", br.addRepoCalls)
+	}
+
+	// Changing the instance URL must re-register the repo.
+	var current v1alpha1.BackrestInstance
+	if err := c.Get(ctx, types.NamespacedName{Name: "primary"}, &current); err != nil {
+		t.Fatalf("get instance: %v", err)
+	}
+	current.Spec.URL = "http://moved.invalid"
+	if err := c.Update(ctx, &current); err != nil {
+		t.Fatalf("update instance: %v", err)
+	}
+	if _, err := r.Reconcile(ctx, req); err != nil {
+		t.Fatalf("reconcile #2: %v", err)
+	}
+	if br.addRepoCalls != 2 || gotURL != "http://moved.invalid" {
+		t.Fatalf("expected re-apply against moved url, got calls=%d url=%q", br.addRepoCalls, gotURL)
+	}
+}
+
+func TestValidateBinding_InstanceRefExclusive(t *testing.T) {
+	b := &v1alpha1.BackrestVolSyncBinding{}
+	b.Spec.Source = v1alpha1.VolSyncSourceRef{Kind: "ReplicationSource", Name: "demo"}
+	b.Spec.Backrest = v1alpha1.BackrestConnection{
+		URL:         "http://example.invalid",
+		InstanceRef: &v1alpha1.InstanceRef{Name: "primary"},
+	}
+	if _, errs := validateBinding(b); len(errs) != 1 {
+		t.Fatalf("expected one error for url+instanceRef, got %v", errs)
+	}
+
+	b.Spec.Backrest.URL = ""
+	if _, errs := validateBinding(b); len(errs) != 0 {
+		t.Fatalf("expected instanceRef alone to be valid, got %v", errs)
+	}
+}
@@ -2,9 +2,12 @@ package controllers
 
 import (
 	"context"
+	"crypto/hmac"
 	"crypto/sha256"
 	"encoding/hex"
 	"fmt"
+	"hash"
+	"reflect"
 	"sort"
 	"strings"
 	"sync"
@@ -20,15 +23,17 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
-	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/validation/field"
 	"k8s.io/client-go/tools/events"
 	"k8s.io/utils/ptr"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
 	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 )
 
@@ -43,30 +48,41 @@ type BackrestVolSyncBindingReconciler struct {
 	client.Client
 	Scheme                *runtime.Scheme
 	Recorder              events.EventRecorder
-	BackrestClientFactory func(baseURL string, auth backrest.Auth) backrestRepoClient
+	BackrestClientFactory func(baseURL string, auth backrest.Auth, opts backrest.Options) backrestRepoClient
 
 	OperatorConfig types.NamespacedName
 
+	// VolSync selects the served VolSync API version per kind. The zero value uses volsync.Version.
+	VolSync volsync.API
+
+	// InputHashKey is mixed into the repo content digest stored in status. Optional; the binding
+	// UID is always part of the key.
+	InputHashKey []byte
+
 	taskTriggerMu       sync.Mutex
 	inflightTaskMarkers map[string]string
+
+	rateLimiters rateLimiterCache
 }
 
 type backrestRepoClient interface {
 	AddRepo(ctx context.Context, repo *v1.Repo) (*v1.Config, error)
+	CheckRepoExists(ctx context.Context, repo *v1.Repo) (bool, error)
 	DoRepoTask(ctx context.Context, repoID string, task v1.DoRepoTaskRequest_Task) error
+	RemoveRepo(ctx context.Context, repoID string) error
 }
 
 func (r *BackrestVolSyncBindingReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
-	logger := log.FromContext(ctx)
-
 	var binding v1alpha1.BackrestVolSyncBinding
 	if err := r.Get(ctx, req.NamespacedName, &binding); err != nil {
 		return ctrl.Result{}, client.IgnoreNotFound(err)
 	}
 
-	if cfg, err := LoadOperatorConfig(ctx, r.Client, r.OperatorConfig); err != nil {
+	cfg, err := LoadOperatorConfig(ctx, r.Client, r.OperatorConfig)
+	if err != nil {
 		return ctrl.Result{}, err
-	} else if cfg.Paused {
+	}
+	if cfg.Paused {
 		if r.Recorder != nil {
 			r.Recorder.Eventf(&binding, nil, corev1.EventTypeNormal, "Paused", "Reconcile", "Operator is paused by BackrestVolSyncOperatorConfig")
 		}
@@ -82,7 +98,21 @@ func (r *BackrestVolSyncBindingReconciler) Reconcile(ctx context.Context, req ct
 		return r.updateStatus(ctx, &binding)
 	}
 
-	if errs := validateBinding(&binding); len(errs) > 0 {
+	if !binding.DeletionTimestamp.IsZero() {
+		return r.finalizeBinding(ctx, &binding, cfg)
+	}
+	if err := r.ensureCleanupFinalizer(ctx, &binding, cfg); err != nil {
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+	if binding.Labels[labelManaged] == "true" {
+		// Generated bindings resolve their repo ID with the namespace's repoIDTemplate.
+		if cfg, err = LoadNamespaceConfig(ctx, r.Client, cfg, binding.Namespace); err != nil {
+			return r.fail(ctx, &binding, "NamespaceConfigInvalid", err)
+		}
+	}
+
+	bindingRewrites, errs := validateBinding(&binding)
+	if len(errs) > 0 {
 		err := errs.ToAggregate()
 		if r.Recorder != nil {
 			r.Recorder.Eventf(&binding, nil, corev1.EventTypeWarning, "InvalidSpec", "Validate", "Invalid spec; see status.conditions")
@@ -103,6 +133,8 @@ func (r *BackrestVolSyncBindingReconciler) Reconcile(ctx context.Context, req ct
 	if err != nil {
 		return r.fail(ctx, &binding, "VolSyncNotFound", err)
 	}
+	syncStatusChanged := r.mirrorSyncStatus(ctx, &binding, vsObj)
+	manualSyncChanged, manualSyncErr := r.reconcileManualSync(ctx, &binding, vsObj)
 
 	repoSecretName, err := volsync.RepositorySecretName(vsObj)
 	if err != nil {
@@ -115,74 +147,203 @@ func (r *BackrestVolSyncBindingReconciler) Reconcile(ctx context.Context, req ct
 		return r.fail(ctx, &binding, "RepositorySecretNotFound", err)
 	}
 
-	resticRepo, resticPass, env, err := extractResticSecret(&repoSecret, binding.Spec.Repo.EnvAllowlist)
+	envFrom, err := loadEnvFrom(ctx, r.Client, binding.Namespace, binding.Spec.Repo.EnvFrom)
+	if err != nil {
+		return r.fail(ctx, &binding, "EnvFromNotFound", err)
+	}
+
+	secretData, err := extractResticSecret(&repoSecret, binding.Spec.Repo.EnvAllowlist, envFrom)
 	if err != nil {
 		return r.fail(ctx, &binding, "RepositorySecretInvalid", err)
 	}
 
-	inputHash := computeInputHash(&binding, vsObj, &repoSecret)
-	shouldApplyRepo := binding.Status.LastAppliedInputHash != inputHash || !isReady(&binding)
-	statusChanged := false
+	repoIDChanged, err := r.resolveRepoID(&binding, cfg, vsObj)
+	if err != nil {
+		return r.fail(ctx, &binding, "RepoIDInvalid", err)
+	}
 
+	statusChanged := syncStatusChanged || manualSyncChanged || repoIDChanged
 	if binding.Status.ResolvedRepositorySecret != repoSecretName {
 		binding.Status.ResolvedRepositorySecret = repoSecretName
 		statusChanged = true
 	}
+	if !reflect.DeepEqual(binding.Status.SecretKeys, &secretData.Keys) {
+		binding.Status.SecretKeys = secretData.Keys.DeepCopy()
+		statusChanged = true
+	}
+
+	repoURI := rewriteRepositoryURI(secretData.Repository, cfg.URIRewrites, bindingRewrites)
+	if redacted := redactRepositoryURI(repoURI); binding.Status.ResolvedRepositoryURI != redacted {
+		binding.Status.ResolvedRepositoryURI = redacted
+		statusChanged = true
+	}
+
+	repoShared, err := r.shareRepo(ctx, &binding, cfg, repoURI, secretData.Password)
+	if err != nil {
+		return r.fail(ctx, &binding, "RepoShareFailed", err)
+	}
+	if repoShared {
+		statusChanged = true
+	}
+
+	repo := &v1.Repo{
+		Id:             desiredRepoID(&binding),
+		Uri:            repoURI,
+		Password:       secretData.Password,
+		Env:            secretData.Env,
+		Flags:          mergeMappedFlags(binding.Spec.Repo.ExtraFlags, secretData.Flags),
+		AutoUnlock:     ptr.Deref(binding.Spec.Repo.AutoUnlock, false),
+		AutoInitialize: ptr.Deref(binding.Spec.Repo.AutoInitialize, false),
+	}
+
+	// Ensure stable ordering so identical inputs do not churn.
+	sort.Strings(repo.Env)
+	sort.Strings(repo.Flags)
 
-	shouldTriggerSnapshotTasks := binding.Spec.Source.Kind == "ReplicationSource" && ptr.Deref(binding.Spec.Repo.TriggerTasksOnSnapshot, false)
-	needsBackrestClient := shouldApplyRepo || shouldTriggerSnapshotTasks
-	var brClient backrestRepoClient
-	if needsBackrestClient {
-		auth, authErr := r.loadBackrestAuth(ctx, &binding)
-		if authErr != nil {
-			return r.fail(ctx, &binding, "BackrestAuthInvalid", authErr)
+	// Each target is applied independently so one unreachable Backrest does not block the others.
+	targets := effectiveTargets(&binding)
+	for _, name := range removedTargets(&binding, targets) {
+		if r.Recorder != nil {
+			r.Recorder.Eventf(&binding, nil, corev1.EventTypeNormal, "TargetRemoved", "SyncTargets", "Backrest target %s was removed from the binding; repo %s stays registered in it", name, repo.GetId())
 		}
-		brClient = r.newBackrestClient(binding.Spec.Backrest.URL, auth)
+	}
+	if syncTargetStatuses(&binding, targets) {
+		statusChanged = true
+	}
+	var failures []targetFailure
+	for i := range targets {
+		changed, release, failure := r.reconcileTarget(ctx, &binding, targets[i], &binding.Status.Targets[i], vsObj, repo, cfg.Markers)
+		if release != nil {
+			defer release()
+		}
+		if changed {
+			statusChanged = true
+		}
+		if failure != nil {
+			failures = append(failures, *failure)
+		}
+	}
+	if mirrorPrimaryTarget(&binding) {
+		statusChanged = true
+	}
+	if setAggregateReady(&binding, len(targets), failures) {
+		statusChanged = true
+	}
+	if setCredentialRotationCondition(&binding, failures) {
+		statusChanged = true
+	}
+	if r.completeManualSync(&binding, vsObj, cfg.Markers) {
+		statusChanged = true
 	}
 
-	if shouldApplyRepo {
-		repo := &v1.Repo{
-			Id:             desiredRepoID(&binding),
-			Uri:            resticRepo,
-			Password:       resticPass,
-			Env:            env,
-			Flags:          append([]string(nil), binding.Spec.Repo.ExtraFlags...),
-			AutoUnlock:     ptr.Deref(binding.Spec.Repo.AutoUnlock, false),
-			AutoInitialize: ptr.Deref(binding.Spec.Repo.AutoInitialize, false),
+	if statusChanged {
+		binding.Status.ObservedGeneration = binding.Generation
+		if res, statusErr := r.updateStatus(ctx, &binding); statusErr != nil || res.RequeueAfter > 0 {
+			return res, statusErr
 		}
+	}
 
-		// Ensure stable ordering so identical inputs do not churn.
-		sort.Strings(repo.Env)
-		sort.Strings(repo.Flags)
+	if len(failures) > 0 {
+		// Trigger controller-runtime exponential backoff without logging the underlying error.
+		return ctrl.Result{}, &sanitizedReconcileError{reason: failures[0].reason, errorHash: failures[0].errorHash}
+	}
+	if err := r.finishRepoIDMigration(ctx, &binding); err != nil {
+		return ctrl.Result{}, err
+	}
+	if manualSyncErr != nil {
+		return ctrl.Result{}, manualSyncErr
+	}
+	if manualSyncAwaitingTasks(&binding) {
+		// Task triggering failed or is in flight elsewhere; retry until every target has it.
+		return ctrl.Result{RequeueAfter: manualSyncRetryInterval}, nil
+	}
+	return ctrl.Result{}, nil
+}
+
+// reconcileTarget registers the repo in one Backrest target and triggers snapshot tasks for it.
+// Only ts is mutated; failures are reported back instead of aborting the whole reconcile.
+func (r *BackrestVolSyncBindingReconciler) reconcileTarget(
+	ctx context.Context,
+	binding *v1alpha1.BackrestVolSyncBinding,
+	target bindingTarget,
+	ts *v1alpha1.BackrestTargetStatus,
+	vsObj *unstructured.Unstructured,
+	repo *v1.Repo,
+	markers *volsync.MarkerExtractor,
+) (bool, func(), *targetFailure) {
+	logger := log.FromContext(ctx)
 
-		_, err = brClient.AddRepo(ctx, repo)
-		if err != nil {
+	backrestURL, instance, err := r.resolveBackrestURL(ctx, target.Connection)
+	if err != nil {
+		return r.targetFailed(ctx, binding, ts, "BackrestInstanceNotFound", err)
+	}
+
+	registrar, err := r.repoRegistrar(ctx, binding, repo.GetId(), backrestURL)
+	if err != nil {
+		return r.targetFailed(ctx, binding, ts, "RepoShareFailed", err)
+	}
+
+	inputHash := computeInputHash(binding, backrestURL, repo, r.InputHashKey)
+	shouldApplyRepo := ts.LastAppliedInputHash != inputHash || !isTargetReady(ts)
+	statusChanged := false
+	if registrar.UID != binding.UID {
+		statusChanged = setRepoShared(binding, ts, registrar)
+		shouldApplyRepo = false
+	}
+	shouldTriggerSnapshotTasks := ptr.Deref(binding.Spec.Repo.TriggerTasksOnSnapshot, false) || manualSyncAwaitingTasks(binding)
+	if !shouldApplyRepo && !shouldTriggerSnapshotTasks {
+		return statusChanged, nil, nil
+	}
+
+	conn, err := r.loadBackrestConnection(ctx, binding.Namespace, target.Connection, backrestURL, instance)
+	if err != nil {
+		return r.targetFailed(ctx, binding, ts, "BackrestAuthInvalid", err)
+	}
+	brClient, err := r.newBackrestClient(conn)
+	if err != nil {
+		return r.targetFailed(ctx, binding, ts, "BackrestAuthInvalid", err)
+	}
+
+	if shouldApplyRepo {
+		credentialDigest := repoCredentialDigest(binding, repo, r.InputHashKey)
+		if ts.LastAppliedCredentialDigest != "" && ts.LastAppliedCredentialDigest != credentialDigest {
+			// Only push rotated credentials, or credentials replacing those another binding of the share
+			// group registered, that actually open the repo; otherwise Backrest keeps the previous config.
+			if err := validateRepoCredentials(ctx, brClient, repo); err != nil {
+				return r.targetFailed(ctx, binding, ts, reasonCredentialRotationFailed, err)
+			}
+		}
+		if _, err := brClient.AddRepo(ctx, repo); err != nil {
 			if isAlreadyInitializedError(err) {
 				logger.Info(
 					"Backrest repo already initialized; treating as applied",
 					"repoID", repo.Id,
+					"target", target.Name,
 					"volsyncKind", binding.Spec.Source.Kind,
 					"volsyncName", binding.Spec.Source.Name,
 				)
 			} else {
-				return r.fail(ctx, &binding, "BackrestAddRepoFailed", err)
+				return r.targetFailed(ctx, binding, ts, "BackrestAddRepoFailed", err)
 			}
 		}
 
 		logger.Info(
 			"Backrest repo applied",
 			"repoID", repo.Id,
+			"target", target.Name,
 			"volsyncKind", binding.Spec.Source.Kind,
 			"volsyncName", binding.Spec.Source.Name,
 		)
 		if r.Recorder != nil {
-			r.Recorder.Eventf(&binding, nil, corev1.EventTypeNormal, "Applied", "RegisterRepository", "Repository registered/updated in Backrest")
+			r.Recorder.Eventf(binding, nil, corev1.EventTypeNormal, "Applied", "RegisterRepository", "Repository registered/updated in Backrest target %s", target.Name)
 		}
 
 		now := metav1.Now()
-		binding.Status.LastAppliedInputHash = inputHash
-		binding.Status.LastApplyTime = &now
-		meta.SetStatusCondition(&binding.Status.Conditions, metav1.Condition{
+		ts.LastAppliedInputHash = inputHash
+		ts.LastAppliedCredentialDigest = credentialDigest
+		ts.LastApplyTime = &now
+		ts.LastErrorHash = ""
+		meta.SetStatusCondition(&ts.Conditions, metav1.Condition{
 			Type:               conditionReady,
 			Status:             metav1.ConditionTrue,
 			Reason:             "Applied",
@@ -193,47 +354,63 @@ func (r *BackrestVolSyncBindingReconciler) Reconcile(ctx context.Context, req ct
 		statusChanged = true
 	}
 
+	var releaseTaskTrigger func()
 	if shouldTriggerSnapshotTasks {
-		taskStatusChanged, releaseTaskTrigger := r.triggerSnapshotTasks(ctx, &binding, vsObj, brClient)
-		if releaseTaskTrigger != nil {
-			defer releaseTaskTrigger()
-		}
+		var taskStatusChanged bool
+		taskStatusChanged, releaseTaskTrigger = r.triggerSnapshotTasks(ctx, binding, target.Name, ts, vsObj, markers, brClient)
 		if taskStatusChanged {
 			statusChanged = true
 		}
 	}
+	return statusChanged, releaseTaskTrigger, nil
+}
 
-	if statusChanged {
-		binding.Status.ObservedGeneration = binding.Generation
-		if res, statusErr := r.updateStatus(ctx, &binding); statusErr != nil || res.RequeueAfter > 0 {
-			return res, statusErr
-		}
+func (r *BackrestVolSyncBindingReconciler) targetFailed(ctx context.Context, binding *v1alpha1.BackrestVolSyncBinding, ts *v1alpha1.BackrestTargetStatus, reason string, err error) (bool, func(), *targetFailure) {
+	errHash := hashString(err.Error())
+	ts.LastErrorHash = errHash
+	if r.Recorder != nil {
+		r.Recorder.Eventf(binding, nil, corev1.EventTypeWarning, reason, "ReconcileFailed", "Reconcile failed for Backrest target %s (errorHash=%s)", ts.Name, errHash)
 	}
-
-	return ctrl.Result{}, nil
+	log.FromContext(ctx).Info(
+		"Reconcile failed for Backrest target",
+		"reason", reason,
+		"target", ts.Name,
+		"namespace", binding.Namespace,
+		"name", binding.Name,
+		"errorHash", errHash,
+	)
+	meta.SetStatusCondition(&ts.Conditions, metav1.Condition{
+		Type:               conditionReady,
+		Status:             metav1.ConditionFalse,
+		Reason:             reason,
+		Message:            fmt.Sprintf("%s (details omitted; errorHash=%s)", reason, errHash),
+		ObservedGeneration: binding.Generation,
+		LastTransitionTime: metav1.Now(),
+	})
+	return true, nil, &targetFailure{target: ts.Name, reason: reason, errorHash: errHash}
 }
 
-func (r *BackrestVolSyncBindingReconciler) newBackrestClient(baseURL string, auth backrest.Auth) backrestRepoClient {
+func (r *BackrestVolSyncBindingReconciler) newBackrestClient(conn backrestConnection) (backrestRepoClient, error) {
 	if r.BackrestClientFactory != nil {
-		return r.BackrestClientFactory(baseURL, auth)
+		return r.BackrestClientFactory(conn.URL, conn.Auth, conn.Options), nil
 	}
-	return backrest.New(baseURL, auth)
+	return backrest.NewWithOptions(conn.URL, conn.Auth, conn.Options)
 }
 
-func (r *BackrestVolSyncBindingReconciler) triggerSnapshotTasks(ctx context.Context, binding *v1alpha1.BackrestVolSyncBinding, vsObj *unstructured.Unstructured, brClient backrestRepoClient) (bool, func()) {
+func (r *BackrestVolSyncBindingReconciler) triggerSnapshotTasks(ctx context.Context, binding *v1alpha1.BackrestVolSyncBinding, targetName string, ts *v1alpha1.BackrestTargetStatus, vsObj *unstructured.Unstructured, markers *volsync.MarkerExtractor, brClient backrestRepoClient) (bool, func()) {
 	logger := log.FromContext(ctx)
 	statusChanged := false
 	setTaskErrorHash := func(errHash string) {
-		if binding.Status.LastRepoTaskErrorHash != errHash {
-			binding.Status.LastRepoTaskErrorHash = errHash
+		if ts.LastRepoTaskErrorHash != errHash {
+			ts.LastRepoTaskErrorHash = errHash
 			statusChanged = true
 		}
 	}
 
-	marker, syncTime, ready, err := volsync.ReplicationSourceCompletionMarker(vsObj)
+	marker, syncTime, ready, err := markers.CompletionMarker(vsObj)
 	if err != nil {
 		errHash := hashString(err.Error())
-		if binding.Status.LastRepoTaskErrorHash == errHash {
+		if ts.LastRepoTaskErrorHash == errHash {
 			return false, nil
 		}
 		setTaskErrorHash(errHash)
@@ -246,17 +423,17 @@ func (r *BackrestVolSyncBindingReconciler) triggerSnapshotTasks(ctx context.Cont
 	if !ready || marker == "" {
 		return false, nil
 	}
-	if snapshotTaskStateMatches(marker, syncTime, binding.Status.LastSnapshotMarker, binding.Status.LastSnapshotSyncTime) {
+	if snapshotTaskStateMatches(marker, syncTime, ts.LastSnapshotMarker, ts.LastSnapshotSyncTime) {
 		return false, nil
 	}
 
-	releaseTaskTrigger, claimed := r.claimTaskTrigger(binding, marker)
+	releaseTaskTrigger, claimed := r.claimTaskTrigger(binding, targetName, marker)
 	if !claimed {
 		return false, nil
 	}
 
 	repoID := desiredRepoID(binding)
-	if !snapshotTaskStateMatches(marker, syncTime, binding.Status.LastIndexedSnapshotMarker, binding.Status.LastIndexedSnapshotSyncTime) {
+	if !snapshotTaskStateMatches(marker, syncTime, ts.LastIndexedSnapshotMarker, ts.LastIndexedSnapshotSyncTime) {
 		if err := brClient.DoRepoTask(ctx, repoID, v1.DoRepoTaskRequest_TASK_INDEX_SNAPSHOTS); err != nil {
 			errHash := hashString(err.Error())
 			setTaskErrorHash(errHash)
@@ -269,12 +446,13 @@ func (r *BackrestVolSyncBindingReconciler) triggerSnapshotTasks(ctx context.Cont
 				"task", v1.DoRepoTaskRequest_TASK_INDEX_SNAPSHOTS.String(),
 				"namespace", binding.Namespace,
 				"name", binding.Name,
+				"target", targetName,
 				"errorHash", errHash,
 			)
 			return statusChanged, releaseTaskTrigger
 		}
-		binding.Status.LastIndexedSnapshotMarker = marker
-		binding.Status.LastIndexedSnapshotSyncTime = syncTime
+		ts.LastIndexedSnapshotMarker = marker
+		ts.LastIndexedSnapshotSyncTime = syncTime
 		statusChanged = true
 	}
 
@@ -290,25 +468,27 @@ func (r *BackrestVolSyncBindingReconciler) triggerSnapshotTasks(ctx context.Cont
 			"task", v1.DoRepoTaskRequest_TASK_STATS.String(),
 			"namespace", binding.Namespace,
 			"name", binding.Name,
+			"target", targetName,
 			"errorHash", errHash,
 		)
 		return statusChanged, releaseTaskTrigger
 	}
 
 	now := metav1.Now()
-	binding.Status.LastSnapshotMarker = marker
-	binding.Status.LastSnapshotSyncTime = syncTime
-	binding.Status.LastRepoTaskTriggerTime = &now
-	if binding.Status.LastRepoTaskErrorHash != "" {
-		binding.Status.LastRepoTaskErrorHash = ""
+	ts.LastSnapshotMarker = marker
+	ts.LastSnapshotSyncTime = syncTime
+	ts.LastRepoTaskTriggerTime = &now
+	if ts.LastRepoTaskErrorHash != "" {
+		ts.LastRepoTaskErrorHash = ""
 	}
 	statusChanged = true
 	if r.Recorder != nil {
-		r.Recorder.Eventf(binding, nil, corev1.EventTypeNormal, "TasksTriggered", "DoRepoTask", "Triggered INDEX_SNAPSHOTS and STATS for repo %s", repoID)
+		r.Recorder.Eventf(binding, nil, corev1.EventTypeNormal, "TasksTriggered", "DoRepoTask", "Triggered INDEX_SNAPSHOTS and STATS for repo %s in Backrest target %s", repoID, targetName)
 	}
 	logger.Info(
 		"Triggered Backrest repo tasks for snapshot completion",
 		"repoID", repoID,
+		"target", targetName,
 		"namespace", binding.Namespace,
 		"name", binding.Name,
 	)
@@ -325,8 +505,8 @@ func snapshotTaskStateMatches(marker, syncTime, storedMarker, storedSyncTime str
 	return false
 }
 
-func (r *BackrestVolSyncBindingReconciler) claimTaskTrigger(binding *v1alpha1.BackrestVolSyncBinding, marker string) (func(), bool) {
-	key := types.NamespacedName{Namespace: binding.Namespace, Name: binding.Name}.String()
+func (r *BackrestVolSyncBindingReconciler) claimTaskTrigger(binding *v1alpha1.BackrestVolSyncBinding, targetName, marker string) (func(), bool) {
+	key := types.NamespacedName{Namespace: binding.Namespace, Name: binding.Name}.String() + "/" + targetName
 
 	r.taskTriggerMu.Lock()
 	defer r.taskTriggerMu.Unlock()
@@ -368,6 +548,15 @@ func (r *BackrestVolSyncBindingReconciler) updateStatus(ctx context.Context, bin
 }
 
 func (r *BackrestVolSyncBindingReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	if err := r.SetupIndexes(mgr); err != nil {
+		return err
+	}
+	return r.SetupControllerWithManager(mgr)
+}
+
+// SetupIndexes registers the binding field indexes. Other controllers (BackrestInstance) rely on them,
+// so they are registered at startup even when the binding controller itself starts later.
+func (r *BackrestVolSyncBindingReconciler) SetupIndexes(mgr ctrl.Manager) error {
 	ctx := context.Background()
 
 	if err := mgr.GetFieldIndexer().IndexField(ctx, &v1alpha1.BackrestVolSyncBinding{}, indexRepositorySecret, func(obj client.Object) []string {
@@ -396,31 +585,99 @@ func (r *BackrestVolSyncBindingReconciler) SetupWithManager(mgr ctrl.Manager) er
 		return err
 	}
 
+	if err := mgr.GetFieldIndexer().IndexField(ctx, &v1alpha1.BackrestVolSyncBinding{}, indexBackrestInstance, func(obj client.Object) []string {
+		b, ok := obj.(*v1alpha1.BackrestVolSyncBinding)
+		if !ok {
+			return nil
+		}
+		return bindingInstanceRefs(b)
+	}); err != nil {
+		return err
+	}
+
+	if err := mgr.GetFieldIndexer().IndexField(ctx, &v1alpha1.BackrestVolSyncBinding{}, indexEnvFrom, func(obj client.Object) []string {
+		b, ok := obj.(*v1alpha1.BackrestVolSyncBinding)
+		if !ok {
+			return nil
+		}
+		return envFromIndexValues(b)
+	}); err != nil {
+		return err
+	}
+
+	if err := mgr.GetFieldIndexer().IndexField(ctx, &v1alpha1.BackrestVolSyncBinding{}, indexRepoID, func(obj client.Object) []string {
+		b, ok := obj.(*v1alpha1.BackrestVolSyncBinding)
+		if !ok {
+			return nil
+		}
+		return []string{desiredRepoID(b)}
+	}); err != nil {
+		return err
+	}
+
+	if err := mgr.GetFieldIndexer().IndexField(ctx, &v1alpha1.BackrestVolSyncBinding{}, indexRepoShareKey, func(obj client.Object) []string {
+		b, ok := obj.(*v1alpha1.BackrestVolSyncBinding)
+		if !ok || b.Status.RepoShareKey == "" {
+			return nil
+		}
+		return []string{b.Status.RepoShareKey}
+	}); err != nil {
+		return err
+	}
+	return nil
+}
+
+// SetupControllerWithManager registers the binding controller; the indexes must already be registered.
+func (r *BackrestVolSyncBindingReconciler) SetupControllerWithManager(mgr ctrl.Manager) error {
 	rs := &unstructured.Unstructured{}
-	rs.SetGroupVersionKind(schema.GroupVersionKind{Group: volsync.Group, Version: volsync.Version, Kind: "ReplicationSource"})
+	rs.SetGroupVersionKind(r.VolSync.GVK("ReplicationSource"))
 	rd := &unstructured.Unstructured{}
-	rd.SetGroupVersionKind(schema.GroupVersionKind{Group: volsync.Group, Version: volsync.Version, Kind: "ReplicationDestination"})
+	rd.SetGroupVersionKind(r.VolSync.GVK("ReplicationDestination"))
 
 	return ctrl.NewControllerManagedBy(mgr).
 		For(&v1alpha1.BackrestVolSyncBinding{}).
-		Watches(&v1alpha1.BackrestVolSyncOperatorConfig{}, handler.EnqueueRequestsFromMapFunc(func(ctx context.Context, obj client.Object) []reconcile.Request {
-			if r.OperatorConfig.Name == "" || r.OperatorConfig.Namespace == "" {
+		Watches(&v1alpha1.BackrestInstance{}, handler.EnqueueRequestsFromMapFunc(func(ctx context.Context, obj client.Object) []reconcile.Request {
+			var list v1alpha1.BackrestVolSyncBindingList
+			if err := r.List(ctx, &list, client.MatchingFields{indexBackrestInstance: obj.GetName()}); err != nil {
 				return nil
 			}
-			if obj.GetNamespace() != r.OperatorConfig.Namespace || obj.GetName() != r.OperatorConfig.Name {
+			reqs := make([]reconcile.Request, 0, len(list.Items))
+			for i := range list.Items {
+				reqs = append(reqs, reconcile.Request{NamespacedName: types.NamespacedName{Namespace: list.Items[i].Namespace, Name: list.Items[i].Name}})
+			}
+			return reqs
+		})).
+		// When a binding of a share group is gone, the next oldest member registers the repo.
+		Watches(&v1alpha1.BackrestVolSyncBinding{}, handler.EnqueueRequestsFromMapFunc(func(ctx context.Context, obj client.Object) []reconcile.Request {
+			b, ok := obj.(*v1alpha1.BackrestVolSyncBinding)
+			if !ok || b.Status.RepoShareKey == "" {
 				return nil
 			}
 			var list v1alpha1.BackrestVolSyncBindingList
-			// Bindings can exist in any namespace (auto-binding is cluster-wide), so requeue them all.
-			if err := r.List(ctx, &list); err != nil {
+			if err := r.List(ctx, &list, client.MatchingFields{indexRepoShareKey: b.Status.RepoShareKey}); err != nil {
 				return nil
 			}
 			reqs := make([]reconcile.Request, 0, len(list.Items))
 			for i := range list.Items {
-				reqs = append(reqs, reconcile.Request{NamespacedName: types.NamespacedName{Namespace: list.Items[i].Namespace, Name: list.Items[i].Name}})
+				if list.Items[i].UID != b.UID {
+					reqs = append(reqs, reconcile.Request{NamespacedName: types.NamespacedName{Namespace: list.Items[i].Namespace, Name: list.Items[i].Name}})
+				}
 			}
 			return reqs
+		}), builder.WithPredicates(predicate.Funcs{
+			CreateFunc:  func(event.CreateEvent) bool { return false },
+			UpdateFunc:  func(event.UpdateEvent) bool { return false },
+			GenericFunc: func(event.GenericEvent) bool { return false },
 		})).
+		// Only changes to the fields the binding controller reads fan out, and only to the bindings
+		// they affect; see bindingAffectedByConfigChange.
+		Watches(&v1alpha1.BackrestVolSyncOperatorConfig{}, r.configChangeHandler(),
+			builder.WithPredicates(operatorConfigChanged(r.OperatorConfig, bindingConfigFields))).
+		// Managed bindings read the namespace config of their namespace. Spec changes only; the namespace
+		// config controller writes its status.
+		Watches(&v1alpha1.BackrestVolSyncNamespaceConfig{}, handler.EnqueueRequestsFromMapFunc(func(ctx context.Context, obj client.Object) []reconcile.Request {
+			return r.managedBindingsInNamespace(ctx, obj.GetNamespace())
+		}), builder.WithPredicates(predicate.GenerationChangedPredicate{})).
 		Watches(&corev1.Secret{}, handler.EnqueueRequestsFromMapFunc(func(ctx context.Context, obj client.Object) []reconcile.Request {
 			secret, ok := obj.(*corev1.Secret)
 			if !ok {
@@ -430,6 +687,27 @@ func (r *BackrestVolSyncBindingReconciler) SetupWithManager(mgr ctrl.Manager) er
 			if err := r.List(ctx, &list, client.InNamespace(secret.Namespace), client.MatchingFields{indexRepositorySecret: secret.Name}); err != nil {
 				return nil
 			}
+			var envFromList v1alpha1.BackrestVolSyncBindingList
+			if err := r.List(ctx, &envFromList, client.InNamespace(secret.Namespace), client.MatchingFields{indexEnvFrom: "secret/" + secret.Name}); err != nil {
+				return nil
+			}
+			seen := map[types.NamespacedName]struct{}{}
+			reqs := make([]reconcile.Request, 0, len(list.Items)+len(envFromList.Items))
+			for _, b := range append(list.Items, envFromList.Items...) {
+				nn := types.NamespacedName{Namespace: b.Namespace, Name: b.Name}
+				if _, ok := seen[nn]; ok {
+					continue
+				}
+				seen[nn] = struct{}{}
+				reqs = append(reqs, reconcile.Request{NamespacedName: nn})
+			}
+			return reqs
+		})).
+		Watches(&corev1.ConfigMap{}, handler.EnqueueRequestsFromMapFunc(func(ctx context.Context, obj client.Object) []reconcile.Request {
+			var list v1alpha1.BackrestVolSyncBindingList
+			if err := r.List(ctx, &list, client.InNamespace(obj.GetNamespace()), client.MatchingFields{indexEnvFrom: "configmap/" + obj.GetName()}); err != nil {
+				return nil
+			}
 			reqs := make([]reconcile.Request, 0, len(list.Items))
 			for i := range list.Items {
 				reqs = append(reqs, reconcile.Request{NamespacedName: types.NamespacedName{Namespace: list.Items[i].Namespace, Name: list.Items[i].Name}})
@@ -477,8 +755,21 @@ func (r *BackrestVolSyncBindingReconciler) SetupWithManager(mgr ctrl.Manager) er
 		Complete(r)
 }
 
+// managedBindingsInNamespace enqueues the managed bindings of a namespace.
+func (r *BackrestVolSyncBindingReconciler) managedBindingsInNamespace(ctx context.Context, namespace string) []reconcile.Request {
+	var list v1alpha1.BackrestVolSyncBindingList
+	if err := r.List(ctx, &list, client.InNamespace(namespace), client.MatchingLabels{labelManaged: "true"}); err != nil {
+		return nil
+	}
+	reqs := make([]reconcile.Request, 0, len(list.Items))
+	for i := range list.Items {
+		reqs = append(reqs, reconcile.Request{NamespacedName: types.NamespacedName{Namespace: list.Items[i].Namespace, Name: list.Items[i].Name}})
+	}
+	return reqs
+}
+
 func (r *BackrestVolSyncBindingReconciler) getVolSyncObject(ctx context.Context, binding *v1alpha1.BackrestVolSyncBinding) (*unstructured.Unstructured, error) {
-	gvk := schema.GroupVersionKind{Group: volsync.Group, Version: volsync.Version, Kind: binding.Spec.Source.Kind}
+	gvk := r.VolSync.GVK(binding.Spec.Source.Kind)
 	obj := &unstructured.Unstructured{}
 	obj.SetGroupVersionKind(gvk)
 	if err := r.Get(ctx, types.NamespacedName{Namespace: binding.Namespace, Name: binding.Spec.Source.Name}, obj); err != nil {
@@ -487,26 +778,32 @@ func (r *BackrestVolSyncBindingReconciler) getVolSyncObject(ctx context.Context,
 	return obj, nil
 }
 
-func (r *BackrestVolSyncBindingReconciler) loadBackrestAuth(ctx context.Context, binding *v1alpha1.BackrestVolSyncBinding) (backrest.Auth, error) {
-	if binding.Spec.Backrest.AuthRef == nil || binding.Spec.Backrest.AuthRef.Name == "" {
-		return backrest.Auth{}, nil
+// resolveBackrestURL returns the effective Backrest URL and, when instanceRef is used, the selected BackrestInstance.
+func (r *BackrestVolSyncBindingReconciler) resolveBackrestURL(ctx context.Context, conn v1alpha1.BackrestConnection) (string, *v1alpha1.BackrestInstance, error) {
+	if conn.InstanceRef == nil {
+		return conn.URL, nil, nil
+	}
+	var inst v1alpha1.BackrestInstance
+	if err := r.Get(ctx, types.NamespacedName{Name: conn.InstanceRef.Name}, &inst); err != nil {
+		return "", nil, err
 	}
-	var sec corev1.Secret
-	if err := r.Get(ctx, types.NamespacedName{Namespace: binding.Namespace, Name: binding.Spec.Backrest.AuthRef.Name}, &sec); err != nil {
-		return backrest.Auth{}, err
+	return inst.Spec.URL, &inst, nil
+}
+
+func (r *BackrestVolSyncBindingReconciler) loadBackrestConnection(ctx context.Context, namespace string, spec v1alpha1.BackrestConnection, backrestURL string, inst *v1alpha1.BackrestInstance) (backrestConnection, error) {
+	if inst != nil {
+		return loadInstanceConnection(ctx, r.Client, inst, &r.rateLimiters)
 	}
-	// Supported keys:
-	// - token (bearer)
-	// - username/password (basic)
-	if b, ok := sec.Data["token"]; ok && len(b) > 0 {
-		return backrest.Auth{BearerToken: string(b)}, nil
+	conn := backrestConnection{URL: backrestURL}
+	if spec.AuthRef == nil || spec.AuthRef.Name == "" {
+		return conn, nil
 	}
-	user := string(sec.Data["username"])
-	pass := string(sec.Data["password"])
-	if user == "" && pass == "" {
-		return backrest.Auth{}, fmt.Errorf("auth secret must contain either 'token' or 'username'/'password'")
+	auth, err := loadAuthSecret(ctx, r.Client, types.NamespacedName{Namespace: namespace, Name: spec.AuthRef.Name})
+	if err != nil {
+		return backrestConnection{}, err
 	}
-	return backrest.Auth{BasicUsername: user, BasicPassword: pass}, nil
+	conn.Auth = auth
+	return conn, nil
 }
 
 func (r *BackrestVolSyncBindingReconciler) fail(ctx context.Context, binding *v1alpha1.BackrestVolSyncBinding, reason string, err error) (ctrl.Result, error) {
@@ -551,87 +848,101 @@ func hashString(s string) string {
 	return hex.EncodeToString(sum[:])
 }
 
-func validateBinding(b *v1alpha1.BackrestVolSyncBinding) field.ErrorList {
+// validateBinding validates the binding spec and returns its compiled spec.repo.uriRewrites.
+func validateBinding(b *v1alpha1.BackrestVolSyncBinding) ([]uriRewriteRule, field.ErrorList) {
 	var errs field.ErrorList
-	if b.Spec.Backrest.URL == "" {
-		errs = append(errs, field.Required(field.NewPath("spec", "backrest", "url"), "required"))
-	}
+	errs = append(errs, validateTargets(b)...)
+	errs = append(errs, validateEnvFrom(b.Spec.Repo.EnvFrom, field.NewPath("spec", "repo", "envFrom"))...)
+	rewrites, rewriteErrs := compileURIRewrites(b.Spec.Repo.URIRewrites, field.NewPath("spec", "repo", "uriRewrites"))
+	errs = append(errs, rewriteErrs...)
 	if b.Spec.Source.Kind != "ReplicationSource" && b.Spec.Source.Kind != "ReplicationDestination" {
 		errs = append(errs, field.Invalid(field.NewPath("spec", "source", "kind"), b.Spec.Source.Kind, "must be ReplicationSource or ReplicationDestination"))
 	}
 	if b.Spec.Source.Name == "" {
 		errs = append(errs, field.Required(field.NewPath("spec", "source", "name"), "required"))
 	}
+	return rewrites, errs
+}
+
+func validateBackrestConnection(conn v1alpha1.BackrestConnection, path *field.Path) field.ErrorList {
+	var errs field.ErrorList
+	if conn.InstanceRef != nil {
+		if conn.InstanceRef.Name == "" {
+			errs = append(errs, field.Required(path.Child("instanceRef", "name"), "required"))
+		}
+		if conn.URL != "" {
+			errs = append(errs, field.Forbidden(path.Child("url"), "must not be set together with instanceRef"))
+		}
+		if conn.AuthRef != nil {
+			errs = append(errs, field.Forbidden(path.Child("authRef"), "must not be set together with instanceRef"))
+		}
+		return errs
+	}
+	if conn.URL == "" {
+		errs = append(errs, field.Required(path.Child("url"), "required unless instanceRef is set"))
+	}
 	return errs
 }
 
+// desiredRepoID returns the repo ID resolved by resolveRepoID, falling back to the override or default
+// ID for bindings that were not reconciled yet.
 func desiredRepoID(b *v1alpha1.BackrestVolSyncBinding) string {
 	if b.Spec.Repo.IDOverride != "" {
 		return b.Spec.Repo.IDOverride
 	}
-	return fmt.Sprintf("volsync-%s-%s-%s", b.Namespace, strings.ToLower(b.Spec.Source.Kind), b.Spec.Source.Name)
-}
-
-func extractResticSecret(sec *corev1.Secret, allowlist []string) (repo string, pass string, env []string, err error) {
-	repo = strings.TrimSpace(string(sec.Data["RESTIC_REPOSITORY"]))
-	pass = string(sec.Data["RESTIC_PASSWORD"])
-	if repo == "" {
-		return "", "", nil, fmt.Errorf("missing RESTIC_REPOSITORY")
-	}
-	if pass == "" {
-		return "", "", nil, fmt.Errorf("missing RESTIC_PASSWORD")
-	}
-
-	allowed := map[string]struct{}{}
-	if len(allowlist) > 0 {
-		for _, k := range allowlist {
-			allowed[k] = struct{}{}
-		}
-	}
-
-	for k, v := range sec.Data {
-		if k == "RESTIC_REPOSITORY" || k == "RESTIC_PASSWORD" {
-			continue
-		}
-		if strings.HasPrefix(k, "RESTIC_") {
-			continue
-		}
-		if len(allowed) > 0 {
-			if _, ok := allowed[k]; !ok {
-				continue
-			}
-		}
-		env = append(env, k+"="+string(v))
+	if b.Status.RepoID != "" {
+		return b.Status.RepoID
 	}
-	return repo, pass, env, nil
+	return defaultRepoID(b)
 }
 
-func computeInputHash(binding *v1alpha1.BackrestVolSyncBinding, vsObj *unstructured.Unstructured, sec *corev1.Secret) string {
+// computeInputHash covers exactly what Backrest sees for a target: the endpoint and the v1.Repo
+// content. Metadata-only changes of the source Secrets (labels, a refresh with identical data)
+// therefore do not re-register the repo.
+func computeInputHash(binding *v1alpha1.BackrestVolSyncBinding, backrestURL string, repo *v1.Repo, key []byte) string {
 	h := sha256.New()
 	write := func(s string) {
 		_, _ = h.Write([]byte(s))
 		_, _ = h.Write([]byte{0})
 	}
-	write(binding.Spec.Backrest.URL)
+	write(backrestURL)
 	write(binding.Spec.Source.Kind)
 	write(binding.Spec.Source.Name)
-	write(desiredRepoID(binding))
-	write(fmt.Sprintf("autoUnlock=%v", ptr.Deref(binding.Spec.Repo.AutoUnlock, false)))
-	write(fmt.Sprintf("autoInitialize=%v", ptr.Deref(binding.Spec.Repo.AutoInitialize, false)))
-	flags := append([]string(nil), binding.Spec.Repo.ExtraFlags...)
+	write(repo.GetId())
+	write(fmt.Sprintf("autoUnlock=%v", repo.GetAutoUnlock()))
+	write(fmt.Sprintf("autoInitialize=%v", repo.GetAutoInitialize()))
+	flags := append([]string(nil), repo.GetFlags()...)
 	sort.Strings(flags)
 	write("flags=" + strings.Join(flags, ","))
-	allow := append([]string(nil), binding.Spec.Repo.EnvAllowlist...)
-	sort.Strings(allow)
-	write("envAllowlist=" + strings.Join(allow, ","))
-	write("volsync.uid=" + string(vsObj.GetUID()))
-	write("secret.uid=" + string(sec.GetUID()))
-	write("secret.rv=" + sec.GetResourceVersion())
+	write("repo=" + repoContentDigest(binding, repo, key))
 	sum := h.Sum(nil)
 	return hex.EncodeToString(sum)
 }
 
-func isReady(b *v1alpha1.BackrestVolSyncBinding) bool {
-	cond := meta.FindStatusCondition(b.Status.Conditions, conditionReady)
+// repoContentDigest is an HMAC over the repo URI, password and env. It is keyed with the operator's
+// input hash key (if any) and the binding UID so the status hash cannot be used to test password guesses
+// or to tell whether two bindings share credentials.
+func repoContentDigest(binding *v1alpha1.BackrestVolSyncBinding, repo *v1.Repo, key []byte) string {
+	mac := newRepoMAC(binding, key)
+	write := func(s string) {
+		_, _ = mac.Write([]byte(s))
+		_, _ = mac.Write([]byte{0})
+	}
+	write(repo.GetUri())
+	write(repo.GetPassword())
+	env := append([]string(nil), repo.GetEnv()...)
+	sort.Strings(env)
+	for _, e := range env {
+		write(e)
+	}
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func newRepoMAC(binding *v1alpha1.BackrestVolSyncBinding, key []byte) hash.Hash {
+	return hmac.New(sha256.New, append(append([]byte(nil), key...), binding.GetUID()...))
+}
+
+func isTargetReady(ts *v1alpha1.BackrestTargetStatus) bool {
+	cond := meta.FindStatusCondition(ts.Conditions, conditionReady)
 	return cond != nil && cond.Status == metav1.ConditionTrue
 }
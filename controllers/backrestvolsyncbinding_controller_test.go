@@ -3,6 +3,7 @@ package controllers
 import (
 	"context"
 	"errors"
+	"strings"
 	"sync"
 	"testing"
 
@@ -16,6 +17,7 @@ import (
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/events"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
@@ -24,20 +26,40 @@ import (
 type fakeBackrestRepoClient struct {
 	mu                sync.Mutex
 	addRepoCalls      int
+	addRepoErr        error
+	lastRepo          *v1.Repo
+	checkRepoCalls    int
+	checkRepoErr      error
+	checkRepoMissing  bool
 	taskCalls         []v1.DoRepoTaskRequest_Task
 	failTaskErrs      map[v1.DoRepoTaskRequest_Task]error
 	firstTaskStarted  chan struct{}
 	releaseTaskCalls  <-chan struct{}
 	firstTaskSignaled bool
+	removedRepoIDs    []string
 }
 
-func (f *fakeBackrestRepoClient) AddRepo(_ context.Context, _ *v1.Repo) (*v1.Config, error) {
+func (f *fakeBackrestRepoClient) AddRepo(_ context.Context, repo *v1.Repo) (*v1.Config, error) {
 	f.mu.Lock()
 	defer f.mu.Unlock()
 	f.addRepoCalls++
+	if f.addRepoErr != nil {
+		return nil, f.addRepoErr
+	}
+	f.lastRepo = repo
 	return &v1.Config{}, nil
 }
 
+func (f *fakeBackrestRepoClient) CheckRepoExists(_ context.Context, _ *v1.Repo) (bool, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.checkRepoCalls++
+	if f.checkRepoErr != nil {
+		return false, f.checkRepoErr
+	}
+	return !f.checkRepoMissing, nil
+}
+
 func (f *fakeBackrestRepoClient) DoRepoTask(_ context.Context, _ string, task v1.DoRepoTaskRequest_Task) error {
 	f.mu.Lock()
 	f.taskCalls = append(f.taskCalls, task)
@@ -60,6 +82,13 @@ func (f *fakeBackrestRepoClient) DoRepoTask(_ context.Context, _ string, task v1
 	return nil
 }
 
+func (f *fakeBackrestRepoClient) RemoveRepo(_ context.Context, repoID string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.removedRepoIDs = append(f.removedRepoIDs, repoID)
+	return nil
+}
+
 func (f *fakeBackrestRepoClient) snapshotTaskCalls() []v1.DoRepoTaskRequest_Task {
 	f.mu.Lock()
 	defer f.mu.Unlock()
@@ -354,7 +383,7 @@ func TestBackrestVolSyncBindingReconcile_TriggersSnapshotTasksWithDedupe(t *test
 	r := &BackrestVolSyncBindingReconciler{
 		Client: c,
 		Scheme: scheme,
-		BackrestClientFactory: func(_ string, _ backrest.Auth) backrestRepoClient {
+		BackrestClientFactory: func(_ string, _ backrest.Auth, _ backrest.Options) backrestRepoClient {
 			return br
 		},
 	}
@@ -470,7 +499,7 @@ func TestBackrestVolSyncBindingReconcile_DoesNotRetriggerWhenOnlyLastSyncTimeCha
 	r := &BackrestVolSyncBindingReconciler{
 		Client: c,
 		Scheme: scheme,
-		BackrestClientFactory: func(_ string, _ backrest.Auth) backrestRepoClient {
+		BackrestClientFactory: func(_ string, _ backrest.Auth, _ backrest.Options) backrestRepoClient {
 			return br
 		},
 	}
@@ -556,7 +585,7 @@ func TestBackrestVolSyncBindingReconcile_DoesNotRetriggerWhenSnapshotFieldChange
 	r := &BackrestVolSyncBindingReconciler{
 		Client: c,
 		Scheme: scheme,
-		BackrestClientFactory: func(_ string, _ backrest.Auth) backrestRepoClient {
+		BackrestClientFactory: func(_ string, _ backrest.Auth, _ backrest.Options) backrestRepoClient {
 			return br
 		},
 	}
@@ -648,7 +677,7 @@ func TestBackrestVolSyncBindingReconcile_DeduplicatesConcurrentSnapshotTriggers(
 	r := &BackrestVolSyncBindingReconciler{
 		Client: c,
 		Scheme: scheme,
-		BackrestClientFactory: func(_ string, _ backrest.Auth) backrestRepoClient {
+		BackrestClientFactory: func(_ string, _ backrest.Auth, _ backrest.Options) backrestRepoClient {
 			return br
 		},
 	}
@@ -733,7 +762,7 @@ func TestBackrestVolSyncBindingReconcile_DoesNotTriggerSnapshotTasksByDefault(t
 	r := &BackrestVolSyncBindingReconciler{
 		Client: c,
 		Scheme: scheme,
-		BackrestClientFactory: func(_ string, _ backrest.Auth) backrestRepoClient {
+		BackrestClientFactory: func(_ string, _ backrest.Auth, _ backrest.Options) backrestRepoClient {
 			return br
 		},
 	}
@@ -807,7 +836,7 @@ func TestBackrestVolSyncBindingReconcile_DoesNotReenqueueIndexWhenOnlyStatsFails
 	r := &BackrestVolSyncBindingReconciler{
 		Client: c,
 		Scheme: scheme,
-		BackrestClientFactory: func(_ string, _ backrest.Auth) backrestRepoClient {
+		BackrestClientFactory: func(_ string, _ backrest.Auth, _ backrest.Options) backrestRepoClient {
 			return br
 		},
 	}
@@ -852,5 +881,272 @@ func TestBackrestVolSyncBindingReconcile_DoesNotReenqueueIndexWhenOnlyStatsFails
 	}
 }
 
+func TestBackrestVolSyncBindingReconcile_MultipleTargetsAreIndependent(t *testing.T) {
+	ctx := context.Background()
+	scheme := bindingTestScheme(t)
+
+	b := &v1alpha1.BackrestVolSyncBinding{}
+	b.Namespace = "workload"
+	b.Name = "b"
+	b.Spec.Backrest.URL = "http://primary.invalid"
+	b.Spec.Targets = []v1alpha1.BackrestTarget{
+		{Name: "offsite", BackrestConnection: v1alpha1.BackrestConnection{URL: "http://offsite.invalid"}},
+	}
+	b.Spec.Source = v1alpha1.VolSyncSourceRef{Kind: "ReplicationSource", Name: "demo"}
+	enabled := true
+	b.Spec.Repo.TriggerTasksOnSnapshot = &enabled
+
+	vs := &unstructured.Unstructured{Object: map[string]any{
+		"apiVersion": volsync.Group + "/" + volsync.Version,
+		"kind":       "ReplicationSource",
+		"metadata": map[string]any{
+			"name":      "demo",
+			"namespace": "workload",
+		},
+		"spec": map[string]any{
+			"restic": map[string]any{
+				"repository": "repo-secret",
+			},
+		},
+		"status": map[string]any{
+			"lastSyncTime": "2026-02-24T12:00:00Z",
+			"lastSnapshot": "snap-1",
+		},
+	}}
+
+	sec := &corev1.Secret{}
+	sec.Namespace = "workload"
+	sec.Name = "repo-secret"
+	sec.Data = map[string][]byte{
+		"RESTIC_REPOSITORY": []byte("s3://bucket/repo"),
+		"RESTIC_PASSWORD":   []byte("pass"),
+	}
+
+	c := fake.NewClientBuilder().WithScheme(scheme).
+		WithStatusSubresource(&v1alpha1.BackrestVolSyncBinding{}).
+		WithObjects(b, vs, sec).
+		Build()
+
+	primary := &fakeBackrestRepoClient{}
+	offsite := &fakeBackrestRepoClient{addRepoErr: errors.New("dial tcp: connection refused")}
+	r := &BackrestVolSyncBindingReconciler{
+		Client: c,
+		Scheme: scheme,
+		BackrestClientFactory: func(baseURL string, _ backrest.Auth, _ backrest.Options) backrestRepoClient {
+			if baseURL == "http://offsite.invalid" {
+				return offsite
+			}
+			return primary
+		},
+	}
+
+	req := ctrl.Request{NamespacedName: types.NamespacedName{Namespace: b.Namespace, Name: b.Name}}
+	if _, err := r.Reconcile(ctx, req); err == nil {
+		t.Fatalf("expected error while offsite target fails")
+	}
+	if primary.addRepoCalls != 1 || len(primary.taskCalls) != 2 {
+		t.Fatalf("expected primary applied and tasks triggered, got addRepo=%d tasks=%d", primary.addRepoCalls, len(primary.taskCalls))
+	}
+
+	var got v1alpha1.BackrestVolSyncBinding
+	if err := c.Get(ctx, req.NamespacedName, &got); err != nil {
+		t.Fatalf("get: %v", err)
+	}
+	if len(got.Status.Targets) != 2 || got.Status.Targets[0].Name != defaultTargetName || got.Status.Targets[1].Name != "offsite" {
+		t.Fatalf("unexpected target statuses: %#v", got.Status.Targets)
+	}
+	if !isTargetReady(&got.Status.Targets[0]) || got.Status.Targets[0].LastSnapshotMarker == "" {
+		t.Fatalf("expected primary target ready with snapshot marker: %#v", got.Status.Targets[0])
+	}
+	if isTargetReady(&got.Status.Targets[1]) || got.Status.Targets[1].LastErrorHash == "" {
+		t.Fatalf("expected offsite target failing: %#v", got.Status.Targets[1])
+	}
+	if reason := getReadyReason(&got); reason != "TargetsFailing" {
+		t.Fatalf("expected TargetsFailing, got %q", reason)
+	}
+	if got.Status.LastSnapshotMarker != got.Status.Targets[0].LastSnapshotMarker {
+		t.Fatalf("expected top-level status to mirror the primary target")
+	}
+
+	offsite.addRepoErr = nil
+	if _, err := r.Reconcile(ctx, req); err != nil {
+		t.Fatalf("reconcile #2: %v", err)
+	}
+	if primary.addRepoCalls != 1 || len(primary.taskCalls) != 2 {
+		t.Fatalf("expected primary untouched, got addRepo=%d tasks=%d", primary.addRepoCalls, len(primary.taskCalls))
+	}
+	if offsite.addRepoCalls != 2 || len(offsite.taskCalls) != 2 {
+		t.Fatalf("expected offsite applied and tasks triggered, got addRepo=%d tasks=%d", offsite.addRepoCalls, len(offsite.taskCalls))
+	}
+	if err := c.Get(ctx, req.NamespacedName, &got); err != nil {
+		t.Fatalf("get: %v", err)
+	}
+	if reason := getReadyReason(&got); reason != "Applied" {
+		t.Fatalf("expected Applied, got %q", reason)
+	}
+
+	// A removed target is dropped from status; its repo is reported as left behind.
+	recorder := events.NewFakeRecorder(10)
+	r.Recorder = recorder
+	got.Spec.Targets = nil
+	if err := c.Update(ctx, &got); err != nil {
+		t.Fatalf("update binding: %v", err)
+	}
+	if _, err := r.Reconcile(ctx, req); err != nil {
+		t.Fatalf("reconcile #3: %v", err)
+	}
+	if err := c.Get(ctx, req.NamespacedName, &got); err != nil {
+		t.Fatalf("get: %v", err)
+	}
+	if len(got.Status.Targets) != 1 || got.Status.Targets[0].Name != defaultTargetName {
+		t.Fatalf("expected only the default target left, got %#v", got.Status.Targets)
+	}
+	if e := <-recorder.Events; !strings.HasPrefix(e, "Normal TargetRemoved Backrest target offsite was removed") {
+		t.Fatalf("expected TargetRemoved event, got %q", e)
+	}
+}
+
+func TestValidateTargets_Names(t *testing.T) {
+	offsite := v1alpha1.BackrestConnection{URL: "http://offsite.invalid"}
+	tests := []struct {
+		name    string
+		primary string
+		targets []v1alpha1.BackrestTarget
+		wantErr bool
+	}{
+		{name: "primary and target", primary: "http://primary.invalid", targets: []v1alpha1.BackrestTarget{{Name: "offsite", BackrestConnection: offsite}}},
+		{name: "targets only", targets: []v1alpha1.BackrestTarget{{Name: "offsite", BackrestConnection: offsite}}},
+		{name: "duplicate", targets: []v1alpha1.BackrestTarget{{Name: "offsite", BackrestConnection: offsite}, {Name: "offsite", BackrestConnection: offsite}}, wantErr: true},
+		{name: "default with primary", primary: "http://primary.invalid", targets: []v1alpha1.BackrestTarget{{Name: defaultTargetName, BackrestConnection: offsite}}, wantErr: true},
+		{name: "default without primary", targets: []v1alpha1.BackrestTarget{{Name: defaultTargetName, BackrestConnection: offsite}}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := &v1alpha1.BackrestVolSyncBinding{}
+			b.Spec.Backrest.URL = tt.primary
+			b.Spec.Targets = tt.targets
+			if errs := validateTargets(b); (len(errs) > 0) != tt.wantErr {
+				t.Fatalf("errs=%v, wantErr=%v", errs, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestSyncTargetStatuses_SeedsDefaultFromLegacyStatus(t *testing.T) {
+	b := &v1alpha1.BackrestVolSyncBinding{}
+	b.Spec.Backrest.URL = "http://primary.invalid"
+	b.Status.LastAppliedInputHash = "abc"
+	b.Status.LastSnapshotMarker = "lastSnapshot=snap-1"
+	b.Status.Conditions = []metav1.Condition{{Type: conditionReady, Status: metav1.ConditionTrue, Reason: "Applied"}}
+
+	if !syncTargetStatuses(b, effectiveTargets(b)) {
+		t.Fatalf("expected change")
+	}
+	ts := b.Status.Targets[0]
+	if ts.Name != defaultTargetName || ts.LastAppliedInputHash != "abc" || ts.LastSnapshotMarker != "lastSnapshot=snap-1" || !isTargetReady(&ts) {
+		t.Fatalf("expected legacy status carried over, got %#v", ts)
+	}
+	if syncTargetStatuses(b, effectiveTargets(b)) {
+		t.Fatalf("expected no change on second sync")
+	}
+}
+
+func TestBackrestVolSyncBindingReconcile_IgnoresSecretMetadataChanges(t *testing.T) {
+	ctx := context.Background()
+	scheme := bindingTestScheme(t)
+
+	b := &v1alpha1.BackrestVolSyncBinding{}
+	b.Namespace = "workload"
+	b.Name = "b"
+	b.UID = "binding-uid"
+	b.Spec.Backrest.URL = "http://backrest.invalid"
+	b.Spec.Source = v1alpha1.VolSyncSourceRef{Kind: "ReplicationSource", Name: "demo"}
+
+	vs := &unstructured.Unstructured{Object: map[string]any{
+		"apiVersion": volsync.Group + "/" + volsync.Version,
+		"kind":       "ReplicationSource",
+		"metadata":   map[string]any{"name": "demo", "namespace": "workload"},
+		"spec":       map[string]any{"restic": map[string]any{"repository": "repo-secret"}},
+	}}
+
+	sec := &corev1.Secret{}
+	sec.Namespace = "workload"
+	sec.Name = "repo-secret"
+	sec.Data = map[string][]byte{
+		"RESTIC_REPOSITORY": []byte("s3://bucket/repo"),
+		"RESTIC_PASSWORD":   []byte("pass"),
+	}
+
+	c := fake.NewClientBuilder().WithScheme(scheme).
+		WithStatusSubresource(&v1alpha1.BackrestVolSyncBinding{}).
+		WithObjects(b, vs, sec).
+		Build()
+
+	br := &fakeBackrestRepoClient{}
+	r := &BackrestVolSyncBindingReconciler{
+		Client: c,
+		Scheme: scheme,
+		BackrestClientFactory: func(string, backrest.Auth, backrest.Options) backrestRepoClient {
+			return br
+		},
+	}
+
+	req := ctrl.Request{NamespacedName: types.NamespacedName{Namespace: b.Namespace, Name: b.Name}}
+	if _, err := r.Reconcile(ctx, req); err != nil {
+		t.Fatalf("reconcile: %v", err)
+	}
+	if br.addRepoCalls != 1 {
+		t.Fatalf("expected addRepoCalls=1, got %d", br.addRepoCalls)
+	}
+
+	updateSecret := func(mutate func(*corev1.Secret)) {
+		t.Helper()
+		var current corev1.Secret
+		if err := c.Get(ctx, types.NamespacedName{Namespace: sec.Namespace, Name: sec.Name}, &current); err != nil {
+			t.Fatalf("get secret: %v", err)
+		}
+		mutate(&current)
+		if err := c.Update(ctx, &current); err != nil {
+			t.Fatalf("update secret: %v", err)
+		}
+	}
+
+	// A label change bumps resourceVersion but leaves the repo content unchanged.
+	updateSecret(func(s *corev1.Secret) { s.Labels = map[string]string{"refreshed": "true"} })
+	if _, err := r.Reconcile(ctx, req); err != nil {
+		t.Fatalf("reconcile #2: %v", err)
+	}
+	if br.addRepoCalls != 1 {
+		t.Fatalf("expected no re-apply for metadata-only change, got %d calls", br.addRepoCalls)
+	}
+
+	updateSecret(func(s *corev1.Secret) { s.Data["RESTIC_PASSWORD"] = []byte("rotated") })
+	if _, err := r.Reconcile(ctx, req); err != nil {
+		t.Fatalf("reconcile #3: %v", err)
+	}
+	if br.addRepoCalls != 2 {
+		t.Fatalf("expected re-apply after password change, got %d calls", br.addRepoCalls)
+	}
+}
+
+func TestRepoContentDigest_KeyedPerBinding(t *testing.T) {
+	repo := &v1.Repo{Uri: "s3://bucket/repo", Password: "pass", Env: []string{"A=1"}}
+	a := &v1alpha1.BackrestVolSyncBinding{}
+	a.UID = "uid-a"
+	other := &v1alpha1.BackrestVolSyncBinding{}
+	other.UID = "uid-b"
+
+	if repoContentDigest(a, repo, nil) == repoContentDigest(other, repo, nil) {
+		t.Fatalf("expected digest to differ between bindings")
+	}
+	if repoContentDigest(a, repo, nil) == repoContentDigest(a, repo, []byte("operator-key")) {
+		t.Fatalf("expected operator key to change the digest")
+	}
+	reordered := &v1.Repo{Uri: repo.Uri, Password: repo.Password, Env: []string{"A=1"}}
+	if repoContentDigest(a, repo, nil) != repoContentDigest(a, reordered, nil) {
+		t.Fatalf("expected identical content to produce identical digest")
+	}
+}
+
 var _ client.Object = (*v1alpha1.BackrestVolSyncBinding)(nil)
 var _ metav1.Object = (*v1alpha1.BackrestVolSyncBinding)(nil)
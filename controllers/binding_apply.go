@@ -0,0 +1,312 @@
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/jogotcha/backrest-volsync-operator/api/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/util/csaupgrade"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// fieldManagerAutoBinding is the server-side apply field manager of managed bindings. It owns
+// exactly the fields the operator sets; other fields can be owned by users and other tools.
+const fieldManagerAutoBinding = "backrest-volsync-autobinding"
+
+// fieldManagerDetached takes over the fields of a binding detached under orphanPolicy Detach, so the
+// apply field manager does not keep claiming fields of a binding the operator no longer manages.
+const fieldManagerDetached = "backrest-volsync-detached"
+
+// legacyFieldManager is the field manager of bindings written before server-side apply. Clients
+// without a field owner are recorded under the program name of their user agent, which is "manager"
+// for the operator image.
+const legacyFieldManager = "manager"
+
+// bindingApplyConfiguration renders the apply configuration of a managed binding: only metadata the
+// operator sets and the spec, never status.
+func bindingApplyConfiguration(desired *v1alpha1.BackrestVolSyncBinding) (*unstructured.Unstructured, error) {
+	content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(desired)
+	if err != nil {
+		return nil, err
+	}
+	u := &unstructured.Unstructured{Object: map[string]any{"spec": content["spec"]}}
+	u.SetGroupVersionKind(v1alpha1.GroupVersion.WithKind("BackrestVolSyncBinding"))
+	u.SetNamespace(desired.Namespace)
+	u.SetName(desired.Name)
+	u.SetLabels(desired.Labels)
+	u.SetAnnotations(desired.Annotations)
+	u.SetOwnerReferences(desired.OwnerReferences)
+	return u, nil
+}
+
+// applyBinding server-side applies a managed binding and reports whether it changed the existing
+// binding; existing is nil when the binding is created. With dryRun the API server computes the
+// result without persisting it, and conflicts are reported as changes without events.
+// A conflict with another field manager over a field the operator sets is reported as an
+// ApplyConflict event on the existing binding; the apply is then forced, so the operator's value wins
+// for that field, like the merge patch it replaces.
+func (r *VolSyncAutoBindingReconciler) applyBinding(ctx context.Context, desired, existing *v1alpha1.BackrestVolSyncBinding, dryRun bool) (bool, error) {
+	u, err := bindingApplyConfiguration(desired)
+	if err != nil {
+		return false, err
+	}
+	if existing != nil {
+		if !dryRun {
+			if err := r.upgradeLegacyManagedFields(ctx, existing); err != nil {
+				return false, err
+			}
+		}
+		if needsApply, err := bindingNeedsApply(existing, u); err != nil || !needsApply {
+			return false, err
+		}
+	}
+	opts := []client.ApplyOption{client.FieldOwner(fieldManagerAutoBinding)}
+	if dryRun {
+		opts = append(opts, client.DryRunAll)
+	}
+	err = r.Apply(ctx, client.ApplyConfigurationFromUnstructured(u), opts...)
+	if err == nil {
+		return bindingChanged(existing, u)
+	}
+	fields := applyConflictFields(err)
+	if len(fields) == 0 {
+		return false, err
+	}
+	if dryRun {
+		return true, nil
+	}
+	log.FromContext(ctx).Info("Server-side apply conflict on managed binding; forcing ownership", "binding", desired.Name, "fields", fields)
+	if existing != nil && r.Recorder != nil {
+		r.Recorder.Eventf(existing, nil, corev1.EventTypeWarning, "ApplyConflict", "ApplyBinding", "Fields set by the operator were changed by another manager and are reverted: %s", strings.Join(fields, ", "))
+	}
+	u, err = bindingApplyConfiguration(desired)
+	if err != nil {
+		return false, err
+	}
+	if err := r.Apply(ctx, client.ApplyConfigurationFromUnstructured(u), client.FieldOwner(fieldManagerAutoBinding), client.ForceOwnership); err != nil {
+		return false, err
+	}
+	return bindingChanged(existing, u)
+}
+
+// upgradeLegacyManagedFields hands the fields the operator wrote with Create and merge patches,
+// before it used server-side apply, over to fieldManagerAutoBinding, like kubectl does when
+// switching to server-side apply. Otherwise the old manager keeps owning fields the operator no
+// longer sets and the apply does not remove them. Finalizers stay with the old manager: the binding
+// controller still writes them with Update under the same name.
+func (r *VolSyncAutoBindingReconciler) upgradeLegacyManagedFields(ctx context.Context, existing *v1alpha1.BackrestVolSyncBinding) error {
+	var entries, finalizers []metav1.ManagedFieldsEntry
+	legacy := false
+	for _, entry := range existing.DeepCopy().ManagedFields {
+		if entry.Manager != legacyFieldManager || entry.Operation != metav1.ManagedFieldsOperationUpdate || entry.Subresource != "" || entry.FieldsV1 == nil {
+			entries = append(entries, entry)
+			continue
+		}
+		fields, finalizerFields, err := splitFinalizerFields(entry.FieldsV1.Raw)
+		if err != nil {
+			return err
+		}
+		if finalizerFields != nil {
+			f := entry
+			f.FieldsV1 = &metav1.FieldsV1{Raw: finalizerFields}
+			finalizers = append(finalizers, f)
+		}
+		if fields != nil {
+			legacy = true
+			entry.FieldsV1 = &metav1.FieldsV1{Raw: fields}
+			entries = append(entries, entry)
+		}
+	}
+	if !legacy {
+		return nil
+	}
+	upgraded := existing.DeepCopy()
+	upgraded.ManagedFields = entries
+	if err := csaupgrade.UpgradeManagedFields(upgraded, sets.New(legacyFieldManager), fieldManagerAutoBinding); err != nil {
+		return err
+	}
+	patch, err := json.Marshal([]map[string]any{
+		{"op": "replace", "path": "/metadata/managedFields", "value": append(upgraded.ManagedFields, finalizers...)},
+		// Fails with a conflict when the binding changed since it was read.
+		{"op": "replace", "path": "/metadata/resourceVersion", "value": existing.ResourceVersion},
+	})
+	if err != nil {
+		return err
+	}
+	log.FromContext(ctx).Info("Moving fields of a managed binding to the server-side apply field manager", "binding", existing.Name, "from", legacyFieldManager)
+	return r.Patch(ctx, existing, client.RawPatch(types.JSONPatchType, patch))
+}
+
+// splitFinalizerFields splits a FieldsV1 document into the metadata.finalizers field and the rest;
+// either is nil when empty.
+func splitFinalizerFields(raw []byte) ([]byte, []byte, error) {
+	var fields map[string]any
+	if err := json.Unmarshal(raw, &fields); err != nil {
+		return nil, nil, err
+	}
+	var finalizers []byte
+	if metadata, ok := fields["f:metadata"].(map[string]any); ok {
+		if f, ok := metadata["f:finalizers"]; ok {
+			var err error
+			if finalizers, err = json.Marshal(map[string]any{"f:metadata": map[string]any{"f:finalizers": f}}); err != nil {
+				return nil, nil, err
+			}
+			delete(metadata, "f:finalizers")
+			if len(metadata) == 0 {
+				delete(fields, "f:metadata")
+			}
+		}
+	}
+	if len(fields) == 0 {
+		return nil, finalizers, nil
+	}
+	rest, err := json.Marshal(fields)
+	return rest, finalizers, err
+}
+
+// releaseManagedFields hands the fields owned by fieldManagerAutoBinding to fieldManagerDetached as
+// an Update manager; the fields and their values stay. It takes effect with the next write of the
+// binding.
+func releaseManagedFields(b *v1alpha1.BackrestVolSyncBinding) {
+	for i := range b.ManagedFields {
+		entry := &b.ManagedFields[i]
+		if entry.Manager == fieldManagerAutoBinding && entry.Operation == metav1.ManagedFieldsOperationApply {
+			entry.Manager = fieldManagerDetached
+			entry.Operation = metav1.ManagedFieldsOperationUpdate
+		}
+	}
+}
+
+// bindingNeedsApply reports whether applying the configuration would change the existing binding: a
+// field the operator sets has another value, a field it owns is no longer set, or it does not own
+// the binding yet. Skipping the apply otherwise keeps reconciles of unchanged objects free of writes.
+func bindingNeedsApply(existing *v1alpha1.BackrestVolSyncBinding, applied *unstructured.Unstructured) (bool, error) {
+	var owned map[string]any
+	for _, entry := range existing.ManagedFields {
+		if entry.Manager == fieldManagerAutoBinding && entry.Operation == metav1.ManagedFieldsOperationApply && entry.Subresource == "" && entry.FieldsV1 != nil {
+			if err := json.Unmarshal(entry.FieldsV1.Raw, &owned); err != nil {
+				return true, nil
+			}
+		}
+	}
+	if owned == nil {
+		return true, nil
+	}
+	current, err := runtime.DefaultUnstructuredConverter.ToUnstructured(existing)
+	if err != nil {
+		return false, err
+	}
+	for _, key := range []string{"metadata", "spec"} {
+		want, _ := applied.Object[key].(map[string]any)
+		have, _ := current[key].(map[string]any)
+		fields, _ := owned["f:"+key].(map[string]any)
+		if !containsFields(have, want) || ownsUnsetField(fields, want) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// containsFields reports whether every field of want is set to the same value in have. Lists are
+// compared as a whole.
+func containsFields(have, want map[string]any) bool {
+	for k, w := range want {
+		h, ok := have[k]
+		if !ok {
+			return false
+		}
+		wm, wIsMap := w.(map[string]any)
+		hm, hIsMap := h.(map[string]any)
+		if wIsMap && hIsMap {
+			if !containsFields(hm, wm) {
+				return false
+			}
+			continue
+		}
+		if !reflect.DeepEqual(h, w) {
+			return false
+		}
+	}
+	return true
+}
+
+// ownsUnsetField reports whether the managed fields (FieldsV1) name a field that want no longer sets.
+// List items are not descended into; containsFields compares lists as a whole.
+func ownsUnsetField(fields, want map[string]any) bool {
+	for k, v := range fields {
+		name, ok := strings.CutPrefix(k, "f:")
+		if !ok {
+			continue
+		}
+		w, ok := want[name]
+		if !ok {
+			return true
+		}
+		wm, wIsMap := w.(map[string]any)
+		child, _ := v.(map[string]any)
+		if wIsMap && ownsUnsetField(child, wm) {
+			return true
+		}
+	}
+	return false
+}
+
+// bindingChanged compares the applied binding with the binding before the apply. A new config hash
+// alone does not count as a change.
+func bindingChanged(existing *v1alpha1.BackrestVolSyncBinding, applied *unstructured.Unstructured) (bool, error) {
+	if existing == nil {
+		return true, nil
+	}
+	var got v1alpha1.BackrestVolSyncBinding
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(applied.Object, &got); err != nil {
+		return false, err
+	}
+	withoutHash := func(annotations map[string]string) map[string]string {
+		out := make(map[string]string, len(annotations))
+		for k, v := range annotations {
+			if k != annotationConfigHash {
+				out[k] = v
+			}
+		}
+		return out
+	}
+	return !reflect.DeepEqual(got.Spec, existing.Spec) ||
+		!reflect.DeepEqual(got.Labels, existing.Labels) ||
+		!reflect.DeepEqual(withoutHash(got.Annotations), withoutHash(existing.Annotations)) ||
+		!reflect.DeepEqual(got.OwnerReferences, existing.OwnerReferences), nil
+}
+
+// applyConflictFields lists the conflicting fields and their managers of a server-side apply
+// conflict, or nil when err is not one.
+func applyConflictFields(err error) []string {
+	var status apierrors.APIStatus
+	if !apierrors.IsConflict(err) || !errors.As(err, &status) {
+		return nil
+	}
+	details := status.Status().Details
+	if details == nil {
+		return nil
+	}
+	var fields []string
+	for _, cause := range details.Causes {
+		if cause.Type != metav1.CauseTypeFieldManagerConflict {
+			continue
+		}
+		fields = append(fields, fmt.Sprintf("%s (%s)", cause.Field, cause.Message))
+	}
+	sort.Strings(fields)
+	return fields
+}
@@ -0,0 +1,102 @@
+package controllers
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	"github.com/jogotcha/backrest-volsync-operator/api/v1alpha1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// alternateBindingName is the binding name used when desiredBindingName is taken by a binding of
+// another object. The suffix hashes the object's identity, including its UID, so it is stable for the
+// object and differs between objects whose names map to the same desiredBindingName.
+func alternateBindingName(kind, volsyncName string, uid types.UID) string {
+	sum := sha256.Sum256([]byte(kind + "/" + volsyncName + "/" + string(uid)))
+	suffix := hex.EncodeToString(sum[:])[:8]
+	base := desiredBindingName(kind, volsyncName)
+	if len(base) > 63-1-len(suffix) {
+		base = strings.TrimRight(base[:63-1-len(suffix)], "-")
+	}
+	return base + "-" + suffix
+}
+
+// bindingConflict explains why an existing binding does not belong to a VolSync object, or returns
+// "" when it does. A user-managed binding belongs to the object its spec.source names. A managed
+// binding belongs to the object in its volsync-ref annotation, and its controller owner reference,
+// when set, must carry the object's UID.
+func bindingConflict(b *v1alpha1.BackrestVolSyncBinding, vsObj *unstructured.Unstructured, kind string) string {
+	if b.Labels[labelManaged] != "true" {
+		if b.Spec.Source.Kind == kind && b.Spec.Source.Name == vsObj.GetName() {
+			return ""
+		}
+		return fmt.Sprintf("user-managed BackrestVolSyncBinding %s binds %s %s", b.Name, b.Spec.Source.Kind, b.Spec.Source.Name)
+	}
+	if ref := b.Annotations[annotationVolSyncRef]; ref != strings.ToLower(kind)+"/"+vsObj.GetName() {
+		return fmt.Sprintf("BackrestVolSyncBinding %s is managed for %s", b.Name, ref)
+	}
+	if owner := metav1.GetControllerOf(b); owner != nil && owner.UID != vsObj.GetUID() {
+		return fmt.Sprintf("BackrestVolSyncBinding %s is owned by an earlier %s %s (uid %s)", b.Name, kind, vsObj.GetName(), owner.UID)
+	}
+	return ""
+}
+
+// ownedByEarlierObject reports whether a managed binding of the object's name is owned by an earlier
+// object of the same kind and name. Names are unique, so that object was deleted (and the object
+// recreated, for example by GitOps); the binding waits for garbage collection.
+func ownedByEarlierObject(b *v1alpha1.BackrestVolSyncBinding, vsObj *unstructured.Unstructured, kind string) bool {
+	if b.Labels[labelManaged] != "true" || b.Annotations[annotationVolSyncRef] != strings.ToLower(kind)+"/"+vsObj.GetName() {
+		return false
+	}
+	owner := metav1.GetControllerOf(b)
+	return owner != nil && owner.Kind == kind && owner.Name == vsObj.GetName() && owner.UID != vsObj.GetUID()
+}
+
+// resolveBindingName picks the binding name of a VolSync object. A binding the object already has
+// under desiredBindingName or alternateBindingName keeps its name; otherwise desiredBindingName is used
+// unless another binding occupies it. A binding left by an earlier, deleted object of the same name
+// does not occupy it: the object adopts that binding instead of taking the alternate name for good.
+// conflict explains why desiredBindingName is not used; name is "" when both names are taken by
+// other bindings.
+func (r *VolSyncAutoBindingReconciler) resolveBindingName(ctx context.Context, vsObj *unstructured.Unstructured, kind string) (name, conflict string, err error) {
+	primary := desiredBindingName(kind, vsObj.GetName())
+	alternate := alternateBindingName(kind, vsObj.GetName(), vsObj.GetUID())
+
+	var existing v1alpha1.BackrestVolSyncBinding
+	err = r.Get(ctx, types.NamespacedName{Namespace: vsObj.GetNamespace(), Name: primary}, &existing)
+	if err != nil && !apierrors.IsNotFound(err) {
+		return "", "", err
+	}
+	stale := false
+	if err == nil {
+		if conflict = bindingConflict(&existing, vsObj, kind); conflict == "" {
+			return primary, "", nil
+		}
+		stale = ownedByEarlierObject(&existing, vsObj, kind)
+	}
+
+	var alt v1alpha1.BackrestVolSyncBinding
+	err = r.Get(ctx, types.NamespacedName{Namespace: vsObj.GetNamespace(), Name: alternate}, &alt)
+	switch {
+	case apierrors.IsNotFound(err):
+		if conflict == "" || stale {
+			return primary, "", nil
+		}
+		return alternate, conflict, nil
+	case err != nil:
+		return "", "", err
+	}
+	if altConflict := bindingConflict(&alt, vsObj, kind); altConflict != "" {
+		if conflict == "" || stale {
+			return primary, "", nil
+		}
+		return "", conflict + "; " + altConflict, nil
+	}
+	return alternate, conflict, nil
+}
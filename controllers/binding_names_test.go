@@ -0,0 +1,169 @@
+package controllers
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/jogotcha/backrest-volsync-operator/api/v1alpha1"
+	"github.com/jogotcha/backrest-volsync-operator/pkg/volsync"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/events"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestAlternateBindingName(t *testing.T) {
+	long := strings.Repeat("b", 100)
+	for _, name := range []string{"demo", long} {
+		alt := alternateBindingName("ReplicationSource", name, "1111")
+		if len(alt) > 63 || alt == desiredBindingName("ReplicationSource", name) {
+			t.Fatalf("unexpected alternate name %q", alt)
+		}
+		if alt != alternateBindingName("ReplicationSource", name, "1111") {
+			t.Fatalf("expected a deterministic alternate name")
+		}
+		if alt == alternateBindingName("ReplicationSource", name, "2222") {
+			t.Fatalf("expected the UID to change the alternate name")
+		}
+	}
+}
+
+func TestVolSyncAutoBindingReconcile_NameCollision(t *testing.T) {
+	ctx := context.Background()
+	scheme := testScheme(t)
+
+	cfg := &v1alpha1.BackrestVolSyncOperatorConfig{}
+	cfg.Namespace = "backrest-volsync-operator"
+	cfg.Name = "backrest-volsync-operator"
+	cfg.Spec.BindingGeneration.Policy = string(BindingPolicyAll)
+	cfg.Spec.BindingGeneration.OrphanPolicy = string(OrphanPolicyDelete)
+	cfg.Spec.DefaultBackrest.URL = "http://example.invalid"
+
+	// my-db and my.db both map to bvsb-rs-my-db.
+	newRS := func(name, uid string) *unstructured.Unstructured {
+		vs := &unstructured.Unstructured{}
+		vs.SetGroupVersionKind(schema.GroupVersionKind{Group: volsync.Group, Version: volsync.Version, Kind: "ReplicationSource"})
+		vs.SetNamespace("workload")
+		vs.SetName(name)
+		vs.SetUID(types.UID(uid))
+		return vs
+	}
+	first := newRS("my-db", "1111")
+	second := newRS("my.db", "2222")
+	// A user-managed binding for another object occupies the name of "app".
+	app := newRS("app", "3333")
+	handMade := &v1alpha1.BackrestVolSyncBinding{}
+	handMade.Namespace = "workload"
+	handMade.Name = "bvsb-rs-app"
+	handMade.Spec.Source = v1alpha1.VolSyncSourceRef{Kind: "ReplicationSource", Name: "other"}
+
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(cfg, first, second, app, handMade).Build()
+	recorder := events.NewFakeRecorder(10)
+	r := &VolSyncAutoBindingReconciler{
+		Client:         c,
+		Scheme:         scheme,
+		Recorder:       recorder,
+		OperatorConfig: types.NamespacedName{Namespace: cfg.Namespace, Name: cfg.Name},
+	}
+	reconcile := func(name string) {
+		t.Helper()
+		if _, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: types.NamespacedName{Namespace: "workload", Name: name}}); err != nil {
+			t.Fatalf("reconcile %s: %v", name, err)
+		}
+	}
+	getBinding := func(name string) *v1alpha1.BackrestVolSyncBinding {
+		t.Helper()
+		var b v1alpha1.BackrestVolSyncBinding
+		if err := c.Get(ctx, types.NamespacedName{Namespace: "workload", Name: name}, &b); err != nil {
+			t.Fatalf("get binding %s: %v", name, err)
+		}
+		return &b
+	}
+	expectConflict := func(binding string) {
+		t.Helper()
+		e := <-recorder.Events
+		if !strings.HasPrefix(e, "Warning BindingNameConflict ") || !strings.Contains(e, "using BackrestVolSyncBinding "+binding+" instead") {
+			t.Fatalf("expected BindingNameConflict event, got %q", e)
+		}
+		if e := <-recorder.Events; !strings.HasPrefix(e, "Normal BindingCreated ") {
+			t.Fatalf("expected BindingCreated event, got %q", e)
+		}
+	}
+
+	reconcile("my-db")
+	<-recorder.Events // BindingCreated
+	reconcile("my.db")
+	alt := alternateBindingName("ReplicationSource", "my.db", "2222")
+	expectConflict(alt)
+	if src := getBinding("bvsb-rs-my-db").Spec.Source.Name; src != "my-db" {
+		t.Fatalf("expected the first binding left bound to my-db, got %q", src)
+	}
+	if src := getBinding(alt).Spec.Source.Name; src != "my.db" {
+		t.Fatalf("expected the alternate binding bound to my.db, got %q", src)
+	}
+
+	// Both objects keep their names without further events.
+	reconcile("my.db")
+	reconcile("my-db")
+	if len(recorder.Events) != 0 {
+		t.Fatalf("unexpected event %q", <-recorder.Events)
+	}
+
+	reconcile("app")
+	expectConflict(alternateBindingName("ReplicationSource", "app", "3333"))
+	if b := getBinding("bvsb-rs-app"); b.Labels[labelManaged] == "true" || b.Spec.Source.Name != "other" {
+		t.Fatalf("expected the user-managed binding untouched, got %#v", b)
+	}
+
+	// A recreated object adopts the binding its deleted predecessor left for garbage collection,
+	// instead of taking the alternate name for good.
+	if err := c.Delete(ctx, first); err != nil {
+		t.Fatalf("delete volsync object: %v", err)
+	}
+	recreated := newRS("my-db", "4444")
+	if err := c.Create(ctx, recreated); err != nil {
+		t.Fatalf("recreate volsync object: %v", err)
+	}
+	stale := getBinding("bvsb-rs-my-db")
+	if conflict := bindingConflict(stale, recreated, "ReplicationSource"); !strings.Contains(conflict, "uid 1111") {
+		t.Fatalf("expected an owner UID conflict, got %q", conflict)
+	}
+	reconcile("my-db")
+	if e := <-recorder.Events; !strings.HasPrefix(e, "Normal BindingUpdated ") {
+		t.Fatalf("expected BindingUpdated event, got %q", e)
+	}
+	if refs := getBinding("bvsb-rs-my-db").OwnerReferences; len(refs) != 1 || refs[0].UID != "4444" {
+		t.Fatalf("expected the binding adopted by the recreated object, got %v", refs)
+	}
+	if err := c.Get(ctx, client.ObjectKey{Namespace: "workload", Name: alternateBindingName("ReplicationSource", "my-db", "4444")}, &v1alpha1.BackrestVolSyncBinding{}); err == nil {
+		t.Fatalf("expected no alternate binding for the recreated object")
+	}
+
+	// Orphan handling only touches the object's own binding.
+	second.SetAnnotations(map[string]string{annotationAutoBinding: "false"})
+	if err := c.Update(ctx, second); err != nil {
+		t.Fatalf("update volsync object: %v", err)
+	}
+	reconcile("my.db")
+	if err := c.Get(ctx, client.ObjectKey{Namespace: "workload", Name: alt}, &v1alpha1.BackrestVolSyncBinding{}); err == nil {
+		t.Fatalf("expected the alternate binding deleted")
+	}
+
+	// A binding that is being deleted is waited for, not applied to.
+	terminating := getBinding("bvsb-rs-my-db")
+	terminating.Finalizers = []string{finalizerRepoCleanup}
+	if err := c.Update(ctx, terminating); err != nil {
+		t.Fatalf("update binding: %v", err)
+	}
+	if err := c.Delete(ctx, terminating); err != nil {
+		t.Fatalf("delete binding: %v", err)
+	}
+	res, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: types.NamespacedName{Namespace: "workload", Name: "my-db"}})
+	if err != nil || res.RequeueAfter != bindingDeletionRetryInterval {
+		t.Fatalf("expected a requeue while the binding is deleted, got %v, %v", res, err)
+	}
+}
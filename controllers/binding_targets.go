@@ -0,0 +1,210 @@
+package controllers
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/jogotcha/backrest-volsync-operator/api/v1alpha1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+// defaultTargetName is the status.targets name used for spec.backrest.
+const defaultTargetName = "default"
+
+type bindingTarget struct {
+	Name       string
+	Connection v1alpha1.BackrestConnection
+}
+
+type targetFailure struct {
+	target    string
+	reason    string
+	errorHash string
+}
+
+func hasBackrestConnection(conn v1alpha1.BackrestConnection) bool {
+	return conn.URL != "" || conn.AuthRef != nil || conn.InstanceRef != nil
+}
+
+// effectiveTargets lists the Backrest targets of a binding: spec.backrest first (when set), then spec.targets.
+func effectiveTargets(b *v1alpha1.BackrestVolSyncBinding) []bindingTarget {
+	targets := make([]bindingTarget, 0, 1+len(b.Spec.Targets))
+	if hasBackrestConnection(b.Spec.Backrest) || len(b.Spec.Targets) == 0 {
+		targets = append(targets, bindingTarget{Name: defaultTargetName, Connection: b.Spec.Backrest})
+	}
+	for _, t := range b.Spec.Targets {
+		targets = append(targets, bindingTarget{Name: t.Name, Connection: t.BackrestConnection})
+	}
+	return targets
+}
+
+// bindingInstanceRefs returns the distinct BackrestInstance names a binding selects.
+func bindingInstanceRefs(b *v1alpha1.BackrestVolSyncBinding) []string {
+	var names []string
+	seen := map[string]struct{}{}
+	for _, t := range effectiveTargets(b) {
+		if t.Connection.InstanceRef == nil || t.Connection.InstanceRef.Name == "" {
+			continue
+		}
+		if _, ok := seen[t.Connection.InstanceRef.Name]; ok {
+			continue
+		}
+		seen[t.Connection.InstanceRef.Name] = struct{}{}
+		names = append(names, t.Connection.InstanceRef.Name)
+	}
+	return names
+}
+
+// syncTargetStatuses aligns status.targets with the effective targets, keeping state for targets
+// that still exist. Bindings created before per-target status existed have their top-level
+// fields carried over to the default target so upgrades do not re-apply or re-trigger tasks.
+func syncTargetStatuses(b *v1alpha1.BackrestVolSyncBinding, targets []bindingTarget) bool {
+	existing := make(map[string]v1alpha1.BackrestTargetStatus, len(b.Status.Targets))
+	for _, ts := range b.Status.Targets {
+		existing[ts.Name] = ts
+	}
+
+	changed := len(b.Status.Targets) != len(targets)
+	out := make([]v1alpha1.BackrestTargetStatus, 0, len(targets))
+	for i, t := range targets {
+		ts, ok := existing[t.Name]
+		if !ok {
+			ts = v1alpha1.BackrestTargetStatus{Name: t.Name}
+			if t.Name == defaultTargetName && len(b.Status.Targets) == 0 {
+				seedTargetFromLegacyStatus(&ts, &b.Status)
+			}
+			changed = true
+		} else if b.Status.Targets[i].Name != t.Name {
+			changed = true
+		}
+		out = append(out, ts)
+	}
+	b.Status.Targets = out
+	return changed
+}
+
+// removedTargets lists the targets in status.targets the binding was registered in that are no longer
+// effective. Their repos are not removed from those Backrest instances: the connection is gone with
+// the spec entry.
+func removedTargets(b *v1alpha1.BackrestVolSyncBinding, targets []bindingTarget) []string {
+	current := make(map[string]struct{}, len(targets))
+	for _, t := range targets {
+		current[t.Name] = struct{}{}
+	}
+	var removed []string
+	for _, ts := range b.Status.Targets {
+		if _, ok := current[ts.Name]; !ok && ts.LastAppliedInputHash != "" {
+			removed = append(removed, ts.Name)
+		}
+	}
+	return removed
+}
+
+func seedTargetFromLegacyStatus(ts *v1alpha1.BackrestTargetStatus, st *v1alpha1.BackrestVolSyncBindingStatus) {
+	ts.LastAppliedInputHash = st.LastAppliedInputHash
+	ts.LastApplyTime = st.LastApplyTime
+	ts.LastIndexedSnapshotMarker = st.LastIndexedSnapshotMarker
+	ts.LastIndexedSnapshotSyncTime = st.LastIndexedSnapshotSyncTime
+	ts.LastSnapshotMarker = st.LastSnapshotMarker
+	ts.LastSnapshotSyncTime = st.LastSnapshotSyncTime
+	ts.LastRepoTaskTriggerTime = st.LastRepoTaskTriggerTime
+	ts.LastRepoTaskErrorHash = st.LastRepoTaskErrorHash
+	if cond := meta.FindStatusCondition(st.Conditions, conditionReady); cond != nil && st.LastAppliedInputHash != "" {
+		ts.Conditions = []metav1.Condition{*cond}
+	}
+}
+
+// mirrorPrimaryTarget copies the first target's state into the top-level status fields.
+func mirrorPrimaryTarget(b *v1alpha1.BackrestVolSyncBinding) bool {
+	if len(b.Status.Targets) == 0 {
+		return false
+	}
+	ts := b.Status.Targets[0]
+	st := &b.Status
+	changed := st.LastAppliedInputHash != ts.LastAppliedInputHash ||
+		!timesEqual(st.LastApplyTime, ts.LastApplyTime) ||
+		st.LastIndexedSnapshotMarker != ts.LastIndexedSnapshotMarker ||
+		st.LastIndexedSnapshotSyncTime != ts.LastIndexedSnapshotSyncTime ||
+		st.LastSnapshotMarker != ts.LastSnapshotMarker ||
+		st.LastSnapshotSyncTime != ts.LastSnapshotSyncTime ||
+		!timesEqual(st.LastRepoTaskTriggerTime, ts.LastRepoTaskTriggerTime) ||
+		st.LastRepoTaskErrorHash != ts.LastRepoTaskErrorHash
+	st.LastAppliedInputHash = ts.LastAppliedInputHash
+	st.LastApplyTime = ts.LastApplyTime
+	st.LastIndexedSnapshotMarker = ts.LastIndexedSnapshotMarker
+	st.LastIndexedSnapshotSyncTime = ts.LastIndexedSnapshotSyncTime
+	st.LastSnapshotMarker = ts.LastSnapshotMarker
+	st.LastSnapshotSyncTime = ts.LastSnapshotSyncTime
+	st.LastRepoTaskTriggerTime = ts.LastRepoTaskTriggerTime
+	st.LastRepoTaskErrorHash = ts.LastRepoTaskErrorHash
+	return changed
+}
+
+func timesEqual(a, b *metav1.Time) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return a.Equal(b)
+}
+
+// setAggregateReady derives the binding Ready condition from the per-target results.
+func setAggregateReady(b *v1alpha1.BackrestVolSyncBinding, targetCount int, failures []targetFailure) bool {
+	cond := metav1.Condition{
+		Type:               conditionReady,
+		ObservedGeneration: b.Generation,
+		LastTransitionTime: metav1.Now(),
+	}
+	changed := false
+	switch {
+	case len(failures) == 0:
+		cond.Status = metav1.ConditionTrue
+		cond.Reason = "Applied"
+		cond.Message = "Repository registered/updated in Backrest"
+		if targetCount > 1 {
+			cond.Message = fmt.Sprintf("Repository registered/updated in %d Backrest targets", targetCount)
+		}
+	case targetCount == 1:
+		cond.Status = metav1.ConditionFalse
+		cond.Reason = failures[0].reason
+		cond.Message = fmt.Sprintf("%s (details omitted; errorHash=%s)", failures[0].reason, failures[0].errorHash)
+	default:
+		parts := make([]string, 0, len(failures))
+		for _, f := range failures {
+			parts = append(parts, fmt.Sprintf("%s (%s)", f.target, f.reason))
+		}
+		cond.Status = metav1.ConditionFalse
+		cond.Reason = "TargetsFailing"
+		cond.Message = fmt.Sprintf("%d of %d Backrest targets failing: %s", len(failures), targetCount, strings.Join(parts, ", "))
+	}
+	if len(failures) > 0 && b.Status.LastErrorHash != failures[0].errorHash {
+		b.Status.LastErrorHash = failures[0].errorHash
+		changed = true
+	}
+	if meta.SetStatusCondition(&b.Status.Conditions, cond) {
+		changed = true
+	}
+	return changed
+}
+
+func validateTargets(b *v1alpha1.BackrestVolSyncBinding) field.ErrorList {
+	var errs field.ErrorList
+	if hasBackrestConnection(b.Spec.Backrest) || len(b.Spec.Targets) == 0 {
+		errs = append(errs, validateBackrestConnection(b.Spec.Backrest, field.NewPath("spec", "backrest"))...)
+	}
+	seen := map[string]struct{}{}
+	for i, t := range b.Spec.Targets {
+		path := field.NewPath("spec", "targets").Index(i)
+		if t.Name == "" {
+			errs = append(errs, field.Required(path.Child("name"), "required"))
+		} else if t.Name == defaultTargetName {
+			errs = append(errs, field.Invalid(path.Child("name"), t.Name, "is reserved for spec.backrest"))
+		} else if _, dup := seen[t.Name]; dup {
+			errs = append(errs, field.Duplicate(path.Child("name"), t.Name))
+		}
+		seen[t.Name] = struct{}{}
+		errs = append(errs, validateBackrestConnection(t.BackrestConnection, path)...)
+	}
+	return errs
+}
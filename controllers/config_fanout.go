@@ -0,0 +1,215 @@
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"strings"
+
+	"github.com/jogotcha/backrest-volsync-operator/api/v1alpha1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/workqueue"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// annotationConfigHash on a managed binding is the autoBindingConfigHash of the effective config it
+// was generated from. OperatorConfig changes skip VolSync objects whose binding is already current.
+const annotationConfigHash = "backrest.garethgeorge.com/config-hash"
+
+// operatorConfigChanged passes events of the OperatorConfig named nn. Updates pass only when the
+// generation changed and relevant returns something different for the old and new spec, so status
+// writes, metadata edits and spec changes another controller cares about do not fan out.
+func operatorConfigChanged(nn types.NamespacedName, relevant func(*v1alpha1.BackrestVolSyncOperatorConfigSpec) any) predicate.Predicate {
+	isConfig := func(obj client.Object) bool {
+		return nn.Name != "" && nn.Namespace != "" && obj.GetNamespace() == nn.Namespace && obj.GetName() == nn.Name
+	}
+	return predicate.Funcs{
+		CreateFunc:  func(e event.CreateEvent) bool { return isConfig(e.Object) },
+		DeleteFunc:  func(e event.DeleteEvent) bool { return isConfig(e.Object) },
+		GenericFunc: func(e event.GenericEvent) bool { return isConfig(e.Object) },
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			if !isConfig(e.ObjectNew) || e.ObjectOld.GetGeneration() == e.ObjectNew.GetGeneration() {
+				return false
+			}
+			oldCfg, okOld := e.ObjectOld.(*v1alpha1.BackrestVolSyncOperatorConfig)
+			newCfg, okNew := e.ObjectNew.(*v1alpha1.BackrestVolSyncOperatorConfig)
+			if !okOld || !okNew {
+				return true
+			}
+			return !reflect.DeepEqual(relevant(&oldCfg.Spec), relevant(&newCfg.Spec))
+		},
+	}
+}
+
+// autoBindingConfigFields are the OperatorConfig fields VolSyncAutoBindingReconciler reads.
+func autoBindingConfigFields(spec *v1alpha1.BackrestVolSyncOperatorConfigSpec) any {
+	return []any{spec.Paused, spec.DefaultBackrest, spec.BindingGeneration, spec.NamespaceOverrides}
+}
+
+// bindingConfigFields are the OperatorConfig fields BackrestVolSyncBindingReconciler reads.
+func bindingConfigFields(spec *v1alpha1.BackrestVolSyncOperatorConfigSpec) any {
+	return []any{spec.Paused, spec.URIRewrites, spec.RepoSharing, spec.CompletionMarker, spec.BindingGeneration.RepoIDTemplate, spec.ClusterName}
+}
+
+// bindingAffectedByConfigChange reports whether a change of the OperatorConfig spec from oldSpec to
+// newSpec can change what BackrestVolSyncBindingReconciler does with b. A nil oldSpec (the config
+// was created or deleted) affects every binding.
+func bindingAffectedByConfigChange(b *v1alpha1.BackrestVolSyncBinding, oldSpec, newSpec *v1alpha1.BackrestVolSyncOperatorConfigSpec) bool {
+	if oldSpec == nil || newSpec == nil || oldSpec.Paused != newSpec.Paused ||
+		!reflect.DeepEqual(oldSpec.URIRewrites, newSpec.URIRewrites) ||
+		!reflect.DeepEqual(oldSpec.RepoSharing, newSpec.RepoSharing) {
+		return true
+	}
+	// Completion markers are only read to trigger Backrest tasks after a sync.
+	if !reflect.DeepEqual(oldSpec.CompletionMarker, newSpec.CompletionMarker) &&
+		(ptr.Deref(b.Spec.Repo.TriggerTasksOnSnapshot, false) || manualSyncAwaitingTasks(b)) {
+		return true
+	}
+	// The repo ID template only names repos of generated bindings that have no repo ID yet, or are
+	// asked to migrate.
+	if (!reflect.DeepEqual(oldSpec.BindingGeneration.RepoIDTemplate, newSpec.BindingGeneration.RepoIDTemplate) || oldSpec.ClusterName != newSpec.ClusterName) &&
+		b.Labels[labelManaged] == "true" && b.Spec.Repo.IDOverride == "" &&
+		(b.Status.RepoID == "" || strings.TrimSpace(b.Annotations[annotationRepoIDMigrate]) == "true") {
+		return true
+	}
+	return false
+}
+
+// bindingsForConfigChange enqueues the bindings an OperatorConfig change affects. Bindings can
+// exist in any namespace (auto-binding is cluster-wide), so all of them are checked.
+func (r *BackrestVolSyncBindingReconciler) bindingsForConfigChange(ctx context.Context, oldCfg, newCfg client.Object, q workqueue.TypedRateLimitingInterface[reconcile.Request]) {
+	var oldSpec, newSpec *v1alpha1.BackrestVolSyncOperatorConfigSpec
+	if cfg, ok := oldCfg.(*v1alpha1.BackrestVolSyncOperatorConfig); ok {
+		oldSpec = &cfg.Spec
+	}
+	if cfg, ok := newCfg.(*v1alpha1.BackrestVolSyncOperatorConfig); ok {
+		newSpec = &cfg.Spec
+	}
+	var list v1alpha1.BackrestVolSyncBindingList
+	if err := r.List(ctx, &list); err != nil {
+		return
+	}
+	for i := range list.Items {
+		if bindingAffectedByConfigChange(&list.Items[i], oldSpec, newSpec) {
+			q.Add(reconcile.Request{NamespacedName: types.NamespacedName{Namespace: list.Items[i].Namespace, Name: list.Items[i].Name}})
+		}
+	}
+}
+
+// configChangeHandler passes the old and new OperatorConfig of an update to bindingsForConfigChange.
+func (r *BackrestVolSyncBindingReconciler) configChangeHandler() handler.EventHandler {
+	return handler.Funcs{
+		CreateFunc: func(ctx context.Context, e event.CreateEvent, q workqueue.TypedRateLimitingInterface[reconcile.Request]) {
+			r.bindingsForConfigChange(ctx, nil, e.Object, q)
+		},
+		UpdateFunc: func(ctx context.Context, e event.UpdateEvent, q workqueue.TypedRateLimitingInterface[reconcile.Request]) {
+			r.bindingsForConfigChange(ctx, e.ObjectOld, e.ObjectNew, q)
+		},
+		DeleteFunc: func(ctx context.Context, e event.DeleteEvent, q workqueue.TypedRateLimitingInterface[reconcile.Request]) {
+			r.bindingsForConfigChange(ctx, nil, e.Object, q)
+		},
+		GenericFunc: func(ctx context.Context, e event.GenericEvent, q workqueue.TypedRateLimitingInterface[reconcile.Request]) {
+			r.bindingsForConfigChange(ctx, nil, e.Object, q)
+		},
+	}
+}
+
+// autoBindingConfigHash hashes the parts of an effective config that decide whether a VolSync object
+// is auto-bound and what its binding looks like. Paused, dryRun and orphanPolicy are left out: they
+// never change a binding that is already current.
+func autoBindingConfigHash(cfg OperatorConfigSnapshot) string {
+	selector := func(s labels.Selector) string {
+		if s == nil {
+			return ""
+		}
+		return s.String()
+	}
+	data, _ := json.Marshal(struct {
+		Policy            BindingGenerationPolicy
+		Kinds             map[string]bool
+		NamespaceSelector string
+		ObjectSelector    string
+		AllowedOverrides  map[string]bool
+		Backrest          v1alpha1.BackrestConnection
+		DefaultRepo       v1alpha1.BackrestRepoSpec
+	}{
+		Policy:            cfg.BindingPolicy,
+		Kinds:             cfg.AllowedVolSyncKinds,
+		NamespaceSelector: selector(cfg.NamespaceSelector),
+		ObjectSelector:    selector(cfg.ObjectSelector),
+		AllowedOverrides:  cfg.AllowedOverrides,
+		Backrest:          cfg.DefaultBackrestConnection(),
+		DefaultRepo:       cfg.DefaultRepo,
+	})
+	return hashString(string(data))[:16]
+}
+
+// volSyncObjectsForConfigChange enqueues the VolSync objects an OperatorConfig change can affect:
+// every object, including kinds the config no longer allows so bindings that stopped being eligible
+// are handled per orphanPolicy, except those whose managed binding carries the current config hash
+// of their namespace.
+func (r *VolSyncAutoBindingReconciler) volSyncObjectsForConfigChange(ctx context.Context) []reconcile.Request {
+	cfg, err := LoadOperatorConfig(ctx, r.Client, r.OperatorConfig)
+	if err != nil || cfg.Paused || !cfg.Found {
+		// An invalid config is reported in its status; fixing it is a spec change that fans out again.
+		return nil
+	}
+	var bindings v1alpha1.BackrestVolSyncBindingList
+	if err := r.List(ctx, &bindings, client.MatchingLabels{labelManaged: "true"}); err != nil {
+		return r.volSyncObjects(ctx)
+	}
+	managed := make(map[types.NamespacedName]*v1alpha1.BackrestVolSyncBinding, len(bindings.Items))
+	for i := range bindings.Items {
+		b := &bindings.Items[i]
+		managed[types.NamespacedName{Namespace: b.Namespace, Name: b.Name}] = b
+	}
+	// current reports whether the object has a managed binding, under either name, generated from
+	// the current config.
+	current := func(obj *unstructured.Unstructured, kind, hash string) bool {
+		for _, name := range []string{desiredBindingName(kind, obj.GetName()), alternateBindingName(kind, obj.GetName(), obj.GetUID())} {
+			b := managed[types.NamespacedName{Namespace: obj.GetNamespace(), Name: name}]
+			if b != nil && bindingConflict(b, obj, kind) == "" {
+				return hash != "" && b.Annotations[annotationConfigHash] == hash
+			}
+		}
+		return false
+	}
+
+	// Namespace configs change the effective config, so the hash is computed per namespace; "" never
+	// matches, for namespaces with an invalid namespace config.
+	namespaceHashes := map[string]string{}
+	currentHash := func(namespace string) string {
+		h, ok := namespaceHashes[namespace]
+		if !ok {
+			if nsCfg, err := LoadNamespaceConfig(ctx, r.Client, cfg, namespace); err == nil {
+				h = autoBindingConfigHash(nsCfg)
+			}
+			namespaceHashes[namespace] = h
+		}
+		return h
+	}
+
+	var reqs []reconcile.Request
+	for _, kind := range []string{"ReplicationSource", "ReplicationDestination"} {
+		var list unstructured.UnstructuredList
+		list.SetGroupVersionKind(r.VolSync.ListGVK(kind))
+		if err := r.List(ctx, &list); err != nil {
+			continue
+		}
+		for i := range list.Items {
+			obj := &list.Items[i]
+			if current(obj, kind, currentHash(obj.GetNamespace())) {
+				continue
+			}
+			reqs = append(reqs, reconcile.Request{NamespacedName: types.NamespacedName{Namespace: obj.GetNamespace(), Name: obj.GetName()}})
+		}
+	}
+	return reqs
+}
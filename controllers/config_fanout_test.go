@@ -0,0 +1,251 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/jogotcha/backrest-volsync-operator/api/v1alpha1"
+	"github.com/jogotcha/backrest-volsync-operator/pkg/volsync"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+)
+
+func TestOperatorConfigChanged(t *testing.T) {
+	nn := types.NamespacedName{Namespace: "backrest-volsync-operator", Name: "backrest-volsync-operator"}
+	base := &v1alpha1.BackrestVolSyncOperatorConfig{}
+	base.Namespace = nn.Namespace
+	base.Name = nn.Name
+	base.Generation = 1
+	base.Spec.BindingGeneration.Policy = string(BindingPolicyAll)
+
+	tests := []struct {
+		name                   string
+		mutate                 func(cfg *v1alpha1.BackrestVolSyncOperatorConfig)
+		wantAutoBinding, wantB bool
+	}{
+		{
+			name: "status only",
+			mutate: func(cfg *v1alpha1.BackrestVolSyncOperatorConfig) {
+				cfg.Status.ManagedBindings = 3
+			},
+		},
+		{
+			name: "annotation only",
+			mutate: func(cfg *v1alpha1.BackrestVolSyncOperatorConfig) {
+				cfg.Annotations = map[string]string{"note": "x"}
+			},
+		},
+		{
+			name: "uri rewrites",
+			mutate: func(cfg *v1alpha1.BackrestVolSyncOperatorConfig) {
+				cfg.Generation++
+				cfg.Spec.URIRewrites = []v1alpha1.URIRewriteRule{{Match: "s3:old", Replace: "s3:new"}}
+			},
+			wantB: true,
+		},
+		{
+			name: "default repo",
+			mutate: func(cfg *v1alpha1.BackrestVolSyncOperatorConfig) {
+				cfg.Generation++
+				cfg.Spec.BindingGeneration.DefaultRepo.ExtraFlags = []string{"--compression=max"}
+			},
+			wantAutoBinding: true,
+		},
+		{
+			name: "paused",
+			mutate: func(cfg *v1alpha1.BackrestVolSyncOperatorConfig) {
+				cfg.Generation++
+				cfg.Spec.Paused = true
+			},
+			wantAutoBinding: true,
+			wantB:           true,
+		},
+	}
+	autoBinding := operatorConfigChanged(nn, autoBindingConfigFields)
+	binding := operatorConfigChanged(nn, bindingConfigFields)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			updated := base.DeepCopy()
+			tt.mutate(updated)
+			e := event.UpdateEvent{ObjectOld: base, ObjectNew: updated}
+			if got := autoBinding.Update(e); got != tt.wantAutoBinding {
+				t.Fatalf("auto-binding predicate: got %v, want %v", got, tt.wantAutoBinding)
+			}
+			if got := binding.Update(e); got != tt.wantB {
+				t.Fatalf("binding predicate: got %v, want %v", got, tt.wantB)
+			}
+		})
+	}
+
+	other := base.DeepCopy()
+	other.Name = "other"
+	if autoBinding.Create(event.CreateEvent{Object: other}) || !autoBinding.Create(event.CreateEvent{Object: base}) {
+		t.Fatalf("expected create events to pass for the operator config only")
+	}
+}
+
+// fanOutFixture returns a client with n ReplicationSources in ten namespaces, each with a managed
+// binding. The first current of them carry the config hash of cfg.
+func fanOutFixture(t testing.TB, n, current int) (*VolSyncAutoBindingReconciler, *v1alpha1.BackrestVolSyncOperatorConfig) {
+	scheme := testScheme(t)
+	cfg := &v1alpha1.BackrestVolSyncOperatorConfig{}
+	cfg.Namespace = "backrest-volsync-operator"
+	cfg.Name = "backrest-volsync-operator"
+	cfg.Spec.BindingGeneration.Policy = string(BindingPolicyAll)
+	cfg.Spec.DefaultBackrest.URL = "http://example.invalid"
+	nn := types.NamespacedName{Namespace: cfg.Namespace, Name: cfg.Name}
+
+	snap, err := LoadOperatorConfig(context.Background(), fake.NewClientBuilder().WithScheme(scheme).WithObjects(cfg).Build(), nn)
+	if err != nil {
+		t.Fatalf("load config: %v", err)
+	}
+	hash := autoBindingConfigHash(snap)
+
+	objs := []client.Object{cfg}
+	for i := 0; i < n; i++ {
+		vs := &unstructured.Unstructured{}
+		vs.SetGroupVersionKind(schema.GroupVersionKind{Group: volsync.Group, Version: volsync.Version, Kind: "ReplicationSource"})
+		vs.SetNamespace(fmt.Sprintf("ns-%d", i%10))
+		vs.SetName(fmt.Sprintf("rs-%d", i))
+		vs.SetUID(types.UID(fmt.Sprintf("uid-%d", i)))
+		b := &v1alpha1.BackrestVolSyncBinding{}
+		b.Namespace = vs.GetNamespace()
+		b.Name = desiredBindingName("ReplicationSource", vs.GetName())
+		b.Labels = map[string]string{labelManaged: "true"}
+		b.Annotations = map[string]string{annotationVolSyncRef: "replicationsource/" + vs.GetName(), annotationConfigHash: "stale"}
+		setOwnerReference(b, vs, "ReplicationSource")
+		if i < current {
+			b.Annotations[annotationConfigHash] = hash
+		}
+		objs = append(objs, vs, b)
+	}
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(objs...).Build()
+	return &VolSyncAutoBindingReconciler{Client: c, Scheme: scheme, OperatorConfig: nn}, cfg
+}
+
+func TestVolSyncObjectsForConfigChange(t *testing.T) {
+	ctx := context.Background()
+	r, cfg := fanOutFixture(t, 20, 15)
+
+	if got := len(r.volSyncObjectsForConfigChange(ctx)); got != 5 {
+		t.Fatalf("expected only the 5 stale objects enqueued, got %d", got)
+	}
+
+	// A namespace config changes the effective config of its namespace only.
+	nc := &v1alpha1.BackrestVolSyncNamespaceConfig{}
+	nc.Namespace = "ns-0"
+	nc.Name = "overrides"
+	nc.Spec.DefaultBackrest = &v1alpha1.BackrestConnection{URL: "http://team.invalid"}
+	if err := r.Create(ctx, nc); err != nil {
+		t.Fatalf("create namespace config: %v", err)
+	}
+	if got := len(r.volSyncObjectsForConfigChange(ctx)); got != 5+2 {
+		t.Fatalf("expected the 2 current objects of ns-0 enqueued too, got %d", got)
+	}
+
+	cfg.Spec.Paused = true
+	if err := r.Update(ctx, cfg); err != nil {
+		t.Fatalf("update config: %v", err)
+	}
+	if got := r.volSyncObjectsForConfigChange(ctx); got != nil {
+		t.Fatalf("expected nothing enqueued while paused, got %d", len(got))
+	}
+}
+
+// BenchmarkOperatorConfigFanOut reports the requests one OperatorConfig change enqueues for 5000
+// VolSync objects, before (all) and after (hash) skipping objects whose binding is current.
+func BenchmarkOperatorConfigFanOut(b *testing.B) {
+	ctx := context.Background()
+	r, _ := fanOutFixture(b, 5000, 4500)
+	for _, bm := range []struct {
+		name string
+		fn   func(context.Context) int
+	}{
+		{name: "all", fn: func(ctx context.Context) int { return len(r.volSyncObjects(ctx)) }},
+		{name: "hash", fn: func(ctx context.Context) int { return len(r.volSyncObjectsForConfigChange(ctx)) }},
+	} {
+		b.Run(bm.name, func(b *testing.B) {
+			var n int
+			for i := 0; i < b.N; i++ {
+				n = bm.fn(ctx)
+			}
+			b.ReportMetric(float64(n), "requests/op")
+		})
+	}
+}
+
+func TestBindingAffectedByConfigChange(t *testing.T) {
+	newBinding := func(name string, mutate func(b *v1alpha1.BackrestVolSyncBinding)) *v1alpha1.BackrestVolSyncBinding {
+		b := &v1alpha1.BackrestVolSyncBinding{}
+		b.Namespace = "workload"
+		b.Name = name
+		b.Status.RepoID = "volsync-workload-" + name
+		mutate(b)
+		return b
+	}
+	bindings := []*v1alpha1.BackrestVolSyncBinding{
+		newBinding("plain", func(*v1alpha1.BackrestVolSyncBinding) {}),
+		newBinding("generated", func(b *v1alpha1.BackrestVolSyncBinding) {
+			b.Labels = map[string]string{labelManaged: "true"}
+		}),
+		newBinding("generated-new", func(b *v1alpha1.BackrestVolSyncBinding) {
+			b.Labels = map[string]string{labelManaged: "true"}
+			b.Status.RepoID = ""
+		}),
+		newBinding("triggers", func(b *v1alpha1.BackrestVolSyncBinding) {
+			b.Spec.Repo.TriggerTasksOnSnapshot = ptr.To(true)
+		}),
+	}
+
+	tests := []struct {
+		name   string
+		mutate func(spec *v1alpha1.BackrestVolSyncOperatorConfigSpec)
+		want   []string
+	}{
+		{
+			name: "uri rewrites",
+			mutate: func(spec *v1alpha1.BackrestVolSyncOperatorConfigSpec) {
+				spec.URIRewrites = []v1alpha1.URIRewriteRule{{Match: "a", Replace: "b"}}
+			},
+			want: []string{"plain", "generated", "generated-new", "triggers"},
+		},
+		{
+			name:   "cluster name",
+			mutate: func(spec *v1alpha1.BackrestVolSyncOperatorConfigSpec) { spec.ClusterName = "prod" },
+			want:   []string{"generated-new"},
+		},
+		{
+			name: "completion marker",
+			mutate: func(spec *v1alpha1.BackrestVolSyncOperatorConfigSpec) {
+				spec.CompletionMarker.SyncTime = []string{"{.status.lastSyncEndTime}"}
+			},
+			want: []string{"triggers"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			oldSpec := &v1alpha1.BackrestVolSyncOperatorConfigSpec{}
+			newSpec := &v1alpha1.BackrestVolSyncOperatorConfigSpec{}
+			tt.mutate(newSpec)
+			var got []string
+			for _, b := range bindings {
+				if bindingAffectedByConfigChange(b, oldSpec, newSpec) {
+					got = append(got, b.Name)
+				}
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("got %v, want %v", got, tt.want)
+			}
+			if !bindingAffectedByConfigChange(bindings[0], nil, newSpec) {
+				t.Fatalf("expected a created or deleted config to affect every binding")
+			}
+		})
+	}
+}
@@ -0,0 +1,77 @@
+package controllers
+
+import (
+	"context"
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	v1 "github.com/garethgeorge/backrest/gen/go/v1"
+	"github.com/jogotcha/backrest-volsync-operator/api/v1alpha1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	conditionCredentialRotationFailed = "CredentialRotationFailed"
+	reasonCredentialRotationFailed    = "CredentialRotationFailed"
+)
+
+// repoCredentialDigest identifies the URI/password pair registered in a target, keyed like repoContentDigest.
+func repoCredentialDigest(binding *v1alpha1.BackrestVolSyncBinding, repo *v1.Repo, key []byte) string {
+	mac := newRepoMAC(binding, key)
+	_, _ = mac.Write([]byte(repo.GetUri()))
+	_, _ = mac.Write([]byte{0})
+	_, _ = mac.Write([]byte(repo.GetPassword()))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// validateRepoCredentials checks that Backrest can open the repo with the new credentials before
+// they replace the working ones. During a restic multi-key rotation (new key added with
+// `restic key add` before the Secret is updated) both passwords open the repo, so the new one
+// validates and is applied; the old key can be removed afterwards.
+func validateRepoCredentials(ctx context.Context, brClient backrestRepoClient, repo *v1.Repo) error {
+	exists, err := brClient.CheckRepoExists(ctx, repo)
+	if err != nil {
+		return err
+	}
+	if !exists && !repo.GetAutoInitialize() {
+		return fmt.Errorf("repository not found with the new credentials")
+	}
+	return nil
+}
+
+// setCredentialRotationCondition reports targets that rejected rotated credentials. The condition
+// is only added once a rotation failed, and flips to False when the rotation later succeeds.
+func setCredentialRotationCondition(b *v1alpha1.BackrestVolSyncBinding, failures []targetFailure) bool {
+	var failed []string
+	var errHash string
+	for _, f := range failures {
+		if f.reason == reasonCredentialRotationFailed {
+			failed = append(failed, f.target)
+			if errHash == "" {
+				errHash = f.errorHash
+			}
+		}
+	}
+	cond := metav1.Condition{
+		Type:               conditionCredentialRotationFailed,
+		ObservedGeneration: b.Generation,
+		LastTransitionTime: metav1.Now(),
+	}
+	if len(failed) > 0 {
+		cond.Status = metav1.ConditionTrue
+		cond.Reason = "ValidationFailed"
+		cond.Message = fmt.Sprintf("New repository credentials were rejected; previous configuration kept in Backrest targets: %s (details omitted; errorHash=%s)", strings.Join(failed, ", "), errHash)
+	} else {
+		// Other target failures may have prevented validation, so the previous outcome stays current.
+		existing := meta.FindStatusCondition(b.Status.Conditions, conditionCredentialRotationFailed)
+		if existing == nil || existing.Status == metav1.ConditionFalse || len(failures) > 0 {
+			return false
+		}
+		cond.Status = metav1.ConditionFalse
+		cond.Reason = "Rotated"
+		cond.Message = "New repository credentials validated and applied"
+	}
+	return meta.SetStatusCondition(&b.Status.Conditions, cond)
+}
@@ -0,0 +1,110 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/jogotcha/backrest-volsync-operator/api/v1alpha1"
+	"github.com/jogotcha/backrest-volsync-operator/pkg/backrest"
+	"github.com/jogotcha/backrest-volsync-operator/pkg/volsync"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestBackrestVolSyncBindingReconcile_ValidatesRotatedPassword(t *testing.T) {
+	ctx := context.Background()
+	scheme := bindingTestScheme(t)
+
+	b := &v1alpha1.BackrestVolSyncBinding{}
+	b.Namespace = "workload"
+	b.Name = "b"
+	b.UID = "binding-uid"
+	b.Spec.Backrest.URL = "http://backrest.invalid"
+	b.Spec.Source = v1alpha1.VolSyncSourceRef{Kind: "ReplicationSource", Name: "demo"}
+
+	vs := &unstructured.Unstructured{Object: map[string]any{
+		"apiVersion": volsync.Group + "/" + volsync.Version,
+		"kind":       "ReplicationSource",
+		"metadata":   map[string]any{"name": "demo", "namespace": "workload"},
+		"spec":       map[string]any{"restic": map[string]any{"repository": "repo-secret"}},
+	}}
+
+	sec := &corev1.Secret{}
+	sec.Namespace = "workload"
+	sec.Name = "repo-secret"
+	sec.Data = map[string][]byte{
+		"RESTIC_REPOSITORY": []byte("s3://bucket/repo"),
+		"RESTIC_PASSWORD":   []byte("old"),
+	}
+
+	c := fake.NewClientBuilder().WithScheme(scheme).
+		WithStatusSubresource(&v1alpha1.BackrestVolSyncBinding{}).
+		WithObjects(b, vs, sec).
+		Build()
+
+	br := &fakeBackrestRepoClient{}
+	r := &BackrestVolSyncBindingReconciler{
+		Client: c,
+		Scheme: scheme,
+		BackrestClientFactory: func(string, backrest.Auth, backrest.Options) backrestRepoClient {
+			return br
+		},
+	}
+	req := ctrl.Request{NamespacedName: types.NamespacedName{Namespace: b.Namespace, Name: b.Name}}
+
+	if _, err := r.Reconcile(ctx, req); err != nil {
+		t.Fatalf("reconcile: %v", err)
+	}
+	if br.checkRepoCalls != 0 {
+		t.Fatalf("initial registration must not validate, got %d checks", br.checkRepoCalls)
+	}
+
+	var current corev1.Secret
+	if err := c.Get(ctx, types.NamespacedName{Namespace: sec.Namespace, Name: sec.Name}, &current); err != nil {
+		t.Fatalf("get secret: %v", err)
+	}
+	current.Data["RESTIC_PASSWORD"] = []byte("wrong")
+	if err := c.Update(ctx, &current); err != nil {
+		t.Fatalf("update secret: %v", err)
+	}
+
+	br.checkRepoErr = errors.New("wrong password or no key found")
+	if _, err := r.Reconcile(ctx, req); err == nil {
+		t.Fatalf("expected error for rejected credentials")
+	}
+	if br.addRepoCalls != 1 || br.lastRepo.Password != "old" {
+		t.Fatalf("expected previous config to stay applied, got calls=%d password=%q", br.addRepoCalls, br.lastRepo.Password)
+	}
+	var got v1alpha1.BackrestVolSyncBinding
+	if err := c.Get(ctx, req.NamespacedName, &got); err != nil {
+		t.Fatalf("get: %v", err)
+	}
+	if !meta.IsStatusConditionTrue(got.Status.Conditions, conditionCredentialRotationFailed) {
+		t.Fatalf("expected CredentialRotationFailed=True, got %#v", got.Status.Conditions)
+	}
+	if reason := getReadyReason(&got); reason != reasonCredentialRotationFailed {
+		t.Fatalf("expected Ready reason %s, got %q", reasonCredentialRotationFailed, reason)
+	}
+
+	// Once the new key is added to the repo (both keys valid), the rotation goes through.
+	br.checkRepoErr = nil
+	if _, err := r.Reconcile(ctx, req); err != nil {
+		t.Fatalf("reconcile #3: %v", err)
+	}
+	if br.addRepoCalls != 2 || br.lastRepo.Password != "wrong" {
+		t.Fatalf("expected rotated password applied, got calls=%d password=%q", br.addRepoCalls, br.lastRepo.Password)
+	}
+	if err := c.Get(ctx, req.NamespacedName, &got); err != nil {
+		t.Fatalf("get: %v", err)
+	}
+	cond := meta.FindStatusCondition(got.Status.Conditions, conditionCredentialRotationFailed)
+	if cond == nil || cond.Status != metav1.ConditionFalse || cond.Reason != "Rotated" {
+		t.Fatalf("expected CredentialRotationFailed=False/Rotated, got %#v", cond)
+	}
+}
@@ -0,0 +1,100 @@
+package controllers
+
+import (
+	"sort"
+	"sync"
+
+	"github.com/jogotcha/backrest-volsync-operator/api/v1alpha1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+)
+
+type dryRunAction string
+
+const (
+	dryRunNone   dryRunAction = ""
+	dryRunCreate dryRunAction = "Create"
+	dryRunUpdate dryRunAction = "Update"
+	dryRunDelete dryRunAction = "Delete"
+
+	// maxDryRunEntries caps each list in status.dryRun; the totals are not capped.
+	maxDryRunEntries = 50
+)
+
+// DryRunRecorder collects what VolSyncAutoBindingReconciler would change while
+// bindingGeneration.dryRun is set. OperatorConfigReconciler reports it in the OperatorConfig
+// status, so a dry run does not write the status once per VolSync object.
+type DryRunRecorder struct {
+	mu      sync.Mutex
+	actions map[types.NamespacedName]dryRunAction
+	changed chan event.GenericEvent
+}
+
+// NewDryRunRecorder returns an empty recorder to share between the two reconcilers.
+func NewDryRunRecorder() *DryRunRecorder {
+	return &DryRunRecorder{
+		actions: map[types.NamespacedName]dryRunAction{},
+		changed: make(chan event.GenericEvent, 1),
+	}
+}
+
+// Record stores the action for a binding and reports whether it differs from the stored one. A nil
+// recorder reports every action as new.
+func (d *DryRunRecorder) Record(binding types.NamespacedName, action dryRunAction) bool {
+	if d == nil {
+		return true
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.actions[binding] == action {
+		return false
+	}
+	if action == dryRunNone {
+		delete(d.actions, binding)
+	} else {
+		d.actions[binding] = action
+	}
+	// Coalesce notifications; one pending event re-reads everything.
+	select {
+	case d.changed <- event.GenericEvent{Object: &v1alpha1.BackrestVolSyncOperatorConfig{}}:
+	default:
+	}
+	return true
+}
+
+// Reset forgets all actions, when dry run is turned off.
+func (d *DryRunRecorder) Reset() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.actions = map[types.NamespacedName]dryRunAction{}
+}
+
+// Report renders the recorded actions, listing at most maxDryRunEntries bindings per action.
+func (d *DryRunRecorder) Report() *v1alpha1.DryRunStatus {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	lists := map[dryRunAction][]string{}
+	for binding, action := range d.actions {
+		lists[action] = append(lists[action], binding.String())
+	}
+	capped := func(names []string) []string {
+		sort.Strings(names)
+		if len(names) > maxDryRunEntries {
+			return names[:maxDryRunEntries]
+		}
+		return names
+	}
+	return &v1alpha1.DryRunStatus{
+		WouldCreate:      capped(lists[dryRunCreate]),
+		WouldUpdate:      capped(lists[dryRunUpdate]),
+		WouldDelete:      capped(lists[dryRunDelete]),
+		WouldCreateTotal: int32(len(lists[dryRunCreate])),
+		WouldUpdateTotal: int32(len(lists[dryRunUpdate])),
+		WouldDeleteTotal: int32(len(lists[dryRunDelete])),
+	}
+}
+
+// Changed signals that the recorded actions changed.
+func (d *DryRunRecorder) Changed() <-chan event.GenericEvent {
+	return d.changed
+}
@@ -0,0 +1,147 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/jogotcha/backrest-volsync-operator/api/v1alpha1"
+	"github.com/jogotcha/backrest-volsync-operator/pkg/volsync"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/events"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestDryRunRecorder_Report(t *testing.T) {
+	d := NewDryRunRecorder()
+	for i := 0; i < maxDryRunEntries+5; i++ {
+		d.Record(types.NamespacedName{Namespace: "workload", Name: fmt.Sprintf("b-%02d", i)}, dryRunCreate)
+	}
+	key := types.NamespacedName{Namespace: "workload", Name: "updated"}
+	if !d.Record(key, dryRunUpdate) || d.Record(key, dryRunUpdate) {
+		t.Fatalf("expected only the first record of an action to report a change")
+	}
+	if !d.Record(types.NamespacedName{Namespace: "workload", Name: "b-00"}, dryRunNone) {
+		t.Fatalf("expected clearing an action to report a change")
+	}
+
+	report := d.Report()
+	if report.WouldCreateTotal != maxDryRunEntries+4 || len(report.WouldCreate) != maxDryRunEntries || report.WouldCreate[0] != "workload/b-01" {
+		t.Fatalf("unexpected creates: %d %v", report.WouldCreateTotal, report.WouldCreate)
+	}
+	if report.WouldUpdateTotal != 1 || !reflect.DeepEqual(report.WouldUpdate, []string{"workload/updated"}) || report.WouldDeleteTotal != 0 {
+		t.Fatalf("unexpected report: %+v", report)
+	}
+
+	d.Reset()
+	if report := d.Report(); report.WouldCreateTotal != 0 || report.WouldUpdateTotal != 0 {
+		t.Fatalf("expected empty report after reset, got %+v", report)
+	}
+}
+
+func TestVolSyncAutoBindingReconcile_DryRun(t *testing.T) {
+	ctx := context.Background()
+	scheme := testScheme(t)
+
+	cfg := &v1alpha1.BackrestVolSyncOperatorConfig{}
+	cfg.Namespace = "backrest-volsync-operator"
+	cfg.Name = "backrest-volsync-operator"
+	cfg.Spec.BindingGeneration.Policy = string(BindingPolicyAll)
+	cfg.Spec.BindingGeneration.OrphanPolicy = string(OrphanPolicyDelete)
+	cfg.Spec.BindingGeneration.DryRun = true
+	cfg.Spec.DefaultBackrest.URL = "http://example.invalid"
+
+	vs := &unstructured.Unstructured{}
+	vs.SetGroupVersionKind(schema.GroupVersionKind{Group: volsync.Group, Version: volsync.Version, Kind: "ReplicationSource"})
+	vs.SetNamespace("workload")
+	vs.SetName("demo")
+	vs.SetUID(types.UID("1111"))
+
+	c := fake.NewClientBuilder().WithScheme(scheme).
+		WithStatusSubresource(&v1alpha1.BackrestVolSyncOperatorConfig{}).
+		WithObjects(cfg, vs).
+		Build()
+	recorder := events.NewFakeRecorder(10)
+	dryRun := NewDryRunRecorder()
+	nn := types.NamespacedName{Namespace: cfg.Namespace, Name: cfg.Name}
+	r := &VolSyncAutoBindingReconciler{Client: c, Scheme: scheme, Recorder: recorder, OperatorConfig: nn, DryRun: dryRun}
+	status := &OperatorConfigReconciler{Client: c, Scheme: scheme, OperatorConfig: nn, DryRun: dryRun}
+	req := ctrl.Request{NamespacedName: types.NamespacedName{Namespace: "workload", Name: "demo"}}
+	bindingKey := types.NamespacedName{Namespace: "workload", Name: "bvsb-rs-demo"}
+
+	reconcile := func() {
+		t.Helper()
+		if _, err := r.Reconcile(ctx, req); err != nil {
+			t.Fatalf("reconcile: %v", err)
+		}
+	}
+	reportedDryRun := func() *v1alpha1.DryRunStatus {
+		t.Helper()
+		if _, err := status.Reconcile(ctx, ctrl.Request{NamespacedName: nn}); err != nil {
+			t.Fatalf("reconcile config: %v", err)
+		}
+		var got v1alpha1.BackrestVolSyncOperatorConfig
+		if err := c.Get(ctx, nn, &got); err != nil {
+			t.Fatalf("get config: %v", err)
+		}
+		return got.Status.DryRun
+	}
+	setDryRun := func(mutate func(*v1alpha1.BackrestVolSyncOperatorConfig)) {
+		t.Helper()
+		var got v1alpha1.BackrestVolSyncOperatorConfig
+		if err := c.Get(ctx, nn, &got); err != nil {
+			t.Fatalf("get config: %v", err)
+		}
+		mutate(&got)
+		if err := c.Update(ctx, &got); err != nil {
+			t.Fatalf("update config: %v", err)
+		}
+	}
+
+	// A create is only reported, once.
+	reconcile()
+	reconcile()
+	if err := c.Get(ctx, bindingKey, &v1alpha1.BackrestVolSyncBinding{}); err == nil {
+		t.Fatalf("expected no binding in dry run")
+	}
+	if e := <-recorder.Events; e != "Normal WouldCreateBinding Dry run: would create BackrestVolSyncBinding bvsb-rs-demo" {
+		t.Fatalf("unexpected event %q", e)
+	}
+	if len(recorder.Events) != 0 {
+		t.Fatalf("expected a single event, got another: %q", <-recorder.Events)
+	}
+	if got := reportedDryRun(); got == nil || got.WouldCreateTotal != 1 || !reflect.DeepEqual(got.WouldCreate, []string{"workload/bvsb-rs-demo"}) {
+		t.Fatalf("unexpected status.dryRun: %+v", got)
+	}
+
+	// Turning dry run off applies the change and clears the report.
+	setDryRun(func(cfg *v1alpha1.BackrestVolSyncOperatorConfig) { cfg.Spec.BindingGeneration.DryRun = false })
+	reconcile()
+	<-recorder.Events // BindingCreated
+	if err := c.Get(ctx, bindingKey, &v1alpha1.BackrestVolSyncBinding{}); err != nil {
+		t.Fatalf("expected binding created: %v", err)
+	}
+	if got := reportedDryRun(); got != nil {
+		t.Fatalf("expected status.dryRun cleared, got %+v", got)
+	}
+
+	// An orphan delete is held back while dry run is on.
+	setDryRun(func(cfg *v1alpha1.BackrestVolSyncOperatorConfig) {
+		cfg.Spec.BindingGeneration.DryRun = true
+		cfg.Spec.BindingGeneration.Policy = string(BindingPolicyDisabled)
+	})
+	reconcile()
+	if err := c.Get(ctx, bindingKey, &v1alpha1.BackrestVolSyncBinding{}); err != nil {
+		t.Fatalf("expected binding kept in dry run: %v", err)
+	}
+	if e := <-recorder.Events; e != "Normal WouldDeleteBinding Dry run: would delete BackrestVolSyncBinding bvsb-rs-demo: the binding policy is Disabled" {
+		t.Fatalf("unexpected event %q", e)
+	}
+	if got := reportedDryRun(); got == nil || got.WouldDeleteTotal != 1 || got.WouldCreateTotal != 0 {
+		t.Fatalf("unexpected status.dryRun: %+v", got)
+	}
+}
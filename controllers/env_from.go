@@ -0,0 +1,91 @@
+package controllers
+
+import (
+	"context"
+
+	"github.com/jogotcha/backrest-volsync-operator/api/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const indexEnvFrom = "spec.repo.envFrom"
+
+// envFromIndexValues returns "secret/<name>" and "configmap/<name>" for each object a binding reads via envFrom.
+func envFromIndexValues(b *v1alpha1.BackrestVolSyncBinding) []string {
+	var out []string
+	for _, src := range b.Spec.Repo.EnvFrom {
+		switch {
+		case src.SecretRef != nil && src.SecretRef.Name != "":
+			out = append(out, "secret/"+src.SecretRef.Name)
+		case src.ConfigMapRef != nil && src.ConfigMapRef.Name != "":
+			out = append(out, "configmap/"+src.ConfigMapRef.Name)
+		}
+	}
+	return out
+}
+
+func validateEnvFrom(sources []v1alpha1.EnvFromSource, path *field.Path) field.ErrorList {
+	var errs field.ErrorList
+	for i, src := range sources {
+		p := path.Index(i)
+		switch {
+		case src.SecretRef == nil && src.ConfigMapRef == nil:
+			errs = append(errs, field.Required(p, "one of secretRef or configMapRef is required"))
+		case src.SecretRef != nil && src.ConfigMapRef != nil:
+			errs = append(errs, field.Forbidden(p.Child("configMapRef"), "must not be set together with secretRef"))
+		case src.SecretRef != nil && src.SecretRef.Name == "":
+			errs = append(errs, field.Required(p.Child("secretRef", "name"), "required"))
+		case src.ConfigMapRef != nil && src.ConfigMapRef.Name == "":
+			errs = append(errs, field.Required(p.Child("configMapRef", "name"), "required"))
+		}
+	}
+	return errs
+}
+
+// loadEnvFrom reads the envFrom sources in order; later sources override earlier ones.
+func loadEnvFrom(ctx context.Context, c client.Client, namespace string, sources []v1alpha1.EnvFromSource) (map[string]string, error) {
+	env := map[string]string{}
+	for _, src := range sources {
+		var data map[string]string
+		switch {
+		case src.SecretRef != nil:
+			var sec corev1.Secret
+			if err := c.Get(ctx, types.NamespacedName{Namespace: namespace, Name: src.SecretRef.Name}, &sec); err != nil {
+				if apierrors.IsNotFound(err) && src.Optional {
+					continue
+				}
+				return nil, err
+			}
+			data = make(map[string]string, len(sec.Data))
+			for k, v := range sec.Data {
+				data[k] = string(v)
+			}
+		case src.ConfigMapRef != nil:
+			var cm corev1.ConfigMap
+			if err := c.Get(ctx, types.NamespacedName{Namespace: namespace, Name: src.ConfigMapRef.Name}, &cm); err != nil {
+				if apierrors.IsNotFound(err) && src.Optional {
+					continue
+				}
+				return nil, err
+			}
+			data = cm.Data
+		default:
+			continue
+		}
+		if len(src.Keys) > 0 {
+			for _, k := range src.Keys {
+				if v, ok := data[k]; ok {
+					env[src.Prefix+k] = v
+				}
+			}
+			continue
+		}
+		for k, v := range data {
+			env[src.Prefix+k] = v
+		}
+	}
+	return env, nil
+}
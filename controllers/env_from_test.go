@@ -0,0 +1,154 @@
+package controllers
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/jogotcha/backrest-volsync-operator/api/v1alpha1"
+	"github.com/jogotcha/backrest-volsync-operator/pkg/backrest"
+	"github.com/jogotcha/backrest-volsync-operator/pkg/volsync"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestBackrestVolSyncBindingReconcile_MergesEnvFrom(t *testing.T) {
+	ctx := context.Background()
+	scheme := bindingTestScheme(t)
+
+	b := &v1alpha1.BackrestVolSyncBinding{}
+	b.Namespace = "workload"
+	b.Name = "b"
+	b.Spec.Backrest.URL = "http://backrest.invalid"
+	b.Spec.Source = v1alpha1.VolSyncSourceRef{Kind: "ReplicationSource", Name: "demo"}
+	b.Spec.Repo.EnvFrom = []v1alpha1.EnvFromSource{
+		{ConfigMapRef: &v1alpha1.ConfigMapRef{Name: "proxy"}},
+		{SecretRef: &v1alpha1.SecretRef{Name: "aws"}, Keys: []string{"ACCESS_KEY_ID", "SECRET_ACCESS_KEY"}, Prefix: "AWS_"},
+		{SecretRef: &v1alpha1.SecretRef{Name: "missing"}, Optional: true},
+	}
+
+	vs := &unstructured.Unstructured{Object: map[string]any{
+		"apiVersion": volsync.Group + "/" + volsync.Version,
+		"kind":       "ReplicationSource",
+		"metadata":   map[string]any{"name": "demo", "namespace": "workload"},
+		"spec":       map[string]any{"restic": map[string]any{"repository": "repo-secret"}},
+	}}
+
+	repoSecret := &corev1.Secret{}
+	repoSecret.Namespace = "workload"
+	repoSecret.Name = "repo-secret"
+	repoSecret.Data = map[string][]byte{
+		"RESTIC_REPOSITORY": []byte("s3:https://s3.example.com/bucket"),
+		"RESTIC_PASSWORD":   []byte("pass"),
+		"HTTPS_PROXY":       []byte("http://repo-proxy:3128"),
+	}
+
+	proxy := &corev1.ConfigMap{}
+	proxy.Namespace = "workload"
+	proxy.Name = "proxy"
+	proxy.Data = map[string]string{
+		"HTTPS_PROXY": "http://shared-proxy:3128",
+		"NO_PROXY":    ".svc",
+	}
+
+	aws := &corev1.Secret{}
+	aws.Namespace = "workload"
+	aws.Name = "aws"
+	aws.Data = map[string][]byte{
+		"ACCESS_KEY_ID":     []byte("id"),
+		"SECRET_ACCESS_KEY": []byte("secret"),
+		"SESSION_TOKEN":     []byte("ignored"),
+	}
+
+	c := fake.NewClientBuilder().WithScheme(scheme).
+		WithStatusSubresource(&v1alpha1.BackrestVolSyncBinding{}).
+		WithObjects(b, vs, repoSecret, proxy, aws).
+		Build()
+
+	br := &fakeBackrestRepoClient{}
+	r := &BackrestVolSyncBindingReconciler{
+		Client: c,
+		Scheme: scheme,
+		BackrestClientFactory: func(string, backrest.Auth, backrest.Options) backrestRepoClient {
+			return br
+		},
+	}
+
+	req := ctrl.Request{NamespacedName: types.NamespacedName{Namespace: b.Namespace, Name: b.Name}}
+	if _, err := r.Reconcile(ctx, req); err != nil {
+		t.Fatalf("reconcile: %v", err)
+	}
+	wantEnv := []string{
+		"AWS_ACCESS_KEY_ID=id",
+		"AWS_SECRET_ACCESS_KEY=secret",
+		"HTTPS_PROXY=http://repo-proxy:3128",
+		"NO_PROXY=.svc",
+	}
+	if br.lastRepo == nil || !reflect.DeepEqual(br.lastRepo.Env, wantEnv) {
+		t.Fatalf("env: got %#v want %v", br.lastRepo, wantEnv)
+	}
+
+	// Updating a referenced ConfigMap must re-register the repo.
+	var current corev1.ConfigMap
+	if err := c.Get(ctx, types.NamespacedName{Namespace: "workload", Name: "proxy"}, &current); err != nil {
+		t.Fatalf("get configmap: %v", err)
+	}
+	current.Data["NO_PROXY"] = ".svc,.cluster.local"
+	if err := c.Update(ctx, &current); err != nil {
+		t.Fatalf("update configmap: %v", err)
+	}
+	if _, err := r.Reconcile(ctx, req); err != nil {
+		t.Fatalf("reconcile #2: %v", err)
+	}
+	if br.addRepoCalls != 2 {
+		t.Fatalf("expected re-apply after configmap change, got %d calls", br.addRepoCalls)
+	}
+}
+
+func TestBackrestVolSyncBindingReconcile_EnvFromMissingFails(t *testing.T) {
+	ctx := context.Background()
+	scheme := bindingTestScheme(t)
+
+	b := &v1alpha1.BackrestVolSyncBinding{}
+	b.Namespace = "workload"
+	b.Name = "b"
+	b.Spec.Backrest.URL = "http://backrest.invalid"
+	b.Spec.Source = v1alpha1.VolSyncSourceRef{Kind: "ReplicationSource", Name: "demo"}
+	b.Spec.Repo.EnvFrom = []v1alpha1.EnvFromSource{{SecretRef: &v1alpha1.SecretRef{Name: "missing"}}}
+
+	vs := &unstructured.Unstructured{Object: map[string]any{
+		"apiVersion": volsync.Group + "/" + volsync.Version,
+		"kind":       "ReplicationSource",
+		"metadata":   map[string]any{"name": "demo", "namespace": "workload"},
+		"spec":       map[string]any{"restic": map[string]any{"repository": "repo-secret"}},
+	}}
+
+	repoSecret := &corev1.Secret{}
+	repoSecret.Namespace = "workload"
+	repoSecret.Name = "repo-secret"
+	repoSecret.Data = map[string][]byte{
+		"RESTIC_REPOSITORY": []byte("s3:https://s3.example.com/bucket"),
+		"RESTIC_PASSWORD":   []byte("pass"),
+	}
+
+	c := fake.NewClientBuilder().WithScheme(scheme).
+		WithStatusSubresource(&v1alpha1.BackrestVolSyncBinding{}).
+		WithObjects(b, vs, repoSecret).
+		Build()
+
+	r := &BackrestVolSyncBindingReconciler{Client: c, Scheme: scheme}
+	req := ctrl.Request{NamespacedName: types.NamespacedName{Namespace: b.Namespace, Name: b.Name}}
+	if _, err := r.Reconcile(ctx, req); err == nil {
+		t.Fatalf("expected error for missing envFrom secret")
+	}
+	var got v1alpha1.BackrestVolSyncBinding
+	if err := c.Get(ctx, req.NamespacedName, &got); err != nil {
+		t.Fatalf("get: %v", err)
+	}
+	if reason := getReadyReason(&got); reason != "EnvFromNotFound" {
+		t.Fatalf("expected EnvFromNotFound, got %q", reason)
+	}
+}
@@ -0,0 +1,150 @@
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jogotcha/backrest-volsync-operator/api/v1alpha1"
+	"github.com/jogotcha/backrest-volsync-operator/pkg/volsync"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// annotationManualSync requests a VolSync manual sync; every new value triggers one sync.
+const annotationManualSync = "backrest.garethgeorge.com/sync-request"
+
+// manualSyncRetryInterval paces retries of the Backrest tasks after a manual sync.
+const manualSyncRetryInterval = 30 * time.Second
+
+const (
+	manualSyncPending   = "Pending"
+	manualSyncSyncing   = "Syncing"
+	manualSyncIndexing  = "Indexing"
+	manualSyncCompleted = "Completed"
+	manualSyncFailed    = "Failed"
+)
+
+// manualSyncTrigger derives the spec.trigger.manual value for a request. It is unique per binding
+// and request, so VolSync starts exactly one sync for it.
+func manualSyncTrigger(b *v1alpha1.BackrestVolSyncBinding, request string) string {
+	return "backrest-" + hashString(string(b.UID) + "/" + request)[:16]
+}
+
+// manualSyncAwaitingTasks reports whether a completed manual sync still needs the Backrest tasks.
+func manualSyncAwaitingTasks(b *v1alpha1.BackrestVolSyncBinding) bool {
+	return b.Status.ManualSync != nil && b.Status.ManualSync.Phase == manualSyncIndexing
+}
+
+// reconcileManualSync drives a manual sync requested with annotationManualSync:
+// Pending -> Syncing (spec.trigger.manual set) -> Indexing (status.lastManualSync matches) -> Completed
+// (Backrest tasks triggered in every target, see completeManualSync). A returned error is retried.
+func (r *BackrestVolSyncBindingReconciler) reconcileManualSync(ctx context.Context, b *v1alpha1.BackrestVolSyncBinding, vsObj *unstructured.Unstructured) (bool, error) {
+	request := strings.TrimSpace(b.Annotations[annotationManualSync])
+	if request == "" {
+		return false, nil
+	}
+
+	changed := false
+	ms := b.Status.ManualSync
+	if ms == nil || ms.Request != request {
+		now := metav1.Now()
+		ms = &v1alpha1.ManualSyncStatus{Request: request, Phase: manualSyncPending, RequestTime: &now}
+		b.Status.ManualSync = ms
+		changed = true
+		if b.Spec.Source.Kind != volsync.KindReplicationSource {
+			ms.Phase = manualSyncFailed
+			ms.Message = "Manual sync is only supported for ReplicationSource bindings"
+			if r.Recorder != nil {
+				r.Recorder.Eventf(b, nil, corev1.EventTypeWarning, "ManualSyncRejected", "TriggerSync", "%s", ms.Message)
+			}
+			return changed, nil
+		}
+		ms.Trigger = manualSyncTrigger(b, request)
+	}
+
+	switch ms.Phase {
+	case manualSyncPending:
+		if err := r.patchManualTrigger(ctx, vsObj, ms.Trigger); err != nil {
+			errHash := hashString(err.Error())
+			ms.Message = fmt.Sprintf("Unable to set spec.trigger.manual (details omitted; errorHash=%s)", errHash)
+			return true, &sanitizedReconcileError{reason: "ManualSyncTriggerFailed", errorHash: errHash}
+		}
+		ms.Phase = manualSyncSyncing
+		ms.Message = "Waiting for VolSync to complete the manual sync"
+		if r.Recorder != nil {
+			r.Recorder.Eventf(b, nil, corev1.EventTypeNormal, "ManualSyncTriggered", "TriggerSync", "Set spec.trigger.manual=%s on ReplicationSource %s", ms.Trigger, b.Spec.Source.Name)
+		}
+		return true, nil
+	case manualSyncSyncing:
+		s, err := volsync.ParseSyncStatus(vsObj)
+		if err != nil || s.LastManualSync != ms.Trigger {
+			return changed, nil
+		}
+		if err := r.clearManualTrigger(ctx, vsObj, ms.Trigger); err != nil {
+			errHash := hashString(err.Error())
+			ms.Message = fmt.Sprintf("Unable to restore the ReplicationSource schedule (details omitted; errorHash=%s)", errHash)
+			return true, &sanitizedReconcileError{reason: "ManualSyncTriggerFailed", errorHash: errHash}
+		}
+		ms.Phase = manualSyncIndexing
+		ms.Message = "Manual sync completed; triggering Backrest repo tasks"
+		return true, nil
+	}
+	return changed, nil
+}
+
+// completeManualSync marks an Indexing manual sync Completed once every target triggered the
+// Backrest tasks for the current completion marker.
+func (r *BackrestVolSyncBindingReconciler) completeManualSync(b *v1alpha1.BackrestVolSyncBinding, vsObj *unstructured.Unstructured, markers *volsync.MarkerExtractor) bool {
+	if !manualSyncAwaitingTasks(b) {
+		return false
+	}
+	marker, syncTime, ready, err := markers.CompletionMarker(vsObj)
+	if err != nil || !ready {
+		return false
+	}
+	for i := range b.Status.Targets {
+		ts := &b.Status.Targets[i]
+		if !snapshotTaskStateMatches(marker, syncTime, ts.LastSnapshotMarker, ts.LastSnapshotSyncTime) {
+			return false
+		}
+	}
+	now := metav1.Now()
+	ms := b.Status.ManualSync
+	ms.Phase = manualSyncCompleted
+	ms.Message = "Manual sync completed and Backrest repo tasks triggered"
+	ms.CompletionTime = &now
+	if r.Recorder != nil {
+		r.Recorder.Eventf(b, nil, corev1.EventTypeNormal, "ManualSyncCompleted", "TriggerSync", "Manual sync %s completed and Backrest repo tasks triggered", ms.Trigger)
+	}
+	return true
+}
+
+func (r *BackrestVolSyncBindingReconciler) patchManualTrigger(ctx context.Context, vsObj *unstructured.Unstructured, value string) error {
+	patch, err := json.Marshal(map[string]any{"spec": map[string]any{"trigger": map[string]any{"manual": value}}})
+	if err != nil {
+		return err
+	}
+	return r.Patch(ctx, vsObj, client.RawPatch(types.MergePatchType, patch))
+}
+
+// clearManualTrigger removes our manual trigger so a configured schedule applies again; VolSync
+// ignores spec.trigger.schedule while spec.trigger.manual is set. Without a schedule the value is
+// kept, since removing it would make the ReplicationSource sync continuously.
+func (r *BackrestVolSyncBindingReconciler) clearManualTrigger(ctx context.Context, vsObj *unstructured.Unstructured, value string) error {
+	manual, _, _ := unstructured.NestedString(vsObj.Object, "spec", "trigger", "manual")
+	schedule, _, _ := unstructured.NestedString(vsObj.Object, "spec", "trigger", "schedule")
+	if manual != value || schedule == "" {
+		return nil
+	}
+	patch, err := json.Marshal(map[string]any{"spec": map[string]any{"trigger": map[string]any{"manual": nil}}})
+	if err != nil {
+		return err
+	}
+	return r.Patch(ctx, vsObj, client.RawPatch(types.MergePatchType, patch))
+}
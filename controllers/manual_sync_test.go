@@ -0,0 +1,173 @@
+package controllers
+
+import (
+	"context"
+	"testing"
+
+	"github.com/jogotcha/backrest-volsync-operator/api/v1alpha1"
+	"github.com/jogotcha/backrest-volsync-operator/pkg/backrest"
+	"github.com/jogotcha/backrest-volsync-operator/pkg/volsync"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestBackrestVolSyncBindingReconcile_ManualSync(t *testing.T) {
+	ctx := context.Background()
+	scheme := bindingTestScheme(t)
+
+	b := &v1alpha1.BackrestVolSyncBinding{}
+	b.Namespace = "workload"
+	b.Name = "b"
+	b.UID = "binding-uid"
+	b.Annotations = map[string]string{annotationManualSync: "1"}
+	b.Spec.Backrest.URL = "http://backrest.invalid"
+	b.Spec.Source = v1alpha1.VolSyncSourceRef{Kind: "ReplicationSource", Name: "demo"}
+
+	vs := &unstructured.Unstructured{Object: map[string]any{
+		"apiVersion": volsync.Group + "/" + volsync.Version,
+		"kind":       "ReplicationSource",
+		"metadata":   map[string]any{"name": "demo", "namespace": "workload"},
+		"spec": map[string]any{
+			"restic":  map[string]any{"repository": "repo-secret"},
+			"trigger": map[string]any{"schedule": "0 * * * *"},
+		},
+		"status": map[string]any{"lastSyncTime": "2026-01-01T00:00:00Z"},
+	}}
+
+	sec := &corev1.Secret{}
+	sec.Namespace = "workload"
+	sec.Name = "repo-secret"
+	sec.Data = map[string][]byte{
+		"RESTIC_REPOSITORY": []byte("s3://bucket/repo"),
+		"RESTIC_PASSWORD":   []byte("pass"),
+	}
+
+	c := fake.NewClientBuilder().WithScheme(scheme).
+		WithStatusSubresource(&v1alpha1.BackrestVolSyncBinding{}).
+		WithObjects(b, vs, sec).
+		Build()
+
+	br := &fakeBackrestRepoClient{}
+	r := &BackrestVolSyncBindingReconciler{
+		Client: c,
+		Scheme: scheme,
+		BackrestClientFactory: func(string, backrest.Auth, backrest.Options) backrestRepoClient {
+			return br
+		},
+	}
+	req := ctrl.Request{NamespacedName: types.NamespacedName{Namespace: b.Namespace, Name: b.Name}}
+	vsKey := types.NamespacedName{Namespace: "workload", Name: "demo"}
+	getVS := func() *unstructured.Unstructured {
+		u := &unstructured.Unstructured{}
+		u.SetGroupVersionKind(vs.GroupVersionKind())
+		if err := c.Get(ctx, vsKey, u); err != nil {
+			t.Fatalf("get vs: %v", err)
+		}
+		return u
+	}
+	getBinding := func() *v1alpha1.BackrestVolSyncBinding {
+		var got v1alpha1.BackrestVolSyncBinding
+		if err := c.Get(ctx, req.NamespacedName, &got); err != nil {
+			t.Fatalf("get: %v", err)
+		}
+		return &got
+	}
+
+	if _, err := r.Reconcile(ctx, req); err != nil {
+		t.Fatalf("reconcile: %v", err)
+	}
+	got := getBinding()
+	if got.Status.ManualSync == nil || got.Status.ManualSync.Phase != manualSyncSyncing {
+		t.Fatalf("expected Syncing, got %#v", got.Status.ManualSync)
+	}
+	trigger := got.Status.ManualSync.Trigger
+	manual, _, _ := unstructured.NestedString(getVS().Object, "spec", "trigger", "manual")
+	if manual == "" || manual != trigger {
+		t.Fatalf("expected spec.trigger.manual=%q, got %q", trigger, manual)
+	}
+	if len(br.taskCalls) != 0 {
+		t.Fatalf("tasks must wait for the sync, got %v", br.taskCalls)
+	}
+
+	// VolSync completes the manual sync.
+	current := getVS()
+	_ = unstructured.SetNestedField(current.Object, trigger, "status", "lastManualSync")
+	_ = unstructured.SetNestedField(current.Object, "2026-01-01T00:10:00Z", "status", "lastSyncTime")
+	if err := c.Update(ctx, current); err != nil {
+		t.Fatalf("update vs: %v", err)
+	}
+	res, err := r.Reconcile(ctx, req)
+	if err != nil {
+		t.Fatalf("reconcile #2: %v", err)
+	}
+	if res.RequeueAfter != 0 {
+		t.Fatalf("expected no requeue after completion, got %v", res.RequeueAfter)
+	}
+	got = getBinding()
+	if got.Status.ManualSync.Phase != manualSyncCompleted || got.Status.ManualSync.CompletionTime == nil {
+		t.Fatalf("expected Completed, got %#v", got.Status.ManualSync)
+	}
+	if len(br.taskCalls) != 2 {
+		t.Fatalf("expected INDEX_SNAPSHOTS and STATS, got %v", br.taskCalls)
+	}
+	if _, found, _ := unstructured.NestedString(getVS().Object, "spec", "trigger", "manual"); found {
+		t.Fatalf("expected manual trigger removed so the schedule applies again")
+	}
+
+	// The same request is not repeated.
+	if _, err := r.Reconcile(ctx, req); err != nil {
+		t.Fatalf("reconcile #3: %v", err)
+	}
+	if len(br.taskCalls) != 2 {
+		t.Fatalf("expected no further tasks, got %v", br.taskCalls)
+	}
+}
+
+func TestBackrestVolSyncBindingReconcile_ManualSyncRejectedForDestination(t *testing.T) {
+	ctx := context.Background()
+	scheme := bindingTestScheme(t)
+
+	b := &v1alpha1.BackrestVolSyncBinding{}
+	b.Namespace = "workload"
+	b.Name = "b"
+	b.Annotations = map[string]string{annotationManualSync: "now"}
+	b.Spec.Backrest.URL = "http://backrest.invalid"
+	b.Spec.Source = v1alpha1.VolSyncSourceRef{Kind: "ReplicationDestination", Name: "demo"}
+
+	vs := &unstructured.Unstructured{Object: map[string]any{
+		"apiVersion": volsync.Group + "/" + volsync.Version,
+		"kind":       "ReplicationDestination",
+		"metadata":   map[string]any{"name": "demo", "namespace": "workload"},
+		"spec":       map[string]any{"restic": map[string]any{"repository": "repo-secret"}},
+	}}
+	sec := &corev1.Secret{}
+	sec.Namespace = "workload"
+	sec.Name = "repo-secret"
+	sec.Data = map[string][]byte{"RESTIC_REPOSITORY": []byte("s3://bucket/repo"), "RESTIC_PASSWORD": []byte("pass")}
+
+	c := fake.NewClientBuilder().WithScheme(scheme).
+		WithStatusSubresource(&v1alpha1.BackrestVolSyncBinding{}).
+		WithObjects(b, vs, sec).
+		Build()
+	r := &BackrestVolSyncBindingReconciler{
+		Client: c,
+		Scheme: scheme,
+		BackrestClientFactory: func(string, backrest.Auth, backrest.Options) backrestRepoClient {
+			return &fakeBackrestRepoClient{}
+		},
+	}
+	req := ctrl.Request{NamespacedName: types.NamespacedName{Namespace: b.Namespace, Name: b.Name}}
+	if _, err := r.Reconcile(ctx, req); err != nil {
+		t.Fatalf("reconcile: %v", err)
+	}
+	var got v1alpha1.BackrestVolSyncBinding
+	if err := c.Get(ctx, req.NamespacedName, &got); err != nil {
+		t.Fatalf("get: %v", err)
+	}
+	if got.Status.ManualSync == nil || got.Status.ManualSync.Phase != manualSyncFailed {
+		t.Fatalf("expected Failed, got %#v", got.Status.ManualSync)
+	}
+}
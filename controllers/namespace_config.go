@@ -0,0 +1,279 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/jogotcha/backrest-volsync-operator/api/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"k8s.io/client-go/tools/events"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+const (
+	conditionAccepted = "Accepted"
+
+	lockDefaultBackrest = "defaultBackrest"
+	lockPolicy          = "bindingGeneration.policy"
+	lockKinds           = "bindingGeneration.kinds"
+	lockRepoIDTemplate  = "bindingGeneration.repoIDTemplate"
+	lockDefaultRepo     = "bindingGeneration.defaultRepo"
+)
+
+// defaultRepoFields are the bindingGeneration.defaultRepo fields a namespace config can set, by JSON name.
+var defaultRepoFields = []string{
+	"idOverride", "autoUnlock", "autoInitialize", "triggerTasksOnSnapshot",
+	"extraFlags", "envAllowlist", "uriRewrites", "envFrom",
+}
+
+func isLockableField(f string) bool {
+	switch f {
+	case lockDefaultBackrest, lockPolicy, lockKinds, lockRepoIDTemplate, lockDefaultRepo:
+		return true
+	}
+	if name, ok := strings.CutPrefix(f, lockDefaultRepo+"."); ok {
+		for _, n := range defaultRepoFields {
+			if n == name {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// isLocked reports whether the cluster config locks a namespace config field; locking
+// bindingGeneration.defaultRepo locks each of its fields.
+func (s OperatorConfigSnapshot) isLocked(f string) bool {
+	if s.LockedFields[f] {
+		return true
+	}
+	return strings.HasPrefix(f, lockDefaultRepo+".") && s.LockedFields[lockDefaultRepo]
+}
+
+// selectNamespaceConfig returns the namespace config in effect: the oldest one, by creation time
+// and then name. The others are reported as conflicting.
+func selectNamespaceConfig(items []v1alpha1.BackrestVolSyncNamespaceConfig) *v1alpha1.BackrestVolSyncNamespaceConfig {
+	var selected *v1alpha1.BackrestVolSyncNamespaceConfig
+	for i := range items {
+		nc := &items[i]
+		if !nc.DeletionTimestamp.IsZero() {
+			continue
+		}
+		if selected == nil {
+			selected = nc
+			continue
+		}
+		at, bt := nc.CreationTimestamp, selected.CreationTimestamp
+		if at.Before(&bt) || (at.Equal(&bt) && nc.Name < selected.Name) {
+			selected = nc
+		}
+	}
+	return selected
+}
+
+// applyNamespaceConfig merges a namespace config over the cluster snapshot. Precedence, highest
+// first: unlocked namespace config fields, the cluster OperatorConfig, built-in defaults. A cluster
+// policy of Disabled is never overridden. It returns the locked fields the namespace config set.
+func applyNamespaceConfig(cfg OperatorConfigSnapshot, nc *v1alpha1.BackrestVolSyncNamespaceConfig) (OperatorConfigSnapshot, []string, error) {
+	out := cfg
+	var ignored []string
+	var errs field.ErrorList
+	spec := nc.Spec
+	specPath := field.NewPath("spec")
+	bgPath := specPath.Child("bindingGeneration")
+
+	apply := func(name string, set bool, fn func()) {
+		if !set {
+			return
+		}
+		if cfg.isLocked(name) {
+			ignored = append(ignored, name)
+			return
+		}
+		fn()
+	}
+
+	if spec.DefaultBackrest != nil {
+		errs = append(errs, validateBackrestConnection(*spec.DefaultBackrest, specPath.Child("defaultBackrest"))...)
+	}
+	apply(lockDefaultBackrest, spec.DefaultBackrest != nil, func() {
+		conn := spec.DefaultBackrest
+		out.DefaultBackrestURL = strings.TrimSpace(conn.URL)
+		out.DefaultBackrestAuthRef = nil
+		if conn.AuthRef != nil && conn.AuthRef.Name != "" {
+			out.DefaultBackrestAuthRef = &v1alpha1.SecretRef{Name: conn.AuthRef.Name}
+		}
+		out.DefaultBackrestInstanceRef = nil
+		if conn.InstanceRef != nil && conn.InstanceRef.Name != "" {
+			out.DefaultBackrestInstanceRef = &v1alpha1.InstanceRef{Name: conn.InstanceRef.Name}
+		}
+	})
+
+	bg := spec.BindingGeneration
+	policy, err := parseBindingPolicy(bg.Policy)
+	if err != nil {
+		errs = append(errs, field.Invalid(bgPath.Child("policy"), bg.Policy, "must be Disabled, Annotated or All"))
+	}
+	apply(lockPolicy, strings.TrimSpace(bg.Policy) != "", func() {
+		if cfg.BindingPolicy != BindingPolicyDisabled {
+			out.BindingPolicy = policy
+		}
+	})
+
+	kinds, err := parseVolSyncKinds(bg.Kinds)
+	if err != nil {
+		errs = append(errs, field.Invalid(bgPath.Child("kinds"), bg.Kinds, err.Error()))
+	}
+	apply(lockKinds, len(bg.Kinds) > 0, func() { out.AllowedVolSyncKinds = kinds })
+
+	if text := strings.TrimSpace(bg.RepoIDTemplate); text != "" {
+		tmpl, err := parseRepoIDTemplate(text)
+		if err != nil {
+			errs = append(errs, field.Invalid(bgPath.Child("repoIDTemplate"), bg.RepoIDTemplate, err.Error()))
+		}
+		apply(lockRepoIDTemplate, true, func() { out.RepoIDTemplate = tmpl })
+	}
+
+	repoPath := bgPath.Child("defaultRepo")
+	repo := bg.DefaultRepo
+	errs = append(errs, validateEnvFrom(repo.EnvFrom, repoPath.Child("envFrom"))...)
+	errs = append(errs, validateURIRewrites(repo.URIRewrites, repoPath.Child("uriRewrites"))...)
+	cfg.DefaultRepo.DeepCopyInto(&out.DefaultRepo)
+	dr := &out.DefaultRepo
+	apply(lockDefaultRepo+".idOverride", repo.IDOverride != "", func() { dr.IDOverride = repo.IDOverride })
+	apply(lockDefaultRepo+".autoUnlock", repo.AutoUnlock != nil, func() { dr.AutoUnlock = repo.AutoUnlock })
+	apply(lockDefaultRepo+".autoInitialize", repo.AutoInitialize != nil, func() { dr.AutoInitialize = repo.AutoInitialize })
+	apply(lockDefaultRepo+".triggerTasksOnSnapshot", repo.TriggerTasksOnSnapshot != nil, func() { dr.TriggerTasksOnSnapshot = repo.TriggerTasksOnSnapshot })
+	apply(lockDefaultRepo+".extraFlags", repo.ExtraFlags != nil, func() { dr.ExtraFlags = repo.ExtraFlags })
+	apply(lockDefaultRepo+".envAllowlist", repo.EnvAllowlist != nil, func() { dr.EnvAllowlist = repo.EnvAllowlist })
+	apply(lockDefaultRepo+".uriRewrites", repo.URIRewrites != nil, func() { dr.URIRewrites = repo.URIRewrites })
+	apply(lockDefaultRepo+".envFrom", repo.EnvFrom != nil, func() { dr.EnvFrom = repo.EnvFrom })
+
+	if len(errs) > 0 {
+		return cfg, ignored, errs.ToAggregate()
+	}
+	return out, ignored, nil
+}
+
+// LoadNamespaceConfig returns cfg with the BackrestVolSyncNamespaceConfig of namespace merged over
+// it. Without a namespace config cfg is returned unchanged; an invalid one is an error.
+func LoadNamespaceConfig(ctx context.Context, c client.Client, cfg OperatorConfigSnapshot, namespace string) (OperatorConfigSnapshot, error) {
+	var list v1alpha1.BackrestVolSyncNamespaceConfigList
+	if err := c.List(ctx, &list, client.InNamespace(namespace)); err != nil {
+		return cfg, err
+	}
+	nc := selectNamespaceConfig(list.Items)
+	if nc == nil {
+		return cfg, nil
+	}
+	merged, _, err := applyNamespaceConfig(cfg, nc)
+	if err != nil {
+		return cfg, fmt.Errorf("BackrestVolSyncNamespaceConfig %s/%s: %w", nc.Namespace, nc.Name, err)
+	}
+	return merged, nil
+}
+
+// NamespaceConfigReconciler reports in each BackrestVolSyncNamespaceConfig's status whether it is
+// in effect and which of its fields the cluster config locks.
+type NamespaceConfigReconciler struct {
+	client.Client
+	Scheme   *runtime.Scheme
+	Recorder events.EventRecorder
+
+	OperatorConfig types.NamespacedName
+}
+
+func (r *NamespaceConfigReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	var nc v1alpha1.BackrestVolSyncNamespaceConfig
+	if err := r.Get(ctx, req.NamespacedName, &nc); err != nil {
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+	if !nc.DeletionTimestamp.IsZero() {
+		return ctrl.Result{}, nil
+	}
+
+	cfg, err := LoadOperatorConfig(ctx, r.Client, r.OperatorConfig)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	var list v1alpha1.BackrestVolSyncNamespaceConfigList
+	if err := r.List(ctx, &list, client.InNamespace(nc.Namespace)); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	cond := metav1.Condition{
+		Type:               conditionAccepted,
+		Status:             metav1.ConditionTrue,
+		Reason:             "Applied",
+		Message:            "Namespace overrides are applied to auto-binding in this namespace",
+		ObservedGeneration: nc.Generation,
+		LastTransitionTime: metav1.Now(),
+	}
+	var ignored []string
+	if selected := selectNamespaceConfig(list.Items); selected == nil || selected.UID != nc.UID {
+		cond.Status = metav1.ConditionFalse
+		cond.Reason = "Conflict"
+		cond.Message = "Another BackrestVolSyncNamespaceConfig in this namespace is older and in effect"
+	} else if _, ignored, err = applyNamespaceConfig(cfg, &nc); err != nil {
+		cond.Status = metav1.ConditionFalse
+		cond.Reason = "InvalidSpec"
+		cond.Message = err.Error()
+	} else if len(ignored) > 0 {
+		cond.Reason = "LockedFieldsIgnored"
+		cond.Message = "Fields locked by the cluster config are ignored: " + strings.Join(ignored, ", ")
+	}
+
+	if !reflect.DeepEqual(nc.Status.IgnoredFields, ignored) && len(ignored) > 0 && r.Recorder != nil {
+		r.Recorder.Eventf(&nc, nil, corev1.EventTypeWarning, "LockedFieldsIgnored", "ApplyNamespaceConfig", "Fields locked by the cluster config are ignored: %s", strings.Join(ignored, ", "))
+	}
+	changed := meta.SetStatusCondition(&nc.Status.Conditions, cond)
+	if !reflect.DeepEqual(nc.Status.IgnoredFields, ignored) {
+		nc.Status.IgnoredFields = ignored
+		changed = true
+	}
+	if nc.Status.ObservedGeneration != nc.Generation {
+		nc.Status.ObservedGeneration = nc.Generation
+		changed = true
+	}
+	if !changed {
+		return ctrl.Result{}, nil
+	}
+	return ctrl.Result{}, client.IgnoreNotFound(r.Status().Update(ctx, &nc))
+}
+
+func (r *NamespaceConfigReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		Named("namespaceconfig").
+		// Every config of a namespace is re-evaluated, so deleting the one in effect promotes the next.
+		Watches(&v1alpha1.BackrestVolSyncNamespaceConfig{}, handler.EnqueueRequestsFromMapFunc(func(ctx context.Context, obj client.Object) []reconcile.Request {
+			return r.namespaceConfigRequests(ctx, client.InNamespace(obj.GetNamespace()))
+		})).
+		// Namespace configs are validated against the whole cluster spec, but not its status.
+		Watches(&v1alpha1.BackrestVolSyncOperatorConfig{}, handler.EnqueueRequestsFromMapFunc(func(ctx context.Context, _ client.Object) []reconcile.Request {
+			return r.namespaceConfigRequests(ctx)
+		}), builder.WithPredicates(operatorConfigChanged(r.OperatorConfig, func(spec *v1alpha1.BackrestVolSyncOperatorConfigSpec) any { return *spec }))).
+		Complete(r)
+}
+
+func (r *NamespaceConfigReconciler) namespaceConfigRequests(ctx context.Context, opts ...client.ListOption) []reconcile.Request {
+	var list v1alpha1.BackrestVolSyncNamespaceConfigList
+	if err := r.List(ctx, &list, opts...); err != nil {
+		return nil
+	}
+	reqs := make([]reconcile.Request, 0, len(list.Items))
+	for i := range list.Items {
+		reqs = append(reqs, reconcile.Request{NamespacedName: types.NamespacedName{Namespace: list.Items[i].Namespace, Name: list.Items[i].Name}})
+	}
+	return reqs
+}
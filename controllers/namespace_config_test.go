@@ -0,0 +1,200 @@
+package controllers
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/jogotcha/backrest-volsync-operator/api/v1alpha1"
+	"github.com/jogotcha/backrest-volsync-operator/pkg/volsync"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/ptr"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+func TestApplyNamespaceConfig_Precedence(t *testing.T) {
+	cluster := OperatorConfigSnapshot{
+		Found:               true,
+		BindingPolicy:       BindingPolicyAnnotated,
+		AllowedVolSyncKinds: map[string]bool{"ReplicationSource": true, "ReplicationDestination": true},
+		DefaultBackrestURL:  "http://cluster",
+		DefaultRepo: v1alpha1.BackrestRepoSpec{
+			ExtraFlags:             []string{"--cluster"},
+			TriggerTasksOnSnapshot: ptr.To(false),
+		},
+		LockedFields: map[string]bool{lockPolicy: true, lockDefaultRepo + ".extraFlags": true},
+	}
+	nc := &v1alpha1.BackrestVolSyncNamespaceConfig{}
+	nc.Spec.DefaultBackrest = &v1alpha1.BackrestConnection{InstanceRef: &v1alpha1.InstanceRef{Name: "team-a"}}
+	nc.Spec.BindingGeneration.Policy = string(BindingPolicyAll)
+	nc.Spec.BindingGeneration.Kinds = []string{"ReplicationSource"}
+	nc.Spec.BindingGeneration.DefaultRepo.ExtraFlags = []string{"--team"}
+	nc.Spec.BindingGeneration.DefaultRepo.TriggerTasksOnSnapshot = ptr.To(true)
+
+	got, ignored, err := applyNamespaceConfig(cluster, nc)
+	if err != nil {
+		t.Fatalf("apply: %v", err)
+	}
+	if got.DefaultBackrestURL != "" || got.DefaultBackrestInstanceRef == nil || got.DefaultBackrestInstanceRef.Name != "team-a" {
+		t.Fatalf("expected namespace defaultBackrest to replace the cluster one, got %#v", got.DefaultBackrestConnection())
+	}
+	if got.BindingPolicy != BindingPolicyAnnotated {
+		t.Fatalf("locked policy overridden: %q", got.BindingPolicy)
+	}
+	if got.IsVolSyncKindAllowed("ReplicationDestination") {
+		t.Fatalf("expected namespace kinds to apply")
+	}
+	if len(got.DefaultRepo.ExtraFlags) != 1 || got.DefaultRepo.ExtraFlags[0] != "--cluster" {
+		t.Fatalf("locked extraFlags overridden: %v", got.DefaultRepo.ExtraFlags)
+	}
+	if !ptr.Deref(got.DefaultRepo.TriggerTasksOnSnapshot, false) {
+		t.Fatalf("expected unlocked defaultRepo field to be merged")
+	}
+	if len(ignored) != 2 || ignored[0] != lockPolicy || ignored[1] != lockDefaultRepo+".extraFlags" {
+		t.Fatalf("unexpected ignored fields: %v", ignored)
+	}
+	if ptr.Deref(cluster.DefaultRepo.TriggerTasksOnSnapshot, true) {
+		t.Fatalf("cluster snapshot was mutated")
+	}
+
+	// Locking defaultRepo locks all of its fields.
+	cluster.LockedFields = map[string]bool{lockDefaultRepo: true}
+	if _, ignored, _ := applyNamespaceConfig(cluster, nc); len(ignored) != 2 {
+		t.Fatalf("expected both defaultRepo fields ignored, got %v", ignored)
+	}
+
+	// A cluster policy of Disabled is not overridden.
+	cluster.LockedFields = nil
+	cluster.BindingPolicy = BindingPolicyDisabled
+	if got, _, _ := applyNamespaceConfig(cluster, nc); got.BindingPolicy != BindingPolicyDisabled {
+		t.Fatalf("expected cluster Disabled to win, got %q", got.BindingPolicy)
+	}
+
+	invalid := nc.DeepCopy()
+	invalid.Spec.BindingGeneration.Policy = "Sometimes"
+	if _, _, err := applyNamespaceConfig(cluster, invalid); err == nil {
+		t.Fatalf("expected invalid policy error")
+	}
+}
+
+func TestNamespaceConfigReconcile_Status(t *testing.T) {
+	ctx := context.Background()
+	scheme := testScheme(t)
+
+	cfg := &v1alpha1.BackrestVolSyncOperatorConfig{}
+	cfg.Namespace = "backrest-volsync-operator"
+	cfg.Name = "backrest-volsync-operator"
+	cfg.Spec.BindingGeneration.Policy = string(BindingPolicyAll)
+	cfg.Spec.NamespaceOverrides.LockedFields = []string{lockDefaultBackrest}
+
+	older := &v1alpha1.BackrestVolSyncNamespaceConfig{}
+	older.Namespace = "team-a"
+	older.Name = "overrides"
+	older.UID = "older"
+	older.CreationTimestamp = metav1.NewTime(time.Now().Add(-time.Hour))
+	older.Spec.DefaultBackrest = &v1alpha1.BackrestConnection{URL: "http://team-a"}
+
+	newer := &v1alpha1.BackrestVolSyncNamespaceConfig{}
+	newer.Namespace = "team-a"
+	newer.Name = "more"
+	newer.UID = "newer"
+	newer.CreationTimestamp = metav1.Now()
+
+	c := fake.NewClientBuilder().WithScheme(scheme).
+		WithStatusSubresource(&v1alpha1.BackrestVolSyncNamespaceConfig{}).
+		WithObjects(cfg, older, newer).
+		Build()
+	r := &NamespaceConfigReconciler{Client: c, OperatorConfig: types.NamespacedName{Namespace: cfg.Namespace, Name: cfg.Name}}
+
+	for _, nc := range []*v1alpha1.BackrestVolSyncNamespaceConfig{older, newer} {
+		if _, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: types.NamespacedName{Namespace: nc.Namespace, Name: nc.Name}}); err != nil {
+			t.Fatalf("reconcile %s: %v", nc.Name, err)
+		}
+	}
+
+	var got v1alpha1.BackrestVolSyncNamespaceConfig
+	if err := c.Get(ctx, types.NamespacedName{Namespace: "team-a", Name: "overrides"}, &got); err != nil {
+		t.Fatalf("get: %v", err)
+	}
+	cond := meta.FindStatusCondition(got.Status.Conditions, conditionAccepted)
+	if cond == nil || cond.Status != metav1.ConditionTrue || cond.Reason != "LockedFieldsIgnored" {
+		t.Fatalf("expected Accepted=True/LockedFieldsIgnored, got %#v", cond)
+	}
+	if len(got.Status.IgnoredFields) != 1 || got.Status.IgnoredFields[0] != lockDefaultBackrest {
+		t.Fatalf("unexpected ignored fields: %v", got.Status.IgnoredFields)
+	}
+
+	if err := c.Get(ctx, types.NamespacedName{Namespace: "team-a", Name: "more"}, &got); err != nil {
+		t.Fatalf("get: %v", err)
+	}
+	if cond := meta.FindStatusCondition(got.Status.Conditions, conditionAccepted); cond == nil || cond.Reason != "Conflict" {
+		t.Fatalf("expected Accepted=False/Conflict, got %#v", cond)
+	}
+}
+
+func TestVolSyncAutoBindingReconcile_NamespaceConfigOverrides(t *testing.T) {
+	ctx := context.Background()
+	scheme := testScheme(t)
+
+	cfg := &v1alpha1.BackrestVolSyncOperatorConfig{}
+	cfg.Namespace = "backrest-volsync-operator"
+	cfg.Name = "backrest-volsync-operator"
+	cfg.Spec.BindingGeneration.Policy = string(BindingPolicyAnnotated)
+	cfg.Spec.DefaultBackrest.URL = "http://cluster"
+
+	nc := &v1alpha1.BackrestVolSyncNamespaceConfig{}
+	nc.Namespace = "team-a"
+	nc.Name = "overrides"
+	nc.Spec.DefaultBackrest = &v1alpha1.BackrestConnection{InstanceRef: &v1alpha1.InstanceRef{Name: "team-a"}}
+	nc.Spec.BindingGeneration.Policy = string(BindingPolicyAll)
+
+	vs := &unstructured.Unstructured{}
+	vs.SetGroupVersionKind(schema.GroupVersionKind{Group: volsync.Group, Version: volsync.Version, Kind: "ReplicationSource"})
+	vs.SetNamespace("team-a")
+	vs.SetName("demo")
+	vs.SetUID(types.UID("1111"))
+
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(cfg, nc, vs).Build()
+	r := &VolSyncAutoBindingReconciler{
+		Client:         c,
+		Scheme:         scheme,
+		OperatorConfig: types.NamespacedName{Namespace: cfg.Namespace, Name: cfg.Name},
+	}
+	if _, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: types.NamespacedName{Namespace: "team-a", Name: "demo"}}); err != nil {
+		t.Fatalf("reconcile: %v", err)
+	}
+
+	// The namespace policy All binds the unannotated object, using the namespace's Backrest instance.
+	var binding v1alpha1.BackrestVolSyncBinding
+	if err := c.Get(ctx, types.NamespacedName{Namespace: "team-a", Name: "bvsb-rs-demo"}, &binding); err != nil {
+		t.Fatalf("expected binding created: %v", err)
+	}
+	if binding.Spec.Backrest.URL != "" || binding.Spec.Backrest.InstanceRef == nil || binding.Spec.Backrest.InstanceRef.Name != "team-a" {
+		t.Fatalf("expected namespace Backrest instance, got %#v", binding.Spec.Backrest)
+	}
+
+	// Editing the namespace config reconciles the VolSync objects and the managed bindings of its
+	// namespace; user-managed bindings do not read it.
+	handMade := &v1alpha1.BackrestVolSyncBinding{}
+	handMade.Namespace = "team-a"
+	handMade.Name = "hand-made"
+	if err := c.Create(ctx, handMade); err != nil {
+		t.Fatalf("create binding: %v", err)
+	}
+	want := []reconcile.Request{{NamespacedName: types.NamespacedName{Namespace: "team-a", Name: "demo"}}}
+	if got := r.volSyncObjectsInNamespace(ctx, nc.Namespace); !reflect.DeepEqual(got, want) {
+		t.Fatalf("expected the VolSync object enqueued, got %v", got)
+	}
+	br := &BackrestVolSyncBindingReconciler{Client: c, Scheme: scheme}
+	want = []reconcile.Request{{NamespacedName: types.NamespacedName{Namespace: "team-a", Name: "bvsb-rs-demo"}}}
+	if got := br.managedBindingsInNamespace(ctx, nc.Namespace); !reflect.DeepEqual(got, want) {
+		t.Fatalf("expected the managed binding enqueued, got %v", got)
+	}
+}
@@ -4,10 +4,15 @@ import (
 	"context"
 	"fmt"
 	"strings"
+	"text/template"
 
 	"github.com/jogotcha/backrest-volsync-operator/api/v1alpha1"
+	"github.com/jogotcha/backrest-volsync-operator/pkg/volsync"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/validation/field"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
@@ -19,6 +24,15 @@ const (
 	BindingPolicyAll       BindingGenerationPolicy = "All"
 )
 
+// OrphanPolicy controls managed bindings whose VolSync object is no longer eligible for auto-binding.
+type OrphanPolicy string
+
+const (
+	OrphanPolicyKeep   OrphanPolicy = "Keep"
+	OrphanPolicyDetach OrphanPolicy = "Detach"
+	OrphanPolicyDelete OrphanPolicy = "Delete"
+)
+
 type OperatorConfigSnapshot struct {
 	Found bool
 
@@ -28,10 +42,79 @@ type OperatorConfigSnapshot struct {
 
 	AllowedVolSyncKinds map[string]bool
 
-	DefaultBackrestURL     string
-	DefaultBackrestAuthRef *v1alpha1.SecretRef
+	// NamespaceSelector and ObjectSelector are the parsed bindingGeneration selectors; nil selects everything.
+	NamespaceSelector labels.Selector
+	ObjectSelector    labels.Selector
+
+	OrphanPolicy OrphanPolicy
+
+	// AllowedOverrides are the VolSync override annotations honored for generated bindings.
+	AllowedOverrides map[string]bool
+
+	// DryRun makes auto-binding report changes instead of writing them.
+	DryRun bool
+
+	DefaultBackrestURL         string
+	DefaultBackrestAuthRef     *v1alpha1.SecretRef
+	DefaultBackrestInstanceRef *v1alpha1.InstanceRef
 
 	DefaultRepo v1alpha1.BackrestRepoSpec
+
+	// URIRewrites are the compiled spec.uriRewrites.
+	URIRewrites []uriRewriteRule
+
+	// RepoShareMatch is the validated repoSharing.match; RepoShareDisabled when unset.
+	RepoShareMatch          string
+	RemoveUnreferencedRepos bool
+
+	// LockedFields are the namespace config fields the cluster config locks (namespaceOverrides.lockedFields).
+	LockedFields map[string]bool
+
+	// Markers extracts VolSync completion markers per spec.completionMarker; nil uses the
+	// built-in rules of the object's API version.
+	Markers *volsync.MarkerExtractor
+
+	// RepoIDTemplate is the parsed bindingGeneration.repoIDTemplate; nil when unset.
+	RepoIDTemplate *template.Template
+	ClusterName    string
+}
+
+// parseBindingPolicy validates bindingGeneration.policy; empty means Disabled.
+func parseBindingPolicy(v string) (BindingGenerationPolicy, error) {
+	policy := strings.TrimSpace(v)
+	if policy == "" {
+		policy = string(BindingPolicyDisabled)
+	}
+	switch BindingGenerationPolicy(policy) {
+	case BindingPolicyDisabled, BindingPolicyAnnotated, BindingPolicyAll:
+		return BindingGenerationPolicy(policy), nil
+	default:
+		return "", fmt.Errorf("invalid bindingGeneration.policy %q", policy)
+	}
+}
+
+// parseVolSyncKinds validates bindingGeneration.kinds; empty allows both VolSync kinds.
+func parseVolSyncKinds(kinds []string) (map[string]bool, error) {
+	allowedKinds := map[string]bool{
+		"ReplicationSource":      false,
+		"ReplicationDestination": false,
+	}
+	if len(kinds) == 0 {
+		allowedKinds["ReplicationSource"] = true
+		allowedKinds["ReplicationDestination"] = true
+		return allowedKinds, nil
+	}
+	for _, k := range kinds {
+		k = strings.TrimSpace(k)
+		switch k {
+		case "ReplicationSource", "ReplicationDestination":
+			allowedKinds[k] = true
+		default:
+			return nil, fmt.Errorf("invalid bindingGeneration.kinds entry %q", k)
+		}
+	}
+	// Always populated, so len==0 ("allow all") is never used for a loaded config.
+	return allowedKinds, nil
 }
 
 func (s OperatorConfigSnapshot) IsVolSyncKindAllowed(kind string) bool {
@@ -41,16 +124,25 @@ func (s OperatorConfigSnapshot) IsVolSyncKindAllowed(kind string) bool {
 	return s.AllowedVolSyncKinds[kind]
 }
 
+// DefaultBackrestConnection returns the connection generated bindings should use.
+// A configured instanceRef takes precedence over url/authRef.
+func (s OperatorConfigSnapshot) DefaultBackrestConnection() v1alpha1.BackrestConnection {
+	if s.DefaultBackrestInstanceRef != nil {
+		return v1alpha1.BackrestConnection{InstanceRef: &v1alpha1.InstanceRef{Name: s.DefaultBackrestInstanceRef.Name}}
+	}
+	return v1alpha1.BackrestConnection{URL: s.DefaultBackrestURL, AuthRef: s.DefaultBackrestAuthRef}
+}
+
 func LoadOperatorConfig(ctx context.Context, c client.Client, nn types.NamespacedName) (OperatorConfigSnapshot, error) {
 	if nn.Name == "" || nn.Namespace == "" {
-		return OperatorConfigSnapshot{Found: false, BindingPolicy: BindingPolicyDisabled}, nil
+		return OperatorConfigSnapshot{Found: false, BindingPolicy: BindingPolicyDisabled, RepoShareMatch: RepoShareDisabled}, nil
 	}
 
 	var cfg v1alpha1.BackrestVolSyncOperatorConfig
 	if err := c.Get(ctx, nn, &cfg); err != nil {
 		if apierrors.IsNotFound(err) {
 			// Treat a missing OperatorConfig as a safety default: auto-binding disabled.
-			return OperatorConfigSnapshot{Found: false, BindingPolicy: BindingPolicyDisabled}, nil
+			return OperatorConfigSnapshot{Found: false, BindingPolicy: BindingPolicyDisabled, RepoShareMatch: RepoShareDisabled}, nil
 		}
 		return OperatorConfigSnapshot{}, err
 	}
@@ -58,45 +150,118 @@ func LoadOperatorConfig(ctx context.Context, c client.Client, nn types.Namespace
 	snap := OperatorConfigSnapshot{Found: true}
 	snap.Paused = cfg.Spec.Paused
 
-	policy := strings.TrimSpace(cfg.Spec.BindingGeneration.Policy)
-	if policy == "" {
-		policy = string(BindingPolicyDisabled)
+	policy, err := parseBindingPolicy(cfg.Spec.BindingGeneration.Policy)
+	if err != nil {
+		return OperatorConfigSnapshot{}, err
 	}
-	switch BindingGenerationPolicy(policy) {
-	case BindingPolicyDisabled, BindingPolicyAnnotated, BindingPolicyAll:
-		snap.BindingPolicy = BindingGenerationPolicy(policy)
-	default:
-		return OperatorConfigSnapshot{}, fmt.Errorf("invalid bindingGeneration.policy %q", policy)
+	snap.BindingPolicy = policy
+
+	allowedKinds, err := parseVolSyncKinds(cfg.Spec.BindingGeneration.Kinds)
+	if err != nil {
+		return OperatorConfigSnapshot{}, err
 	}
+	snap.AllowedVolSyncKinds = allowedKinds
 
-	// bindingGeneration.kinds defaults to allowing both VolSync kinds.
-	allowedKinds := map[string]bool{
-		"ReplicationSource":      false,
-		"ReplicationDestination": false,
+	if sel := cfg.Spec.BindingGeneration.NamespaceSelector; sel != nil {
+		parsed, err := metav1.LabelSelectorAsSelector(sel)
+		if err != nil {
+			return OperatorConfigSnapshot{}, fmt.Errorf("invalid bindingGeneration.namespaceSelector: %w", err)
+		}
+		snap.NamespaceSelector = parsed
 	}
-	if len(cfg.Spec.BindingGeneration.Kinds) == 0 {
-		allowedKinds["ReplicationSource"] = true
-		allowedKinds["ReplicationDestination"] = true
-	} else {
-		for _, k := range cfg.Spec.BindingGeneration.Kinds {
-			k = strings.TrimSpace(k)
-			switch k {
-			case "ReplicationSource", "ReplicationDestination":
-				allowedKinds[k] = true
-			default:
-				return OperatorConfigSnapshot{}, fmt.Errorf("invalid bindingGeneration.kinds entry %q", k)
-			}
+	if sel := cfg.Spec.BindingGeneration.ObjectSelector; sel != nil {
+		parsed, err := metav1.LabelSelectorAsSelector(sel)
+		if err != nil {
+			return OperatorConfigSnapshot{}, fmt.Errorf("invalid bindingGeneration.objectSelector: %w", err)
 		}
+		snap.ObjectSelector = parsed
+	}
+
+	switch orphan := OrphanPolicy(strings.TrimSpace(cfg.Spec.BindingGeneration.OrphanPolicy)); orphan {
+	case "":
+		snap.OrphanPolicy = OrphanPolicyKeep
+	case OrphanPolicyKeep, OrphanPolicyDetach, OrphanPolicyDelete:
+		snap.OrphanPolicy = orphan
+	default:
+		return OperatorConfigSnapshot{}, fmt.Errorf("invalid bindingGeneration.orphanPolicy %q", orphan)
+	}
+
+	snap.DryRun = cfg.Spec.BindingGeneration.DryRun
+
+	for _, o := range cfg.Spec.BindingGeneration.AllowedOverrides {
+		o = strings.TrimSpace(o)
+		if !isOverrideName(o) {
+			return OperatorConfigSnapshot{}, fmt.Errorf("invalid bindingGeneration.allowedOverrides entry %q", o)
+		}
+		if snap.AllowedOverrides == nil {
+			snap.AllowedOverrides = map[string]bool{}
+		}
+		snap.AllowedOverrides[o] = true
 	}
-	// Compact map so len==0 means "allow all" is never used; we always populate.
-	snap.AllowedVolSyncKinds = allowedKinds
 
 	snap.DefaultBackrestURL = strings.TrimSpace(cfg.Spec.DefaultBackrest.URL)
 	if cfg.Spec.DefaultBackrest.AuthRef != nil && cfg.Spec.DefaultBackrest.AuthRef.Name != "" {
 		snap.DefaultBackrestAuthRef = &v1alpha1.SecretRef{Name: cfg.Spec.DefaultBackrest.AuthRef.Name}
 	}
+	if cfg.Spec.DefaultBackrest.InstanceRef != nil && cfg.Spec.DefaultBackrest.InstanceRef.Name != "" {
+		snap.DefaultBackrestInstanceRef = &v1alpha1.InstanceRef{Name: cfg.Spec.DefaultBackrest.InstanceRef.Name}
+	}
 
 	// Copy defaults (preserving optional pointers/slices).
 	snap.DefaultRepo = cfg.Spec.BindingGeneration.DefaultRepo
+
+	rewrites, errs := compileURIRewrites(cfg.Spec.URIRewrites, field.NewPath("spec", "uriRewrites"))
+	if len(errs) > 0 {
+		return OperatorConfigSnapshot{}, errs.ToAggregate()
+	}
+	snap.URIRewrites = rewrites
+
+	if text := strings.TrimSpace(cfg.Spec.BindingGeneration.RepoIDTemplate); text != "" {
+		tmpl, err := parseRepoIDTemplate(text)
+		if err != nil {
+			return OperatorConfigSnapshot{}, fmt.Errorf("invalid bindingGeneration.repoIDTemplate: %w", err)
+		}
+		snap.RepoIDTemplate = tmpl
+	}
+	snap.ClusterName = strings.TrimSpace(cfg.Spec.ClusterName)
+
+	match := strings.TrimSpace(cfg.Spec.RepoSharing.Match)
+	switch match {
+	case "", RepoShareDisabled:
+		match = RepoShareDisabled
+	case RepoShareURI, RepoShareURIAndPassword:
+	default:
+		return OperatorConfigSnapshot{}, fmt.Errorf("invalid repoSharing.match %q", match)
+	}
+	snap.RepoShareMatch = match
+	snap.RemoveUnreferencedRepos = cfg.Spec.RepoSharing.RemoveUnreferencedRepos
+
+	for _, f := range cfg.Spec.NamespaceOverrides.LockedFields {
+		f = strings.TrimSpace(f)
+		if !isLockableField(f) {
+			return OperatorConfigSnapshot{}, fmt.Errorf("invalid namespaceOverrides.lockedFields entry %q", f)
+		}
+		if snap.LockedFields == nil {
+			snap.LockedFields = map[string]bool{}
+		}
+		snap.LockedFields[f] = true
+	}
+
+	if cm := cfg.Spec.CompletionMarker; len(cm.Rules) > 0 || len(cm.DestinationRules) > 0 || len(cm.SyncTime) > 0 {
+		markers, err := volsync.NewMarkerExtractor(markerRules(cm.Rules), markerRules(cm.DestinationRules), cm.SyncTime)
+		if err != nil {
+			return OperatorConfigSnapshot{}, fmt.Errorf("invalid completionMarker: %w", err)
+		}
+		snap.Markers = markers
+	}
 	return snap, nil
 }
+
+// markerRules converts the API marker rules to volsync.MarkerRule.
+func markerRules(in []v1alpha1.MarkerRule) []volsync.MarkerRule {
+	out := make([]volsync.MarkerRule, 0, len(in))
+	for _, r := range in {
+		out = append(out, volsync.MarkerRule{Name: r.Name, JSONPath: r.JSONPath})
+	}
+	return out
+}
@@ -0,0 +1,201 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+
+	"github.com/jogotcha/backrest-volsync-operator/api/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/events"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+)
+
+const (
+	conditionValid    = "Valid"
+	conditionPaused   = "Paused"
+	conditionDegraded = "Degraded"
+
+	// maxFailingBindingSample caps status.failingBindingSample.
+	maxFailingBindingSample = 10
+)
+
+// OperatorConfigReconciler reports the state of the operator in the OperatorConfig status: whether
+// the spec is valid, whether reconciliation is paused, binding health counts, the changes a dry
+// run holds back, and why auto-binding skipped VolSync objects.
+type OperatorConfigReconciler struct {
+	client.Client
+	Scheme   *runtime.Scheme
+	Recorder events.EventRecorder
+
+	OperatorConfig types.NamespacedName
+
+	// DryRun and Skips are shared with VolSyncAutoBindingReconciler; they may be nil.
+	DryRun *DryRunRecorder
+	Skips  *SkipRecorder
+}
+
+// bindingCounts summarizes bindings for the OperatorConfig status.
+type bindingCounts struct {
+	bindings, managed, ready, failing, instances int32
+	failingSample                                []string
+}
+
+func countBindings(bindings []v1alpha1.BackrestVolSyncBinding) bindingCounts {
+	var counts bindingCounts
+	instances := map[string]bool{}
+	addInstance := func(conn v1alpha1.BackrestConnection) {
+		switch {
+		case conn.InstanceRef != nil && conn.InstanceRef.Name != "":
+			instances["instance/"+conn.InstanceRef.Name] = true
+		case conn.URL != "":
+			instances["url/"+conn.URL] = true
+		}
+	}
+	var failing []string
+	for i := range bindings {
+		b := &bindings[i]
+		counts.bindings++
+		if b.Labels[labelManaged] == "true" {
+			counts.managed++
+		}
+		addInstance(b.Spec.Backrest)
+		for _, t := range b.Spec.Targets {
+			addInstance(t.BackrestConnection)
+		}
+		ready := meta.FindStatusCondition(b.Status.Conditions, conditionReady)
+		switch {
+		case ready == nil:
+		case ready.Status == metav1.ConditionTrue:
+			counts.ready++
+		case ready.Status == metav1.ConditionFalse && ready.Reason != "Paused":
+			counts.failing++
+			failing = append(failing, b.Namespace+"/"+b.Name)
+		}
+	}
+	counts.instances = int32(len(instances))
+	sort.Strings(failing)
+	if len(failing) > maxFailingBindingSample {
+		failing = failing[:maxFailingBindingSample]
+	}
+	counts.failingSample = failing
+	return counts
+}
+
+func (r *OperatorConfigReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	if req.NamespacedName != r.OperatorConfig {
+		return ctrl.Result{}, nil
+	}
+	var cfg v1alpha1.BackrestVolSyncOperatorConfig
+	if err := r.Get(ctx, req.NamespacedName, &cfg); err != nil {
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+
+	var bindings v1alpha1.BackrestVolSyncBindingList
+	if err := r.List(ctx, &bindings); err != nil {
+		return ctrl.Result{}, err
+	}
+	counts := countBindings(bindings.Items)
+
+	now := metav1.Now()
+	valid := metav1.Condition{Type: conditionValid, Status: metav1.ConditionTrue, Reason: "Valid", Message: "Spec is valid"}
+	_, loadErr := LoadOperatorConfig(ctx, r.Client, r.OperatorConfig)
+	if loadErr != nil {
+		valid.Status = metav1.ConditionFalse
+		valid.Reason = "InvalidSpec"
+		valid.Message = loadErr.Error()
+	}
+	paused := metav1.Condition{Type: conditionPaused, Status: metav1.ConditionFalse, Reason: "Running", Message: "Bindings and auto-binding are reconciled"}
+	if cfg.Spec.Paused {
+		paused.Status = metav1.ConditionTrue
+		paused.Reason = "Paused"
+		paused.Message = "Reconciliation is paused by spec.paused"
+	}
+	degraded := metav1.Condition{Type: conditionDegraded, Status: metav1.ConditionFalse, Reason: "AsExpected", Message: "No problems detected"}
+	volsyncCond := meta.FindStatusCondition(cfg.Status.Conditions, conditionVolSyncAvailable)
+	switch {
+	case loadErr != nil:
+		degraded.Status = metav1.ConditionTrue
+		degraded.Reason = "InvalidSpec"
+		degraded.Message = "The spec is invalid; see the Valid condition"
+	case volsyncCond != nil && volsyncCond.Status == metav1.ConditionFalse:
+		degraded.Status = metav1.ConditionTrue
+		degraded.Reason = "VolSyncUnavailable"
+		degraded.Message = "VolSync controllers are not running; see the VolSyncAvailable condition"
+	case counts.failing > 0:
+		degraded.Status = metav1.ConditionTrue
+		degraded.Reason = "BindingsFailing"
+		degraded.Message = fmt.Sprintf("%d of %d bindings are failing", counts.failing, counts.bindings)
+	}
+
+	if loadErr != nil && !meta.IsStatusConditionFalse(cfg.Status.Conditions, conditionValid) && r.Recorder != nil {
+		r.Recorder.Eventf(&cfg, nil, corev1.EventTypeWarning, "InvalidSpec", "ValidateConfig", "%s", loadErr.Error())
+	}
+
+	status := &cfg.DeepCopy().Status
+	for _, cond := range []metav1.Condition{valid, paused, degraded} {
+		cond.ObservedGeneration = cfg.Generation
+		cond.LastTransitionTime = now
+		meta.SetStatusCondition(&status.Conditions, cond)
+	}
+	status.ObservedGeneration = cfg.Generation
+	status.Bindings = counts.bindings
+	status.ManagedBindings = counts.managed
+	status.ReadyBindings = counts.ready
+	status.FailingBindings = counts.failing
+	status.BackrestInstances = counts.instances
+	status.FailingBindingSample = counts.failingSample
+	status.DryRun = nil
+	if r.DryRun != nil {
+		if cfg.Spec.BindingGeneration.DryRun {
+			status.DryRun = r.DryRun.Report()
+		} else {
+			r.DryRun.Reset()
+		}
+	}
+	status.AutoBindingSkips = nil
+	if r.Skips != nil {
+		status.AutoBindingSkips = r.Skips.Report()
+	}
+	if reflect.DeepEqual(*status, cfg.Status) {
+		return ctrl.Result{}, nil
+	}
+	cfg.Status = *status
+	// A conflict with the VolSync CRD gate, which also writes conditions here, is retried with backoff.
+	return ctrl.Result{}, client.IgnoreNotFound(r.Status().Update(ctx, &cfg))
+}
+
+func (r *OperatorConfigReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	isOperatorConfig := predicate.NewPredicateFuncs(func(obj client.Object) bool {
+		return obj.GetNamespace() == r.OperatorConfig.Namespace && obj.GetName() == r.OperatorConfig.Name
+	})
+	toConfig := handler.EnqueueRequestsFromMapFunc(func(context.Context, client.Object) []reconcile.Request {
+		if r.OperatorConfig.Name == "" || r.OperatorConfig.Namespace == "" {
+			return nil
+		}
+		return []reconcile.Request{{NamespacedName: r.OperatorConfig}}
+	})
+	b := ctrl.NewControllerManagedBy(mgr).
+		Named("operatorconfig").
+		For(&v1alpha1.BackrestVolSyncOperatorConfig{}, builder.WithPredicates(isOperatorConfig)).
+		// Binding status changes update the counts; the single config request coalesces bursts.
+		Watches(&v1alpha1.BackrestVolSyncBinding{}, toConfig)
+	if r.DryRun != nil {
+		b = b.WatchesRawSource(source.Channel(r.DryRun.Changed(), toConfig))
+	}
+	if r.Skips != nil {
+		b = b.WatchesRawSource(source.Channel(r.Skips.Changed(), toConfig))
+	}
+	return b.Complete(r)
+}
@@ -0,0 +1,120 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/jogotcha/backrest-volsync-operator/api/v1alpha1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/events"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func statusBinding(name string, managed bool, conn v1alpha1.BackrestConnection, readyStatus metav1.ConditionStatus, reason string) *v1alpha1.BackrestVolSyncBinding {
+	b := &v1alpha1.BackrestVolSyncBinding{}
+	b.Namespace = "workload"
+	b.Name = name
+	if managed {
+		b.Labels = map[string]string{labelManaged: "true"}
+	}
+	b.Spec.Backrest = conn
+	b.Spec.Source = v1alpha1.VolSyncSourceRef{Kind: "ReplicationSource", Name: name}
+	if readyStatus != "" {
+		b.Status.Conditions = []metav1.Condition{{Type: conditionReady, Status: readyStatus, Reason: reason, LastTransitionTime: metav1.Now()}}
+	}
+	return b
+}
+
+func TestOperatorConfigReconcile_Status(t *testing.T) {
+	ctx := context.Background()
+	scheme := testScheme(t)
+
+	cfg := &v1alpha1.BackrestVolSyncOperatorConfig{}
+	cfg.Namespace = "backrest-volsync-operator"
+	cfg.Name = "backrest-volsync-operator"
+	cfg.Generation = 3
+	cfg.Spec.BindingGeneration.Policy = string(BindingPolicyAll)
+
+	shared := v1alpha1.BackrestConnection{InstanceRef: &v1alpha1.InstanceRef{Name: "shared"}}
+	withTarget := statusBinding("with-target", false, v1alpha1.BackrestConnection{URL: "http://a"}, metav1.ConditionFalse, "BackrestApplyFailed")
+	withTarget.Spec.Targets = []v1alpha1.BackrestTarget{{Name: "dr", BackrestConnection: shared}}
+	objs := []client.Object{
+		cfg,
+		statusBinding("ready", true, shared, metav1.ConditionTrue, "Applied"),
+		statusBinding("paused", true, shared, metav1.ConditionFalse, "Paused"),
+		statusBinding("new", true, v1alpha1.BackrestConnection{URL: "http://b"}, "", ""),
+		statusBinding("broken", false, v1alpha1.BackrestConnection{URL: "http://a"}, metav1.ConditionFalse, "RepoSecretNotFound"),
+		withTarget,
+	}
+
+	c := fake.NewClientBuilder().WithScheme(scheme).
+		WithStatusSubresource(&v1alpha1.BackrestVolSyncOperatorConfig{}).
+		WithObjects(objs...).
+		Build()
+	recorder := events.NewFakeRecorder(10)
+	nn := types.NamespacedName{Namespace: cfg.Namespace, Name: cfg.Name}
+	r := &OperatorConfigReconciler{Client: c, Scheme: scheme, Recorder: recorder, OperatorConfig: nn}
+	if _, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: nn}); err != nil {
+		t.Fatalf("reconcile: %v", err)
+	}
+
+	var got v1alpha1.BackrestVolSyncOperatorConfig
+	if err := c.Get(ctx, nn, &got); err != nil {
+		t.Fatalf("get: %v", err)
+	}
+	st := got.Status
+	if st.ObservedGeneration != 3 || st.Bindings != 5 || st.ManagedBindings != 3 || st.ReadyBindings != 1 || st.FailingBindings != 2 || st.BackrestInstances != 3 {
+		t.Fatalf("unexpected counts: %+v", st)
+	}
+	if want := []string{"workload/broken", "workload/with-target"}; !reflect.DeepEqual(st.FailingBindingSample, want) {
+		t.Fatalf("expected sample %v, got %v", want, st.FailingBindingSample)
+	}
+	if !meta.IsStatusConditionTrue(st.Conditions, conditionValid) || !meta.IsStatusConditionFalse(st.Conditions, conditionPaused) {
+		t.Fatalf("unexpected conditions: %+v", st.Conditions)
+	}
+	if cond := meta.FindStatusCondition(st.Conditions, conditionDegraded); cond == nil || cond.Status != metav1.ConditionTrue || cond.Reason != "BindingsFailing" {
+		t.Fatalf("expected Degraded=True/BindingsFailing, got %+v", cond)
+	}
+
+	// An invalid, paused spec is reported without reconcile errors.
+	got.Spec.Paused = true
+	got.Spec.BindingGeneration.Policy = "Sometimes"
+	if err := c.Update(ctx, &got); err != nil {
+		t.Fatalf("update: %v", err)
+	}
+	if _, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: nn}); err != nil {
+		t.Fatalf("reconcile: %v", err)
+	}
+	if err := c.Get(ctx, nn, &got); err != nil {
+		t.Fatalf("get: %v", err)
+	}
+	if cond := meta.FindStatusCondition(got.Status.Conditions, conditionValid); cond == nil || cond.Status != metav1.ConditionFalse || cond.Message != `invalid bindingGeneration.policy "Sometimes"` {
+		t.Fatalf("expected Valid=False with the validation error, got %+v", cond)
+	}
+	if !meta.IsStatusConditionTrue(got.Status.Conditions, conditionPaused) {
+		t.Fatalf("expected Paused=True")
+	}
+	if cond := meta.FindStatusCondition(got.Status.Conditions, conditionDegraded); cond == nil || cond.Reason != "InvalidSpec" {
+		t.Fatalf("expected Degraded reason InvalidSpec, got %+v", cond)
+	}
+	if e := <-recorder.Events; e != `Warning InvalidSpec invalid bindingGeneration.policy "Sometimes"` {
+		t.Fatalf("unexpected event %q", e)
+	}
+}
+
+func TestCountBindings_CapsSample(t *testing.T) {
+	var bindings []v1alpha1.BackrestVolSyncBinding
+	for i := 0; i < maxFailingBindingSample+5; i++ {
+		bindings = append(bindings, *statusBinding(fmt.Sprintf("b-%02d", i), false, v1alpha1.BackrestConnection{URL: "http://a"}, metav1.ConditionFalse, "BackrestApplyFailed"))
+	}
+	counts := countBindings(bindings)
+	if counts.failing != int32(len(bindings)) || len(counts.failingSample) != maxFailingBindingSample || counts.failingSample[0] != "workload/b-00" {
+		t.Fatalf("unexpected counts: %+v", counts)
+	}
+}
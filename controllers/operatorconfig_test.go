@@ -5,6 +5,7 @@ import (
 	"testing"
 
 	"github.com/jogotcha/backrest-volsync-operator/api/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 	ctrlclient "sigs.k8s.io/controller-runtime/pkg/client"
@@ -91,6 +92,118 @@ func TestLoadOperatorConfig_DefaultsAndValidation(t *testing.T) {
 		}
 	})
 
+	t.Run("invalid repoSharing.match errors", func(t *testing.T) {
+		cfg := &v1alpha1.BackrestVolSyncOperatorConfig{}
+		cfg.Namespace = nn.Namespace
+		cfg.Name = nn.Name
+		cfg.Spec.RepoSharing.Match = "Bucket"
+		c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(cfg).Build()
+		_, err := LoadOperatorConfig(ctx, c, nn)
+		if err == nil {
+			t.Fatalf("expected error")
+		}
+	})
+
+	t.Run("completionMarker rules are compiled and validated", func(t *testing.T) {
+		cfg := &v1alpha1.BackrestVolSyncOperatorConfig{}
+		cfg.Namespace = nn.Namespace
+		cfg.Name = nn.Name
+		c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(cfg).Build()
+		snap, err := LoadOperatorConfig(ctx, c, nn)
+		if err != nil {
+			t.Fatalf("expected nil error, got %v", err)
+		}
+		if snap.Markers != nil {
+			t.Fatalf("expected built-in markers when unset")
+		}
+
+		cfg2 := cfg.DeepCopy()
+		cfg2.ResourceVersion = ""
+		cfg2.Spec.CompletionMarker.Rules = []v1alpha1.MarkerRule{{Name: "backup", JSONPath: "{.status.backup.id}"}}
+		c = fake.NewClientBuilder().WithScheme(scheme).WithObjects(cfg2).Build()
+		snap, err = LoadOperatorConfig(ctx, c, nn)
+		if err != nil || snap.Markers == nil {
+			t.Fatalf("expected compiled markers, got %v", err)
+		}
+
+		cfg3 := cfg.DeepCopy()
+		cfg3.ResourceVersion = ""
+		cfg3.Spec.CompletionMarker.SyncTime = []string{"{.status[}"}
+		c = fake.NewClientBuilder().WithScheme(scheme).WithObjects(cfg3).Build()
+		if _, err := LoadOperatorConfig(ctx, c, nn); err == nil {
+			t.Fatalf("expected error")
+		}
+	})
+
+	t.Run("invalid selector errors", func(t *testing.T) {
+		cfg := &v1alpha1.BackrestVolSyncOperatorConfig{}
+		cfg.Namespace = nn.Namespace
+		cfg.Name = nn.Name
+		cfg.Spec.BindingGeneration.NamespaceSelector = &metav1.LabelSelector{MatchExpressions: []metav1.LabelSelectorRequirement{
+			{Key: "team", Operator: metav1.LabelSelectorOpIn},
+		}}
+		c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(cfg).Build()
+		if _, err := LoadOperatorConfig(ctx, c, nn); err == nil {
+			t.Fatalf("expected error")
+		}
+	})
+
+	t.Run("orphanPolicy defaults to Keep and is validated", func(t *testing.T) {
+		cfg := &v1alpha1.BackrestVolSyncOperatorConfig{}
+		cfg.Namespace = nn.Namespace
+		cfg.Name = nn.Name
+		c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(cfg).Build()
+		snap, err := LoadOperatorConfig(ctx, c, nn)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if snap.OrphanPolicy != OrphanPolicyKeep {
+			t.Fatalf("expected Keep, got %q", snap.OrphanPolicy)
+		}
+
+		cfg2 := cfg.DeepCopy()
+		cfg2.ResourceVersion = ""
+		cfg2.Spec.BindingGeneration.OrphanPolicy = "Orphan"
+		c = fake.NewClientBuilder().WithScheme(scheme).WithObjects(cfg2).Build()
+		if _, err := LoadOperatorConfig(ctx, c, nn); err == nil {
+			t.Fatalf("expected error")
+		}
+	})
+
+	t.Run("invalid allowedOverrides entry errors", func(t *testing.T) {
+		cfg := &v1alpha1.BackrestVolSyncOperatorConfig{}
+		cfg.Namespace = nn.Namespace
+		cfg.Name = nn.Name
+		cfg.Spec.BindingGeneration.AllowedOverrides = []string{OverrideRepoID, "autoUnlock"}
+		c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(cfg).Build()
+		if _, err := LoadOperatorConfig(ctx, c, nn); err == nil {
+			t.Fatalf("expected error")
+		}
+	})
+
+	t.Run("namespaceOverrides.lockedFields are validated", func(t *testing.T) {
+		cfg := &v1alpha1.BackrestVolSyncOperatorConfig{}
+		cfg.Namespace = nn.Namespace
+		cfg.Name = nn.Name
+		cfg.Spec.NamespaceOverrides.LockedFields = []string{"defaultBackrest", "bindingGeneration.defaultRepo.extraFlags"}
+		c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(cfg).Build()
+		snap, err := LoadOperatorConfig(ctx, c, nn)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if !snap.LockedFields["defaultBackrest"] || !snap.LockedFields["bindingGeneration.defaultRepo.extraFlags"] {
+			t.Fatalf("expected locked fields, got %v", snap.LockedFields)
+		}
+
+		cfg2 := cfg.DeepCopy()
+		cfg2.ResourceVersion = ""
+		cfg2.Spec.NamespaceOverrides.LockedFields = []string{"paused"}
+		c = fake.NewClientBuilder().WithScheme(scheme).WithObjects(cfg2).Build()
+		if _, err := LoadOperatorConfig(ctx, c, nn); err == nil {
+			t.Fatalf("expected error")
+		}
+	})
+
 	t.Run("authRef only set when name present", func(t *testing.T) {
 		cfg := &v1alpha1.BackrestVolSyncOperatorConfig{}
 		cfg.Namespace = nn.Namespace
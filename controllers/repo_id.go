@@ -0,0 +1,156 @@
+package controllers
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"text/template"
+
+	"github.com/jogotcha/backrest-volsync-operator/api/v1alpha1"
+	"github.com/jogotcha/backrest-volsync-operator/pkg/volsync"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// annotationRepoIDMigrate lets a binding's repo ID follow spec.repo.idOverride removal or a changed
+// repoIDTemplate. Without it the ID stored in status.repoID is kept. It is removed once the binding
+// is registered under the migrated ID.
+const annotationRepoIDMigrate = "backrest.garethgeorge.com/repo-id-migrate"
+
+const maxRepoIDLength = 63
+
+var repoIDPattern = regexp.MustCompile(`^[A-Za-z0-9._-]+$`)
+
+var errEmptyRepoID = errors.New("repoIDTemplate rendered an empty ID")
+
+// repoIDTemplateData is the data available to bindingGeneration.repoIDTemplate.
+type repoIDTemplateData struct {
+	Namespace   string
+	Kind        string
+	Name        string
+	PVC         string
+	Cluster     string
+	Labels      map[string]string
+	Annotations map[string]string
+}
+
+var repoIDTemplateFuncs = template.FuncMap{
+	"lower":   strings.ToLower,
+	"replace": strings.ReplaceAll,
+}
+
+// parseRepoIDTemplate parses a repo ID template and checks it renders a valid ID for sample data.
+// The sample has no labels or annotations, so an empty result is tolerated here.
+func parseRepoIDTemplate(text string) (*template.Template, error) {
+	tmpl, err := template.New("repoID").Funcs(repoIDTemplateFuncs).Option("missingkey=zero").Parse(text)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := renderRepoID(tmpl, repoIDTemplateData{
+		Namespace: "namespace", Kind: "ReplicationSource", Name: "name", PVC: "pvc", Cluster: "cluster",
+	}); err != nil && !errors.Is(err, errEmptyRepoID) {
+		return nil, err
+	}
+	return tmpl, nil
+}
+
+// renderRepoID renders a repo ID. IDs longer than maxRepoIDLength are truncated with a stable hash
+// suffix, like desiredBindingName; characters Backrest does not accept are an error.
+func renderRepoID(tmpl *template.Template, data repoIDTemplateData) (string, error) {
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", err
+	}
+	id := strings.TrimSpace(buf.String())
+	if id == "" {
+		return "", errEmptyRepoID
+	}
+	if !repoIDPattern.MatchString(id) {
+		return "", fmt.Errorf("repoIDTemplate rendered %q; only letters, digits, '.', '_' and '-' are allowed", id)
+	}
+	if len(id) <= maxRepoIDLength {
+		return id, nil
+	}
+	sum := sha256.Sum256([]byte(id))
+	suffix := hex.EncodeToString(sum[:])[:8]
+	return id[:maxRepoIDLength-1-len(suffix)] + "-" + suffix, nil
+}
+
+func defaultRepoID(b *v1alpha1.BackrestVolSyncBinding) string {
+	return fmt.Sprintf("volsync-%s-%s-%s", b.Namespace, strings.ToLower(b.Spec.Source.Kind), b.Spec.Source.Name)
+}
+
+// bindingApplied reports whether the binding was registered in any Backrest target before.
+func bindingApplied(b *v1alpha1.BackrestVolSyncBinding) bool {
+	if b.Status.LastAppliedInputHash != "" {
+		return true
+	}
+	for i := range b.Status.Targets {
+		if b.Status.Targets[i].LastAppliedInputHash != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// resolveRepoID decides the binding's repo ID and stores it in status.repoID. spec.repo.idOverride
+// always wins. Otherwise a stored ID is kept, so a changed repoIDTemplate does not orphan the repo
+// registered under the old ID; bindings applied before status.repoID existed keep the default ID.
+// The migrate annotation drops the stored ID and resolves it again.
+func (r *BackrestVolSyncBindingReconciler) resolveRepoID(b *v1alpha1.BackrestVolSyncBinding, cfg OperatorConfigSnapshot, vsObj *unstructured.Unstructured) (bool, error) {
+	migrate := strings.TrimSpace(b.Annotations[annotationRepoIDMigrate]) == "true"
+	var id string
+	switch {
+	case b.Spec.Repo.IDOverride != "":
+		id = b.Spec.Repo.IDOverride
+	case b.Status.RepoID != "" && !migrate:
+		id = b.Status.RepoID
+	case bindingApplied(b) && !migrate:
+		id = defaultRepoID(b)
+	case b.Labels[labelManaged] == "true" && cfg.RepoIDTemplate != nil:
+		pvc, err := volsync.PVCName(vsObj)
+		if err != nil {
+			return false, err
+		}
+		id, err = renderRepoID(cfg.RepoIDTemplate, repoIDTemplateData{
+			Namespace:   b.Namespace,
+			Kind:        b.Spec.Source.Kind,
+			Name:        b.Spec.Source.Name,
+			PVC:         pvc,
+			Cluster:     cfg.ClusterName,
+			Labels:      vsObj.GetLabels(),
+			Annotations: vsObj.GetAnnotations(),
+		})
+		if err != nil {
+			return false, err
+		}
+	default:
+		id = defaultRepoID(b)
+	}
+	if b.Status.RepoID == id {
+		return false, nil
+	}
+	if b.Status.RepoID != "" && r.Recorder != nil {
+		r.Recorder.Eventf(b, nil, corev1.EventTypeNormal, "RepoIDChanged", "ResolveRepoID", "Backrest repo ID changed from %s to %s; the old repo stays registered in Backrest", b.Status.RepoID, id)
+	}
+	b.Status.RepoID = id
+	return true, nil
+}
+
+// finishRepoIDMigration removes the migrate annotation after the migrated ID was stored in status and
+// applied to every target, so the ID stays fixed again and a later template change does not move the
+// repo without another migration.
+func (r *BackrestVolSyncBindingReconciler) finishRepoIDMigration(ctx context.Context, b *v1alpha1.BackrestVolSyncBinding) error {
+	if _, ok := b.Annotations[annotationRepoIDMigrate]; !ok || b.Status.RepoID == "" {
+		return nil
+	}
+	original := b.DeepCopy()
+	delete(b.Annotations, annotationRepoIDMigrate)
+	return client.IgnoreNotFound(r.Patch(ctx, b, client.MergeFrom(original)))
+}
@@ -0,0 +1,152 @@
+package controllers
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/jogotcha/backrest-volsync-operator/api/v1alpha1"
+	"github.com/jogotcha/backrest-volsync-operator/pkg/volsync"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestRenderRepoID(t *testing.T) {
+	data := repoIDTemplateData{
+		Namespace: "apps",
+		Kind:      "ReplicationSource",
+		Name:      "db",
+		PVC:       "data-db-0",
+		Cluster:   "prod",
+		Labels:    map[string]string{"team": "payments"},
+	}
+	tests := []struct {
+		name    string
+		tmpl    string
+		want    string
+		wantErr bool
+	}{
+		{name: "fields", tmpl: "{{ .Cluster }}-{{ .Namespace }}-{{ .PVC }}", want: "prod-apps-data-db-0"},
+		{name: "labels and funcs", tmpl: `{{ .Labels.team }}.{{ lower .Kind }}`, want: "payments.replicationsource"},
+		{name: "missing label is empty", tmpl: `{{ .Labels.missing }}{{ .Name }}`, want: "db"},
+		{name: "invalid charset", tmpl: "{{ .Namespace }}/{{ .Name }}", wantErr: true},
+		{name: "empty", tmpl: "{{ .Labels.missing }}", wantErr: true},
+		{name: "overflow", tmpl: strings.Repeat("a", 80), want: strings.Repeat("a", 54) + "-"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tmpl, err := parseRepoIDTemplate(tt.tmpl)
+			if err != nil {
+				if !tt.wantErr {
+					t.Fatalf("parse: %v", err)
+				}
+				return
+			}
+			got, err := renderRepoID(tmpl, data)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("expected error, got %q", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("render: %v", err)
+			}
+			if tt.name == "overflow" {
+				if len(got) != maxRepoIDLength || !strings.HasPrefix(got, tt.want) {
+					t.Fatalf("expected truncated ID with hash suffix, got %q", got)
+				}
+				again, _ := renderRepoID(tmpl, data)
+				if again != got {
+					t.Fatalf("expected stable suffix, got %q and %q", got, again)
+				}
+				return
+			}
+			if got != tt.want {
+				t.Fatalf("got %q want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestResolveRepoID_PinsResolvedID(t *testing.T) {
+	tmplV1, err := parseRepoIDTemplate("{{ .Cluster }}-{{ .PVC }}")
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	tmplV2, err := parseRepoIDTemplate("{{ .Cluster }}-{{ .Namespace }}-{{ .PVC }}")
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	vs := &unstructured.Unstructured{Object: map[string]any{
+		"apiVersion": volsync.Group + "/" + volsync.Version,
+		"kind":       "ReplicationSource",
+		"metadata":   map[string]any{"name": "db", "namespace": "apps"},
+		"spec":       map[string]any{"sourcePVC": "data"},
+	}}
+	b := &v1alpha1.BackrestVolSyncBinding{}
+	b.Namespace = "apps"
+	b.Name = "bvsb-rs-db"
+	b.Labels = map[string]string{labelManaged: "true"}
+	b.Spec.Source = v1alpha1.VolSyncSourceRef{Kind: "ReplicationSource", Name: "db"}
+
+	c := fake.NewClientBuilder().WithScheme(bindingTestScheme(t)).WithStatusSubresource(b).WithObjects(b).Build()
+	r := &BackrestVolSyncBindingReconciler{Client: c}
+	cfg := OperatorConfigSnapshot{RepoIDTemplate: tmplV1, ClusterName: "prod"}
+	if changed, err := r.resolveRepoID(b, cfg, vs); err != nil || !changed {
+		t.Fatalf("resolve: changed=%v err=%v", changed, err)
+	}
+	if b.Status.RepoID != "prod-data" || desiredRepoID(b) != "prod-data" {
+		t.Fatalf("expected templated ID, got %q", b.Status.RepoID)
+	}
+
+	// A template change keeps the stored ID.
+	cfg.RepoIDTemplate = tmplV2
+	if changed, _ := r.resolveRepoID(b, cfg, vs); changed || b.Status.RepoID != "prod-data" {
+		t.Fatalf("expected pinned ID, got %q", b.Status.RepoID)
+	}
+
+	// The migrate annotation follows the new template.
+	b.Annotations = map[string]string{annotationRepoIDMigrate: "true"}
+	status := b.Status
+	if err := c.Update(context.Background(), b); err != nil {
+		t.Fatalf("update binding: %v", err)
+	}
+	b.Status = status
+	if changed, _ := r.resolveRepoID(b, cfg, vs); !changed || b.Status.RepoID != "prod-apps-data" {
+		t.Fatalf("expected migrated ID, got %q", b.Status.RepoID)
+	}
+
+	// Once applied, the annotation is removed and the migrated ID is pinned again.
+	if err := c.Status().Update(context.Background(), b); err != nil {
+		t.Fatalf("update status: %v", err)
+	}
+	if err := r.finishRepoIDMigration(context.Background(), b); err != nil {
+		t.Fatalf("finish migration: %v", err)
+	}
+	var stored v1alpha1.BackrestVolSyncBinding
+	if err := c.Get(context.Background(), client.ObjectKeyFromObject(b), &stored); err != nil {
+		t.Fatalf("get binding: %v", err)
+	}
+	if _, ok := stored.Annotations[annotationRepoIDMigrate]; ok {
+		t.Fatalf("expected the migrate annotation removed, got %v", stored.Annotations)
+	}
+	cfg.RepoIDTemplate = tmplV1
+	if changed, _ := r.resolveRepoID(b, cfg, vs); changed || b.Status.RepoID != "prod-apps-data" {
+		t.Fatalf("expected the migrated ID pinned, got %q", b.Status.RepoID)
+	}
+
+	// Bindings applied before status.repoID existed keep the default ID.
+	legacy := &v1alpha1.BackrestVolSyncBinding{}
+	legacy.Namespace = "apps"
+	legacy.Labels = map[string]string{labelManaged: "true"}
+	legacy.Spec.Source = v1alpha1.VolSyncSourceRef{Kind: "ReplicationSource", Name: "db"}
+	legacy.Status.LastAppliedInputHash = "applied"
+	if _, err := r.resolveRepoID(legacy, cfg, vs); err != nil {
+		t.Fatalf("resolve: %v", err)
+	}
+	if legacy.Status.RepoID != "volsync-apps-replicationsource-db" {
+		t.Fatalf("expected legacy default ID, got %q", legacy.Status.RepoID)
+	}
+}
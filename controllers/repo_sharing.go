@@ -0,0 +1,277 @@
+package controllers
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+
+	"connectrpc.com/connect"
+	"github.com/jogotcha/backrest-volsync-operator/api/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+const (
+	RepoShareDisabled       = "Disabled"
+	RepoShareURI            = "URI"
+	RepoShareURIAndPassword = "URIAndPassword"
+
+	// finalizerRepoCleanup keeps a binding until its repo was removed from Backrest, or another
+	// binding still references the repo ID. Only set while repoSharing.removeUnreferencedRepos is on.
+	finalizerRepoCleanup = "backrest.garethgeorge.com/repo-cleanup"
+
+	indexRepoID       = "status.repoID"
+	indexRepoShareKey = "status.repoShareKey"
+
+	reasonRepoShared = "RepoShared"
+
+	// credentialDigestShared marks a target where another binding of the share group registered the
+	// repo. Its credentials are validated before this binding registers the repo there itself.
+	credentialDigestShared = "shared"
+)
+
+// repoShareKey returns the digest that groups bindings of the same restic repository, or "" when
+// sharing is disabled. Unlike repoContentDigest it is not keyed with the binding UID, because
+// equal keys across bindings are the point; it is keyed with the operator's input hash key instead.
+func repoShareKey(match string, uri, password string, key []byte) string {
+	if match != RepoShareURI && match != RepoShareURIAndPassword {
+		return ""
+	}
+	mac := hmac.New(sha256.New, key)
+	_, _ = mac.Write([]byte(match))
+	_, _ = mac.Write([]byte{0})
+	_, _ = mac.Write([]byte(uri))
+	if match == RepoShareURIAndPassword {
+		_, _ = mac.Write([]byte{0})
+		_, _ = mac.Write([]byte(password))
+	}
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// shareRepo groups the binding with the other bindings of the same restic repository. The oldest
+// binding of a group owns the repo ID and the others adopt it, so Backrest indexes the repository
+// once. Bindings with spec.repo.idOverride keep their own ID. status.repoReferences counts the
+// bindings using the resulting ID.
+func (r *BackrestVolSyncBindingReconciler) shareRepo(ctx context.Context, b *v1alpha1.BackrestVolSyncBinding, cfg OperatorConfigSnapshot, uri, password string) (bool, error) {
+	key := repoShareKey(cfg.RepoShareMatch, uri, password, r.InputHashKey)
+	changed := false
+	if b.Status.RepoShareKey != key {
+		b.Status.RepoShareKey = key
+		changed = true
+	}
+	if key == "" {
+		if b.Status.RepoReferences != 0 {
+			b.Status.RepoReferences = 0
+			changed = true
+		}
+		return changed, nil
+	}
+
+	if b.Spec.Repo.IDOverride == "" {
+		var group v1alpha1.BackrestVolSyncBindingList
+		if err := r.List(ctx, &group, client.MatchingFields{indexRepoShareKey: key}); err != nil {
+			return changed, err
+		}
+		owner := b
+		for i := range group.Items {
+			m := &group.Items[i]
+			if m.UID == b.UID || !m.DeletionTimestamp.IsZero() || m.Status.RepoID == "" {
+				continue
+			}
+			if olderBinding(m, owner) {
+				owner = m
+			}
+		}
+		if id := desiredRepoID(owner); owner != b && id != b.Status.RepoID {
+			if r.Recorder != nil {
+				r.Recorder.Eventf(b, nil, corev1.EventTypeNormal, "RepoShared", "ShareRepo", "Sharing Backrest repo %s with binding %s/%s", id, owner.Namespace, owner.Name)
+			}
+			b.Status.RepoID = id
+			changed = true
+		}
+	}
+
+	refs, err := r.repoReferences(ctx, b, desiredRepoID(b))
+	if err != nil {
+		return changed, err
+	}
+	if n := int32(refs + 1); b.Status.RepoReferences != n {
+		b.Status.RepoReferences = n
+		changed = true
+	}
+	return changed, nil
+}
+
+// repoRegistrar returns the binding that registers repoID in the Backrest at backrestURL: the oldest
+// live binding of the share group that uses repoID and targets that Backrest. Only the registrar
+// calls AddRepo, so members whose password (under match=URI), env or flags differ do not overwrite
+// each other's registration.
+func (r *BackrestVolSyncBindingReconciler) repoRegistrar(ctx context.Context, b *v1alpha1.BackrestVolSyncBinding, repoID, backrestURL string) (*v1alpha1.BackrestVolSyncBinding, error) {
+	if b.Status.RepoShareKey == "" {
+		return b, nil
+	}
+	var group v1alpha1.BackrestVolSyncBindingList
+	if err := r.List(ctx, &group, client.MatchingFields{indexRepoShareKey: b.Status.RepoShareKey}); err != nil {
+		return nil, err
+	}
+	registrar := b
+	for i := range group.Items {
+		m := &group.Items[i]
+		if m.UID == b.UID || !m.DeletionTimestamp.IsZero() || desiredRepoID(m) != repoID || !olderBinding(m, registrar) {
+			continue
+		}
+		for _, t := range effectiveTargets(m) {
+			if url, _, err := r.resolveBackrestURL(ctx, t.Connection); err == nil && url == backrestURL {
+				registrar = m
+				break
+			}
+		}
+	}
+	return registrar, nil
+}
+
+// setRepoShared reports a target whose repo is registered by another binding of the share group.
+func setRepoShared(b *v1alpha1.BackrestVolSyncBinding, ts *v1alpha1.BackrestTargetStatus, registrar *v1alpha1.BackrestVolSyncBinding) bool {
+	changed := ts.LastAppliedInputHash != "" || ts.LastAppliedCredentialDigest != credentialDigestShared || ts.LastErrorHash != ""
+	ts.LastAppliedInputHash = ""
+	ts.LastAppliedCredentialDigest = credentialDigestShared
+	ts.LastErrorHash = ""
+	if meta.SetStatusCondition(&ts.Conditions, metav1.Condition{
+		Type:               conditionReady,
+		Status:             metav1.ConditionTrue,
+		Reason:             reasonRepoShared,
+		Message:            fmt.Sprintf("Repository registered in Backrest by binding %s/%s", registrar.Namespace, registrar.Name),
+		ObservedGeneration: b.Generation,
+		LastTransitionTime: metav1.Now(),
+	}) {
+		changed = true
+	}
+	return changed
+}
+
+// olderBinding orders bindings by creation time, then namespace/name, so every member of a group
+// picks the same owner.
+func olderBinding(a, b *v1alpha1.BackrestVolSyncBinding) bool {
+	at, bt := a.CreationTimestamp, b.CreationTimestamp
+	if !at.Equal(&bt) {
+		return at.Before(&bt)
+	}
+	if a.Namespace != b.Namespace {
+		return a.Namespace < b.Namespace
+	}
+	return a.Name < b.Name
+}
+
+// repoReferences counts the other live bindings that use repoID.
+func (r *BackrestVolSyncBindingReconciler) repoReferences(ctx context.Context, b *v1alpha1.BackrestVolSyncBinding, repoID string) (int, error) {
+	var users v1alpha1.BackrestVolSyncBindingList
+	if err := r.List(ctx, &users, client.MatchingFields{indexRepoID: repoID}); err != nil {
+		return 0, err
+	}
+	n := 0
+	for i := range users.Items {
+		if users.Items[i].UID != b.UID && users.Items[i].DeletionTimestamp.IsZero() {
+			n++
+		}
+	}
+	return n, nil
+}
+
+// ensureCleanupFinalizer adds or removes the repo cleanup finalizer to match the operator config.
+// It runs before any status change because Update overwrites the in-memory object.
+func (r *BackrestVolSyncBindingReconciler) ensureCleanupFinalizer(ctx context.Context, b *v1alpha1.BackrestVolSyncBinding, cfg OperatorConfigSnapshot) error {
+	var changed bool
+	if cfg.RemoveUnreferencedRepos {
+		changed = controllerutil.AddFinalizer(b, finalizerRepoCleanup)
+	} else {
+		changed = controllerutil.RemoveFinalizer(b, finalizerRepoCleanup)
+	}
+	if !changed {
+		return nil
+	}
+	return r.Update(ctx, b)
+}
+
+// finalizeBinding removes the binding's repo from every Backrest target when no other binding uses
+// the repo ID any more, then releases the finalizer. Targets whose BackrestInstance or auth Secret is
+// gone are skipped so deletion cannot get stuck on them.
+func (r *BackrestVolSyncBindingReconciler) finalizeBinding(ctx context.Context, b *v1alpha1.BackrestVolSyncBinding, cfg OperatorConfigSnapshot) (ctrl.Result, error) {
+	if !controllerutil.ContainsFinalizer(b, finalizerRepoCleanup) {
+		return ctrl.Result{}, nil
+	}
+	logger := log.FromContext(ctx)
+	repoID := desiredRepoID(b)
+
+	if cfg.RemoveUnreferencedRepos && bindingApplied(b) {
+		refs, err := r.repoReferences(ctx, b, repoID)
+		if err != nil {
+			return ctrl.Result{}, err
+		}
+		if refs > 0 {
+			logger.Info("Backrest repo still referenced; keeping it", "repoID", repoID, "references", refs)
+		} else {
+			for _, target := range effectiveTargets(b) {
+				if err := r.removeRepoFromTarget(ctx, b, target, repoID); err != nil {
+					errHash := hashString(err.Error())
+					if r.Recorder != nil {
+						r.Recorder.Eventf(b, nil, corev1.EventTypeWarning, "BackrestRemoveRepoFailed", "RemoveRepository", "Removing repo from Backrest target %s failed (errorHash=%s)", target.Name, errHash)
+					}
+					return ctrl.Result{}, &sanitizedReconcileError{reason: "BackrestRemoveRepoFailed", errorHash: errHash}
+				}
+			}
+		}
+	}
+
+	controllerutil.RemoveFinalizer(b, finalizerRepoCleanup)
+	return ctrl.Result{}, client.IgnoreNotFound(r.Update(ctx, b))
+}
+
+func (r *BackrestVolSyncBindingReconciler) removeRepoFromTarget(ctx context.Context, b *v1alpha1.BackrestVolSyncBinding, target bindingTarget, repoID string) error {
+	skip := func(err error) error {
+		if !apierrors.IsNotFound(err) {
+			return err
+		}
+		if r.Recorder != nil {
+			r.Recorder.Eventf(b, nil, corev1.EventTypeWarning, "RepoCleanupSkipped", "RemoveRepository", "Backrest target %s is not resolvable; repo %s was not removed from it", target.Name, repoID)
+		}
+		return nil
+	}
+	backrestURL, instance, err := r.resolveBackrestURL(ctx, target.Connection)
+	if err != nil {
+		return skip(err)
+	}
+	conn, err := r.loadBackrestConnection(ctx, b.Namespace, target.Connection, backrestURL, instance)
+	if err != nil {
+		return skip(err)
+	}
+	brClient, err := r.newBackrestClient(conn)
+	if err != nil {
+		return err
+	}
+	if err := brClient.RemoveRepo(ctx, repoID); err != nil && !isRepoNotFoundError(err) {
+		return err
+	}
+	log.FromContext(ctx).Info("Backrest repo removed", "repoID", repoID, "target", target.Name)
+	if r.Recorder != nil {
+		r.Recorder.Eventf(b, nil, corev1.EventTypeNormal, "RepoRemoved", "RemoveRepository", "Repo %s removed from Backrest target %s", repoID, target.Name)
+	}
+	return nil
+}
+
+func isRepoNotFoundError(err error) bool {
+	var cerr *connect.Error
+	if errors.As(err, &cerr) && cerr.Code() == connect.CodeNotFound {
+		return true
+	}
+	return strings.Contains(strings.ToLower(err.Error()), "not found")
+}
@@ -0,0 +1,243 @@
+package controllers
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/jogotcha/backrest-volsync-operator/api/v1alpha1"
+	"github.com/jogotcha/backrest-volsync-operator/pkg/backrest"
+	"github.com/jogotcha/backrest-volsync-operator/pkg/volsync"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+func sharingTestClient(t *testing.T, objs ...client.Object) client.Client {
+	t.Helper()
+	return fake.NewClientBuilder().WithScheme(bindingTestScheme(t)).
+		WithStatusSubresource(&v1alpha1.BackrestVolSyncBinding{}).
+		WithObjects(objs...).
+		WithIndex(&v1alpha1.BackrestVolSyncBinding{}, indexRepoID, func(obj client.Object) []string {
+			return []string{desiredRepoID(obj.(*v1alpha1.BackrestVolSyncBinding))}
+		}).
+		WithIndex(&v1alpha1.BackrestVolSyncBinding{}, indexRepoShareKey, func(obj client.Object) []string {
+			if key := obj.(*v1alpha1.BackrestVolSyncBinding).Status.RepoShareKey; key != "" {
+				return []string{key}
+			}
+			return nil
+		}).
+		Build()
+}
+
+func sharingTestBinding(namespace, kind, name string, created time.Time) *v1alpha1.BackrestVolSyncBinding {
+	b := &v1alpha1.BackrestVolSyncBinding{}
+	b.Namespace = namespace
+	b.Name = name
+	b.UID = types.UID(namespace + "/" + name)
+	b.CreationTimestamp = metav1.NewTime(created)
+	b.Spec.Source = v1alpha1.VolSyncSourceRef{Kind: kind, Name: name}
+	b.Spec.Backrest.URL = "http://backrest.invalid"
+	b.Status.RepoID = defaultRepoID(b)
+	return b
+}
+
+func TestRepoShareKey(t *testing.T) {
+	key := []byte("k")
+	if repoShareKey(RepoShareDisabled, "s3:bucket/db", "pw", key) != "" {
+		t.Fatalf("expected no key when sharing is disabled")
+	}
+	uri := repoShareKey(RepoShareURI, "s3:bucket/db", "pw", key)
+	if uri == "" || uri != repoShareKey(RepoShareURI, "s3:bucket/db", "other", key) {
+		t.Fatalf("URI match must ignore the password")
+	}
+	if repoShareKey(RepoShareURIAndPassword, "s3:bucket/db", "pw", key) == repoShareKey(RepoShareURIAndPassword, "s3:bucket/db", "other", key) {
+		t.Fatalf("URIAndPassword match must include the password")
+	}
+	if uri == repoShareKey(RepoShareURI, "s3:bucket/db", "pw", []byte("other")) {
+		t.Fatalf("expected the key to depend on the input hash key")
+	}
+}
+
+func TestShareRepo_SourceAndDestinationShareOneRepoID(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+	src := sharingTestBinding("prod", "ReplicationSource", "db", now.Add(-time.Hour))
+	dst := sharingTestBinding("restore", "ReplicationDestination", "db", now)
+
+	c := sharingTestClient(t, src, dst)
+	r := &BackrestVolSyncBindingReconciler{Client: c}
+	cfg := OperatorConfigSnapshot{RepoShareMatch: RepoShareURI}
+
+	for _, b := range []*v1alpha1.BackrestVolSyncBinding{src, dst} {
+		if _, err := r.shareRepo(ctx, b, cfg, "s3:bucket/db", "pw"); err != nil {
+			t.Fatalf("shareRepo %s: %v", b.Name, err)
+		}
+		if err := c.Status().Update(ctx, b); err != nil {
+			t.Fatalf("update status: %v", err)
+		}
+	}
+	if dst.Status.RepoID != src.Status.RepoID {
+		t.Fatalf("expected destination to adopt %q, got %q", src.Status.RepoID, dst.Status.RepoID)
+	}
+	if dst.Status.RepoReferences != 2 {
+		t.Fatalf("expected 2 references, got %d", dst.Status.RepoReferences)
+	}
+
+	// The owner keeps its ID and sees the destination on the next pass.
+	if _, err := r.shareRepo(ctx, src, cfg, "s3:bucket/db", "pw"); err != nil {
+		t.Fatalf("shareRepo: %v", err)
+	}
+	if src.Status.RepoID != "volsync-prod-replicationsource-db" || src.Status.RepoReferences != 2 {
+		t.Fatalf("unexpected owner status: id=%q refs=%d", src.Status.RepoID, src.Status.RepoReferences)
+	}
+
+	// A different repository is not grouped.
+	other := sharingTestBinding("prod", "ReplicationSource", "cache", now.Add(-2*time.Hour))
+	if _, err := r.shareRepo(ctx, other, cfg, "s3:bucket/cache", "pw"); err != nil {
+		t.Fatalf("shareRepo: %v", err)
+	}
+	if other.Status.RepoID != defaultRepoID(other) || other.Status.RepoReferences != 1 {
+		t.Fatalf("unexpected status for unrelated binding: id=%q refs=%d", other.Status.RepoID, other.Status.RepoReferences)
+	}
+}
+
+func TestFinalizeBinding_RemovesRepoWithLastReference(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+	src := sharingTestBinding("prod", "ReplicationSource", "db", now.Add(-time.Hour))
+	dst := sharingTestBinding("restore", "ReplicationDestination", "db", now)
+	dst.Status.RepoID = src.Status.RepoID
+	for _, b := range []*v1alpha1.BackrestVolSyncBinding{src, dst} {
+		b.Status.LastAppliedInputHash = "applied"
+		controllerutil.AddFinalizer(b, finalizerRepoCleanup)
+	}
+
+	c := sharingTestClient(t, src, dst)
+	fakeClient := &fakeBackrestRepoClient{}
+	r := &BackrestVolSyncBindingReconciler{
+		Client: c,
+		BackrestClientFactory: func(_ string, _ backrest.Auth, _ backrest.Options) backrestRepoClient {
+			return fakeClient
+		},
+	}
+	cfg := OperatorConfigSnapshot{RepoShareMatch: RepoShareURI, RemoveUnreferencedRepos: true}
+
+	deleteAndFinalize := func(b *v1alpha1.BackrestVolSyncBinding) {
+		t.Helper()
+		if err := c.Delete(ctx, b); err != nil {
+			t.Fatalf("delete: %v", err)
+		}
+		var got v1alpha1.BackrestVolSyncBinding
+		if err := c.Get(ctx, client.ObjectKeyFromObject(b), &got); err != nil {
+			t.Fatalf("get: %v", err)
+		}
+		if _, err := r.finalizeBinding(ctx, &got, cfg); err != nil {
+			t.Fatalf("finalize: %v", err)
+		}
+	}
+
+	deleteAndFinalize(src)
+	if len(fakeClient.removedRepoIDs) != 0 {
+		t.Fatalf("repo removed while still referenced: %v", fakeClient.removedRepoIDs)
+	}
+
+	deleteAndFinalize(dst)
+	if len(fakeClient.removedRepoIDs) != 1 || fakeClient.removedRepoIDs[0] != src.Status.RepoID {
+		t.Fatalf("expected repo %q removed once, got %v", src.Status.RepoID, fakeClient.removedRepoIDs)
+	}
+
+	var list v1alpha1.BackrestVolSyncBindingList
+	if err := c.List(ctx, &list); err != nil {
+		t.Fatalf("list: %v", err)
+	}
+	if len(list.Items) != 0 {
+		t.Fatalf("expected finalizers released, %d bindings left", len(list.Items))
+	}
+}
+
+func TestBackrestVolSyncBindingReconcile_SharedRepoRegisteredOnce(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+	src := sharingTestBinding("prod", "ReplicationSource", "db", now.Add(-time.Hour))
+	dst := sharingTestBinding("restore", "ReplicationDestination", "db", now)
+
+	// Both repository Secrets point at the same URI, with different passwords.
+	objs := []client.Object{src, dst}
+	for _, b := range []*v1alpha1.BackrestVolSyncBinding{src, dst} {
+		vs := &unstructured.Unstructured{Object: map[string]any{
+			"apiVersion": volsync.Group + "/" + volsync.Version,
+			"kind":       b.Spec.Source.Kind,
+			"metadata":   map[string]any{"name": "db", "namespace": b.Namespace},
+			"spec":       map[string]any{"restic": map[string]any{"repository": "repo-secret"}},
+		}}
+		sec := &corev1.Secret{}
+		sec.Namespace = b.Namespace
+		sec.Name = "repo-secret"
+		sec.Data = map[string][]byte{
+			"RESTIC_REPOSITORY": []byte("s3://bucket/db"),
+			"RESTIC_PASSWORD":   []byte("pw-" + b.Namespace),
+		}
+		objs = append(objs, vs, sec)
+	}
+	cfg := &v1alpha1.BackrestVolSyncOperatorConfig{}
+	cfg.Namespace = "backrest-volsync-operator"
+	cfg.Name = "backrest-volsync-operator"
+	cfg.Spec.RepoSharing.Match = RepoShareURI
+	objs = append(objs, cfg)
+
+	c := sharingTestClient(t, objs...)
+	br := &fakeBackrestRepoClient{}
+	r := &BackrestVolSyncBindingReconciler{
+		Client:         c,
+		OperatorConfig: types.NamespacedName{Namespace: cfg.Namespace, Name: cfg.Name},
+		BackrestClientFactory: func(string, backrest.Auth, backrest.Options) backrestRepoClient {
+			return br
+		},
+	}
+	reconcile := func(b *v1alpha1.BackrestVolSyncBinding) *v1alpha1.BackrestVolSyncBinding {
+		t.Helper()
+		if _, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: client.ObjectKeyFromObject(b)}); err != nil {
+			t.Fatalf("reconcile %s: %v", b.Name, err)
+		}
+		var got v1alpha1.BackrestVolSyncBinding
+		if err := c.Get(ctx, client.ObjectKeyFromObject(b), &got); err != nil {
+			t.Fatalf("get: %v", err)
+		}
+		return &got
+	}
+
+	reconcile(src)
+	got := reconcile(dst)
+	reconcile(src)
+	if br.addRepoCalls != 1 || br.lastRepo.GetPassword() != "pw-prod" {
+		t.Fatalf("expected only the owner to register the repo, got %d calls, last password %q", br.addRepoCalls, br.lastRepo.GetPassword())
+	}
+	if got.Status.RepoID != src.Status.RepoID {
+		t.Fatalf("expected the destination to adopt %q, got %q", src.Status.RepoID, got.Status.RepoID)
+	}
+	if cond := meta.FindStatusCondition(got.Status.Targets[0].Conditions, conditionReady); cond == nil || cond.Reason != reasonRepoShared {
+		t.Fatalf("expected target Ready reason %s, got %#v", reasonRepoShared, cond)
+	}
+
+	// Once the owner is gone, the destination registers the repo with its own, validated credentials.
+	if err := c.Delete(ctx, src); err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+	got = reconcile(dst)
+	if br.checkRepoCalls != 1 {
+		t.Fatalf("expected the credentials validated before taking over, got %d checks", br.checkRepoCalls)
+	}
+	if br.addRepoCalls != 2 || br.lastRepo.GetPassword() != "pw-restore" || br.lastRepo.GetId() != src.Status.RepoID {
+		t.Fatalf("expected the destination to register %q, got %d calls, last repo %+v", src.Status.RepoID, br.addRepoCalls, br.lastRepo)
+	}
+	if cond := meta.FindStatusCondition(got.Status.Targets[0].Conditions, conditionReady); cond == nil || cond.Reason != "Applied" {
+		t.Fatalf("expected target Ready reason Applied, got %#v", cond)
+	}
+}
@@ -0,0 +1,179 @@
+package controllers
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/jogotcha/backrest-volsync-operator/api/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// resticKeyAction describes how a RESTIC_* key from the VolSync repository Secret is handed to Backrest.
+type resticKeyAction struct {
+	// Flag, when set, turns the key into a restic flag "<Flag>=<value>" on the Backrest repo.
+	Flag string
+	// Env forwards the key unchanged as a Backrest repo env entry.
+	Env bool
+}
+
+// resticKeyMappings is the table of RESTIC_* keys the operator understands besides
+// RESTIC_REPOSITORY and RESTIC_PASSWORD. Keys not listed here are dropped.
+//
+// Path-valued keys (RESTIC_CACERT, RESTIC_TLS_CLIENT_CERT) are passed through as-is, so the
+// path must also be readable inside the Backrest container.
+var resticKeyMappings = map[string]resticKeyAction{
+	"RESTIC_COMPRESSION":      {Flag: "--compression"},
+	"RESTIC_PACK_SIZE":        {Flag: "--pack-size"},
+	"RESTIC_READ_CONCURRENCY": {Flag: "--read-concurrency"},
+	"RESTIC_CACERT":           {Flag: "--cacert"},
+	"RESTIC_TLS_CLIENT_CERT":  {Flag: "--tls-client-cert"},
+	"RESTIC_LIMIT_UPLOAD":     {Flag: "--limit-upload"},
+	"RESTIC_LIMIT_DOWNLOAD":   {Flag: "--limit-download"},
+	"RESTIC_KEY_HINT":         {Env: true},
+}
+
+// resticKeyDenylist lists keys that are never forwarded, even if they appear in envAllowlist.
+// They either replace Backrest's own password/repository handling, execute commands, point at
+// local state Backrest manages itself, or carry credentials for a different repository.
+var resticKeyDenylist = map[string]struct{}{
+	"RESTIC_PASSWORD_FILE":         {},
+	"RESTIC_PASSWORD_COMMAND":      {},
+	"RESTIC_REPOSITORY_FILE":       {},
+	"RESTIC_CACHE_DIR":             {},
+	"RESTIC_FROM_REPOSITORY":       {},
+	"RESTIC_FROM_REPOSITORY_FILE":  {},
+	"RESTIC_FROM_PASSWORD":         {},
+	"RESTIC_FROM_PASSWORD_FILE":    {},
+	"RESTIC_FROM_PASSWORD_COMMAND": {},
+}
+
+// mapResticKey reports how a RESTIC_* key should be handled.
+// ok is false when the key is denied or unknown and must be dropped.
+func mapResticKey(key string) (resticKeyAction, bool) {
+	if _, denied := resticKeyDenylist[key]; denied {
+		return resticKeyAction{}, false
+	}
+	action, ok := resticKeyMappings[key]
+	return action, ok
+}
+
+// resticSecretData is what the operator derives from a VolSync repository Secret.
+type resticSecretData struct {
+	Repository string
+	Password   string
+	Env        []string
+	Flags      []string
+	Keys       v1alpha1.RepositorySecretKeys
+}
+
+// extractResticSecret reads RESTIC_REPOSITORY/RESTIC_PASSWORD and sorts every other key into
+// env entries, mapped flags, or dropped keys:
+//   - RESTIC_* keys in resticKeyMappings are always honoured (they are restic settings, not credentials).
+//   - Denied and unknown RESTIC_* keys are dropped.
+//   - Other keys are forwarded as env, restricted to allowlist when it is non-empty.
+//
+// envFrom holds lower-precedence entries from spec.repo.envFrom. They follow the same RESTIC_* rules
+// but are not subject to allowlist (envFrom has its own per-source key list). A key present in the
+// repository Secret always wins.
+func extractResticSecret(sec *corev1.Secret, allowlist []string, envFrom map[string]string) (resticSecretData, error) {
+	out := resticSecretData{
+		Repository: strings.TrimSpace(string(sec.Data["RESTIC_REPOSITORY"])),
+		Password:   string(sec.Data["RESTIC_PASSWORD"]),
+	}
+	if out.Repository == "" {
+		return resticSecretData{}, fmt.Errorf("missing RESTIC_REPOSITORY")
+	}
+	if out.Password == "" {
+		return resticSecretData{}, fmt.Errorf("missing RESTIC_PASSWORD")
+	}
+
+	allowed := map[string]struct{}{}
+	for _, k := range allowlist {
+		allowed[k] = struct{}{}
+	}
+
+	forwarded := map[string]struct{}{}
+	mapped := map[string]struct{}{}
+	dropped := map[string]struct{}{}
+	add := func(k, v string, checkAllowlist bool) {
+		if k == "RESTIC_REPOSITORY" || k == "RESTIC_PASSWORD" {
+			return
+		}
+		if strings.HasPrefix(k, "RESTIC_") {
+			action, ok := mapResticKey(k)
+			value := strings.TrimSpace(v)
+			switch {
+			case !ok || value == "":
+				dropped[k] = struct{}{}
+			case action.Flag != "":
+				out.Flags = append(out.Flags, action.Flag+"="+value)
+				mapped[k] = struct{}{}
+			default:
+				out.Env = append(out.Env, k+"="+value)
+				forwarded[k] = struct{}{}
+			}
+			return
+		}
+		if checkAllowlist && len(allowed) > 0 {
+			if _, ok := allowed[k]; !ok {
+				dropped[k] = struct{}{}
+				return
+			}
+		}
+		out.Env = append(out.Env, k+"="+v)
+		forwarded[k] = struct{}{}
+	}
+	for k, v := range sec.Data {
+		add(k, string(v), true)
+	}
+	for k, v := range envFrom {
+		if _, shadowed := sec.Data[k]; shadowed {
+			continue
+		}
+		add(k, v, false)
+	}
+	sort.Strings(out.Env)
+	sort.Strings(out.Flags)
+	out.Keys = v1alpha1.RepositorySecretKeys{
+		Forwarded: sortedKeys(forwarded),
+		Mapped:    sortedKeys(mapped),
+		Dropped:   sortedKeys(dropped),
+	}
+	return out, nil
+}
+
+// mergeMappedFlags appends flags derived from secret keys unless the same flag was already set
+// explicitly (for example via spec.repo.extraFlags), which always wins.
+func mergeMappedFlags(explicit, mapped []string) []string {
+	out := append([]string(nil), explicit...)
+	for _, f := range mapped {
+		name, _, _ := strings.Cut(f, "=")
+		if hasFlag(explicit, name) {
+			continue
+		}
+		out = append(out, f)
+	}
+	return out
+}
+
+func hasFlag(flags []string, name string) bool {
+	for _, f := range flags {
+		if f == name || strings.HasPrefix(f, name+"=") {
+			return true
+		}
+	}
+	return false
+}
+
+func sortedKeys(m map[string]struct{}) []string {
+	if len(m) == 0 {
+		return nil
+	}
+	out := make([]string, 0, len(m))
+	for k := range m {
+		out = append(out, k)
+	}
+	sort.Strings(out)
+	return out
+}
@@ -0,0 +1,86 @@
+package controllers
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/jogotcha/backrest-volsync-operator/api/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+)
+
+func TestExtractResticSecret_MapsKnownKeys(t *testing.T) {
+	sec := &corev1.Secret{Data: map[string][]byte{
+		"RESTIC_REPOSITORY":       []byte(" s3://bucket/repo "),
+		"RESTIC_PASSWORD":         []byte("pass"),
+		"RESTIC_COMPRESSION":      []byte("max"),
+		"RESTIC_PACK_SIZE":        []byte("64"),
+		"RESTIC_READ_CONCURRENCY": []byte("4"),
+		"RESTIC_CACERT":           []byte("/etc/ssl/repo-ca.pem"),
+		"RESTIC_KEY_HINT":         []byte("abc123"),
+		"RESTIC_PASSWORD_COMMAND": []byte("cat /secret"),
+		"RESTIC_UNKNOWN_SETTING":  []byte("x"),
+		"RESTIC_PACK_SIZE_EMPTY":  []byte(""),
+		"AWS_ACCESS_KEY_ID":       []byte("id"),
+		"AWS_SECRET_ACCESS_KEY":   []byte("secret"),
+		"UNRELATED":               []byte("nope"),
+	}}
+
+	got, err := extractResticSecret(sec, []string{"AWS_ACCESS_KEY_ID", "AWS_SECRET_ACCESS_KEY", "RESTIC_PASSWORD_COMMAND"}, nil)
+	if err != nil {
+		t.Fatalf("extract: %v", err)
+	}
+	if got.Repository != "s3://bucket/repo" || got.Password != "pass" {
+		t.Fatalf("unexpected repository/password: %q %q", got.Repository, got.Password)
+	}
+
+	wantFlags := []string{"--cacert=/etc/ssl/repo-ca.pem", "--compression=max", "--pack-size=64", "--read-concurrency=4"}
+	if !reflect.DeepEqual(got.Flags, wantFlags) {
+		t.Fatalf("flags: got %v want %v", got.Flags, wantFlags)
+	}
+	wantEnv := []string{"AWS_ACCESS_KEY_ID=id", "AWS_SECRET_ACCESS_KEY=secret", "RESTIC_KEY_HINT=abc123"}
+	if !reflect.DeepEqual(got.Env, wantEnv) {
+		t.Fatalf("env: got %v want %v", got.Env, wantEnv)
+	}
+	wantKeys := v1alpha1.RepositorySecretKeys{
+		Forwarded: []string{"AWS_ACCESS_KEY_ID", "AWS_SECRET_ACCESS_KEY", "RESTIC_KEY_HINT"},
+		Mapped:    []string{"RESTIC_CACERT", "RESTIC_COMPRESSION", "RESTIC_PACK_SIZE", "RESTIC_READ_CONCURRENCY"},
+		Dropped:   []string{"RESTIC_PACK_SIZE_EMPTY", "RESTIC_PASSWORD_COMMAND", "RESTIC_UNKNOWN_SETTING", "UNRELATED"},
+	}
+	if !reflect.DeepEqual(got.Keys, wantKeys) {
+		t.Fatalf("keys: got %#v want %#v", got.Keys, wantKeys)
+	}
+}
+
+func TestMergeMappedFlags_ExplicitWins(t *testing.T) {
+	tests := []struct {
+		name     string
+		explicit []string
+		mapped   []string
+		want     []string
+	}{
+		{
+			name:   "no explicit flags",
+			mapped: []string{"--compression=max"},
+			want:   []string{"--compression=max"},
+		},
+		{
+			name:     "explicit flag overrides mapped value",
+			explicit: []string{"--compression=off"},
+			mapped:   []string{"--compression=max", "--pack-size=64"},
+			want:     []string{"--compression=off", "--pack-size=64"},
+		},
+		{
+			name:     "explicit bare flag overrides mapped value",
+			explicit: []string{"--cacert"},
+			mapped:   []string{"--cacert=/ca.pem"},
+			want:     []string{"--cacert"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := mergeMappedFlags(tt.explicit, tt.mapped); !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("got %v want %v", got, tt.want)
+			}
+		})
+	}
+}
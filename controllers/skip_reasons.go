@@ -0,0 +1,134 @@
+package controllers
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/jogotcha/backrest-volsync-operator/api/v1alpha1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+)
+
+// skipReason says why auto-binding did not bind a VolSync object. The values are shown in events and
+// in status.autoBindingSkips.
+type skipReason string
+
+const (
+	skipPolicyDisabled         skipReason = "PolicyDisabled"
+	skipKindNotAllowed         skipReason = "KindNotAllowed"
+	skipNotAnnotated           skipReason = "NotAnnotated"
+	skipOutsideSelectors       skipReason = "OutsideSelectors"
+	skipNoDefaultBackrest      skipReason = "NoDefaultBackrest"
+	skipUnmanagedBinding       skipReason = "UnmanagedBinding"
+	skipNamespaceConfigInvalid skipReason = "NamespaceConfigInvalid"
+	skipBindingNameConflict    skipReason = "BindingNameConflict"
+
+	// skipEventInterval is how often a skip is reported again as an event while its reason is unchanged.
+	skipEventInterval = time.Hour
+
+	// maxSkippedObjectSample caps the objects listed per reason in status.autoBindingSkips.
+	maxSkippedObjectSample = 5
+)
+
+// skipKey identifies a VolSync object; a ReplicationSource and a ReplicationDestination can share a name.
+type skipKey struct {
+	kind, namespace, name string
+}
+
+func skipKeyFor(kind string, vsObj *unstructured.Unstructured) skipKey {
+	return skipKey{kind: kind, namespace: vsObj.GetNamespace(), name: vsObj.GetName()}
+}
+
+type skipEntry struct {
+	reason  skipReason
+	eventAt time.Time
+}
+
+// SkipRecorder remembers why VolSyncAutoBindingReconciler skipped VolSync objects. It rate-limits the
+// events explaining the skips, and OperatorConfigReconciler summarizes it in the OperatorConfig status.
+type SkipRecorder struct {
+	mu      sync.Mutex
+	skipped map[skipKey]skipEntry
+	changed chan event.GenericEvent
+	now     func() time.Time
+}
+
+// NewSkipRecorder returns an empty recorder to share between the two reconcilers.
+func NewSkipRecorder() *SkipRecorder {
+	return &SkipRecorder{
+		skipped: map[skipKey]skipEntry{},
+		changed: make(chan event.GenericEvent, 1),
+		now:     time.Now,
+	}
+}
+
+// Skip records why an object was skipped and reports whether to emit an event: the reason is new,
+// or skipEventInterval passed since the last event. A nil recorder always emits.
+func (s *SkipRecorder) Skip(key skipKey, reason skipReason) bool {
+	if s == nil {
+		return true
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := s.now()
+	prev, ok := s.skipped[key]
+	if ok && prev.reason == reason && now.Sub(prev.eventAt) < skipEventInterval {
+		return false
+	}
+	s.skipped[key] = skipEntry{reason: reason, eventAt: now}
+	if !ok || prev.reason != reason {
+		s.notify()
+	}
+	return true
+}
+
+// Clear forgets an object that is bound or gone.
+func (s *SkipRecorder) Clear(key skipKey) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.skipped[key]; ok {
+		delete(s.skipped, key)
+		s.notify()
+	}
+}
+
+// notify coalesces change notifications; one pending event re-reads everything.
+func (s *SkipRecorder) notify() {
+	select {
+	case s.changed <- event.GenericEvent{Object: &v1alpha1.BackrestVolSyncOperatorConfig{}}:
+	default:
+	}
+}
+
+// Report summarizes the skipped objects per reason, or returns nil when none were skipped.
+func (s *SkipRecorder) Report() *v1alpha1.AutoBindingSkipStatus {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.skipped) == 0 {
+		return nil
+	}
+	objects := map[skipReason][]string{}
+	for key, entry := range s.skipped {
+		objects[entry.reason] = append(objects[entry.reason], key.kind+" "+key.namespace+"/"+key.name)
+	}
+	out := &v1alpha1.AutoBindingSkipStatus{Total: int32(len(s.skipped))}
+	for reason, names := range objects {
+		sort.Strings(names)
+		count := int32(len(names))
+		if len(names) > maxSkippedObjectSample {
+			names = names[:maxSkippedObjectSample]
+		}
+		out.Reasons = append(out.Reasons, v1alpha1.SkipReasonCount{Reason: string(reason), Count: count, Objects: names})
+	}
+	sort.Slice(out.Reasons, func(i, j int) bool { return out.Reasons[i].Reason < out.Reasons[j].Reason })
+	return out
+}
+
+// Changed signals that the set of skipped objects or their reasons changed.
+func (s *SkipRecorder) Changed() <-chan event.GenericEvent {
+	return s.changed
+}
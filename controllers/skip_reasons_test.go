@@ -0,0 +1,132 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/jogotcha/backrest-volsync-operator/api/v1alpha1"
+	"github.com/jogotcha/backrest-volsync-operator/pkg/volsync"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/events"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestSkipRecorder(t *testing.T) {
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	s := NewSkipRecorder()
+	s.now = func() time.Time { return now }
+	key := skipKey{kind: "ReplicationSource", namespace: "workload", name: "demo"}
+
+	if !s.Skip(key, skipNotAnnotated) {
+		t.Fatalf("expected the first skip to emit")
+	}
+	now = now.Add(skipEventInterval / 2)
+	if s.Skip(key, skipNotAnnotated) {
+		t.Fatalf("expected a repeated skip within the interval not to emit")
+	}
+	if !s.Skip(key, skipKindNotAllowed) {
+		t.Fatalf("expected a new reason to emit")
+	}
+	now = now.Add(skipEventInterval)
+	if !s.Skip(key, skipKindNotAllowed) {
+		t.Fatalf("expected a skip after the interval to emit")
+	}
+
+	for i := 0; i < maxSkippedObjectSample+2; i++ {
+		s.Skip(skipKey{kind: "ReplicationDestination", namespace: "workload", name: fmt.Sprintf("rd-%d", i)}, skipPolicyDisabled)
+	}
+	report := s.Report()
+	if report.Total != maxSkippedObjectSample+3 || len(report.Reasons) != 2 {
+		t.Fatalf("unexpected report: %+v", report)
+	}
+	if got := report.Reasons[0]; got.Reason != string(skipKindNotAllowed) || got.Count != 1 || !reflect.DeepEqual(got.Objects, []string{"ReplicationSource workload/demo"}) {
+		t.Fatalf("unexpected first reason: %+v", got)
+	}
+	if got := report.Reasons[1]; got.Reason != string(skipPolicyDisabled) || got.Count != maxSkippedObjectSample+2 || len(got.Objects) != maxSkippedObjectSample {
+		t.Fatalf("unexpected second reason: %+v", got)
+	}
+
+	s.Clear(key)
+	if got := s.Report().Total; got != maxSkippedObjectSample+2 {
+		t.Fatalf("expected the cleared object dropped, got total %d", got)
+	}
+}
+
+func TestVolSyncAutoBindingReconcile_SkipEvents(t *testing.T) {
+	ctx := context.Background()
+	scheme := testScheme(t)
+
+	cfg := &v1alpha1.BackrestVolSyncOperatorConfig{}
+	cfg.Namespace = "backrest-volsync-operator"
+	cfg.Name = "backrest-volsync-operator"
+	cfg.Spec.BindingGeneration.Policy = string(BindingPolicyAnnotated)
+	cfg.Spec.DefaultBackrest.URL = "http://example.invalid"
+
+	vs := &unstructured.Unstructured{}
+	vs.SetGroupVersionKind(schema.GroupVersionKind{Group: volsync.Group, Version: volsync.Version, Kind: "ReplicationSource"})
+	vs.SetNamespace("workload")
+	vs.SetName("demo")
+	vs.SetUID(types.UID("1111"))
+
+	c := fake.NewClientBuilder().WithScheme(scheme).
+		WithStatusSubresource(&v1alpha1.BackrestVolSyncOperatorConfig{}).
+		WithObjects(cfg, vs).
+		Build()
+	recorder := events.NewFakeRecorder(10)
+	skips := NewSkipRecorder()
+	nn := types.NamespacedName{Namespace: cfg.Namespace, Name: cfg.Name}
+	r := &VolSyncAutoBindingReconciler{Client: c, Scheme: scheme, Recorder: recorder, OperatorConfig: nn, Skips: skips}
+	status := &OperatorConfigReconciler{Client: c, Scheme: scheme, OperatorConfig: nn, Skips: skips}
+	req := ctrl.Request{NamespacedName: types.NamespacedName{Namespace: "workload", Name: "demo"}}
+
+	reconcile := func() {
+		t.Helper()
+		if _, err := r.Reconcile(ctx, req); err != nil {
+			t.Fatalf("reconcile: %v", err)
+		}
+	}
+	reportedSkips := func() *v1alpha1.AutoBindingSkipStatus {
+		t.Helper()
+		if _, err := status.Reconcile(ctx, ctrl.Request{NamespacedName: nn}); err != nil {
+			t.Fatalf("reconcile config: %v", err)
+		}
+		var got v1alpha1.BackrestVolSyncOperatorConfig
+		if err := c.Get(ctx, nn, &got); err != nil {
+			t.Fatalf("get config: %v", err)
+		}
+		return got.Status.AutoBindingSkips
+	}
+
+	// Every status update of the object reconciles it again; the event is not repeated.
+	reconcile()
+	reconcile()
+	if e := <-recorder.Events; e != "Normal BindingSkipped Auto-binding skipped (NotAnnotated): the backrest.garethgeorge.com/binding annotation does not enable auto-binding" {
+		t.Fatalf("unexpected event %q", e)
+	}
+	if len(recorder.Events) != 0 {
+		t.Fatalf("expected a single event, got another: %q", <-recorder.Events)
+	}
+	want := &v1alpha1.AutoBindingSkipStatus{Total: 1, Reasons: []v1alpha1.SkipReasonCount{{Reason: "NotAnnotated", Count: 1, Objects: []string{"ReplicationSource workload/demo"}}}}
+	if got := reportedSkips(); !reflect.DeepEqual(got, want) {
+		t.Fatalf("unexpected status.autoBindingSkips: %+v", got)
+	}
+
+	// Once bound, the object drops out of the summary.
+	vs.SetAnnotations(map[string]string{annotationAutoBinding: "true"})
+	if err := c.Update(ctx, vs); err != nil {
+		t.Fatalf("update volsync object: %v", err)
+	}
+	reconcile()
+	if e := <-recorder.Events; e != "Normal BindingCreated Created BackrestVolSyncBinding bvsb-rs-demo" {
+		t.Fatalf("unexpected event %q", e)
+	}
+	if got := reportedSkips(); got != nil {
+		t.Fatalf("expected status.autoBindingSkips cleared, got %+v", got)
+	}
+}
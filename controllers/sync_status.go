@@ -0,0 +1,105 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+
+	"github.com/jogotcha/backrest-volsync-operator/api/v1alpha1"
+	"github.com/jogotcha/backrest-volsync-operator/pkg/volsync"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+const conditionSourceFailing = "SourceFailing"
+
+// mirrorSyncStatus copies the VolSync sync status into binding status and maintains the
+// SourceFailing condition. An unreadable VolSync status is logged and leaves status unchanged;
+// it must not block registering the repo.
+func (r *BackrestVolSyncBindingReconciler) mirrorSyncStatus(ctx context.Context, b *v1alpha1.BackrestVolSyncBinding, vsObj *unstructured.Unstructured) bool {
+	s, err := volsync.ParseSyncStatus(vsObj)
+	if err != nil {
+		log.FromContext(ctx).V(1).Info("unable to read VolSync sync status", "errorHash", hashString(err.Error()))
+		return false
+	}
+
+	desired := &v1alpha1.VolSyncStatus{
+		LastSyncTime:      s.LastSyncTime,
+		LastSyncStartTime: s.LastSyncStartTime,
+		LastSyncDuration:  s.LastSyncDuration,
+		NextSyncTime:      s.NextSyncTime,
+		LastManualSync:    s.LastManualSync,
+		LastResult:        s.MoverResult,
+	}
+	if s.Synchronizing != nil {
+		desired.Synchronizing = s.Synchronizing.Status
+		desired.SynchronizingReason = s.Synchronizing.Reason
+	}
+	if *desired == (v1alpha1.VolSyncStatus{}) {
+		desired = nil
+	}
+
+	changed := false
+	if !reflect.DeepEqual(b.Status.Sync, desired) {
+		b.Status.Sync = desired
+		changed = true
+	}
+	if recordRestore(b, s) {
+		changed = true
+	}
+	if r.setSourceFailingCondition(b, s) {
+		changed = true
+	}
+	return changed
+}
+
+// recordRestore records the latest completed restore of a ReplicationDestination. The image is
+// empty with copyMethods that do not report latestImage.
+func recordRestore(b *v1alpha1.BackrestVolSyncBinding, s volsync.SyncStatus) bool {
+	if b.Spec.Source.Kind != volsync.KindReplicationDestination || s.LastSyncTime == "" {
+		return false
+	}
+	if b.Status.LastRestoredSnapshot == s.LatestImage && b.Status.LastRestoreTime == s.LastSyncTime {
+		return false
+	}
+	b.Status.LastRestoredSnapshot = s.LatestImage
+	b.Status.LastRestoreTime = s.LastSyncTime
+	return true
+}
+
+// setSourceFailingCondition sets SourceFailing from the latest mover result. Before the first mover
+// run there is no result and the condition is left as is.
+func (r *BackrestVolSyncBindingReconciler) setSourceFailingCondition(b *v1alpha1.BackrestVolSyncBinding, s volsync.SyncStatus) bool {
+	if s.MoverResult == "" {
+		return false
+	}
+	cond := metav1.Condition{
+		Type:               conditionSourceFailing,
+		Status:             metav1.ConditionFalse,
+		Reason:             "MoverSucceeded",
+		Message:            fmt.Sprintf("Latest %s mover run succeeded", b.Spec.Source.Kind),
+		ObservedGeneration: b.Generation,
+		LastTransitionTime: metav1.Now(),
+	}
+	if s.MoverFailed() {
+		cond.Status = metav1.ConditionTrue
+		cond.Reason = "MoverFailed"
+		cond.Message = fmt.Sprintf("Latest %s mover run failed; see the VolSync object status", b.Spec.Source.Kind)
+	} else if s.MoverResult != volsync.MoverResultSuccessful {
+		cond.Status = metav1.ConditionUnknown
+		cond.Reason = "UnknownResult"
+		cond.Message = fmt.Sprintf("Latest %s mover result %q is not recognized", b.Spec.Source.Kind, s.MoverResult)
+	}
+
+	wasFailing := meta.IsStatusConditionTrue(b.Status.Conditions, conditionSourceFailing)
+	if !meta.SetStatusCondition(&b.Status.Conditions, cond) {
+		return false
+	}
+	if r.Recorder != nil && cond.Status == metav1.ConditionTrue && !wasFailing {
+		r.Recorder.Eventf(b, nil, corev1.EventTypeWarning, "SourceFailing", "Observe", "%s %s: latest mover run failed", b.Spec.Source.Kind, b.Spec.Source.Name)
+	}
+	return true
+}
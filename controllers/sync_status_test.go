@@ -0,0 +1,200 @@
+package controllers
+
+import (
+	"context"
+	"testing"
+
+	"github.com/jogotcha/backrest-volsync-operator/api/v1alpha1"
+	"github.com/jogotcha/backrest-volsync-operator/pkg/backrest"
+	"github.com/jogotcha/backrest-volsync-operator/pkg/volsync"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestBackrestVolSyncBindingReconcile_MirrorsSyncStatus(t *testing.T) {
+	ctx := context.Background()
+	scheme := bindingTestScheme(t)
+
+	b := &v1alpha1.BackrestVolSyncBinding{}
+	b.Namespace = "workload"
+	b.Name = "b"
+	b.Spec.Backrest.URL = "http://backrest.invalid"
+	b.Spec.Source = v1alpha1.VolSyncSourceRef{Kind: "ReplicationSource", Name: "demo"}
+
+	vs := &unstructured.Unstructured{Object: map[string]any{
+		"apiVersion": volsync.Group + "/" + volsync.Version,
+		"kind":       "ReplicationSource",
+		"metadata":   map[string]any{"name": "demo", "namespace": "workload"},
+		"spec":       map[string]any{"restic": map[string]any{"repository": "repo-secret"}},
+		"status": map[string]any{
+			"lastSyncTime":      "2026-01-01T00:00:00Z",
+			"lastSyncDuration":  "42s",
+			"nextSyncTime":      "2026-01-01T01:00:00Z",
+			"latestMoverStatus": map[string]any{"result": "Failed"},
+			"conditions": []any{
+				map[string]any{"type": "Synchronizing", "status": "False", "reason": "WaitingForSchedule"},
+			},
+		},
+	}}
+
+	sec := &corev1.Secret{}
+	sec.Namespace = "workload"
+	sec.Name = "repo-secret"
+	sec.Data = map[string][]byte{
+		"RESTIC_REPOSITORY": []byte("s3://bucket/repo"),
+		"RESTIC_PASSWORD":   []byte("pass"),
+	}
+
+	c := fake.NewClientBuilder().WithScheme(scheme).
+		WithStatusSubresource(&v1alpha1.BackrestVolSyncBinding{}).
+		WithObjects(b, vs, sec).
+		Build()
+
+	r := &BackrestVolSyncBindingReconciler{
+		Client: c,
+		Scheme: scheme,
+		BackrestClientFactory: func(string, backrest.Auth, backrest.Options) backrestRepoClient {
+			return &fakeBackrestRepoClient{}
+		},
+	}
+	req := ctrl.Request{NamespacedName: types.NamespacedName{Namespace: b.Namespace, Name: b.Name}}
+	if _, err := r.Reconcile(ctx, req); err != nil {
+		t.Fatalf("reconcile: %v", err)
+	}
+
+	var got v1alpha1.BackrestVolSyncBinding
+	if err := c.Get(ctx, req.NamespacedName, &got); err != nil {
+		t.Fatalf("get: %v", err)
+	}
+	want := v1alpha1.VolSyncStatus{
+		LastSyncTime:        "2026-01-01T00:00:00Z",
+		LastSyncDuration:    "42s",
+		NextSyncTime:        "2026-01-01T01:00:00Z",
+		LastResult:          "Failed",
+		Synchronizing:       "False",
+		SynchronizingReason: "WaitingForSchedule",
+	}
+	if got.Status.Sync == nil || *got.Status.Sync != want {
+		t.Fatalf("sync status: got %#v want %#v", got.Status.Sync, want)
+	}
+	if !meta.IsStatusConditionTrue(got.Status.Conditions, conditionSourceFailing) {
+		t.Fatalf("expected SourceFailing=True, got %#v", got.Status.Conditions)
+	}
+	if !meta.IsStatusConditionTrue(got.Status.Conditions, conditionReady) {
+		t.Fatalf("a failing mover must not affect Ready")
+	}
+
+	// The next successful run clears the condition.
+	var current unstructured.Unstructured
+	current.SetGroupVersionKind(vs.GroupVersionKind())
+	if err := c.Get(ctx, types.NamespacedName{Namespace: "workload", Name: "demo"}, &current); err != nil {
+		t.Fatalf("get vs: %v", err)
+	}
+	if err := unstructured.SetNestedField(current.Object, "Successful", "status", "latestMoverStatus", "result"); err != nil {
+		t.Fatalf("set: %v", err)
+	}
+	if err := c.Update(ctx, &current); err != nil {
+		t.Fatalf("update vs: %v", err)
+	}
+	if _, err := r.Reconcile(ctx, req); err != nil {
+		t.Fatalf("reconcile #2: %v", err)
+	}
+	if err := c.Get(ctx, req.NamespacedName, &got); err != nil {
+		t.Fatalf("get: %v", err)
+	}
+	cond := meta.FindStatusCondition(got.Status.Conditions, conditionSourceFailing)
+	if cond == nil || cond.Reason != "MoverSucceeded" || meta.IsStatusConditionTrue(got.Status.Conditions, conditionSourceFailing) {
+		t.Fatalf("expected SourceFailing=False/MoverSucceeded, got %#v", cond)
+	}
+}
+
+func TestBackrestVolSyncBindingReconcile_ReplicationDestinationTriggersTasks(t *testing.T) {
+	ctx := context.Background()
+	scheme := bindingTestScheme(t)
+
+	b := &v1alpha1.BackrestVolSyncBinding{}
+	b.Namespace = "dr"
+	b.Name = "b"
+	b.Spec.Backrest.URL = "http://backrest.invalid"
+	b.Spec.Source = v1alpha1.VolSyncSourceRef{Kind: "ReplicationDestination", Name: "demo"}
+	enabled := true
+	b.Spec.Repo.TriggerTasksOnSnapshot = &enabled
+
+	vs := &unstructured.Unstructured{Object: map[string]any{
+		"apiVersion": volsync.Group + "/" + volsync.Version,
+		"kind":       "ReplicationDestination",
+		"metadata":   map[string]any{"name": "demo", "namespace": "dr"},
+		"spec":       map[string]any{"restic": map[string]any{"repository": "repo-secret"}},
+		"status": map[string]any{
+			"lastSyncTime": "2026-01-01T00:00:00Z",
+			"latestImage":  map[string]any{"name": "demo-dest-1"},
+		},
+	}}
+
+	sec := &corev1.Secret{}
+	sec.Namespace = "dr"
+	sec.Name = "repo-secret"
+	sec.Data = map[string][]byte{
+		"RESTIC_REPOSITORY": []byte("s3://bucket/repo"),
+		"RESTIC_PASSWORD":   []byte("pass"),
+	}
+
+	c := fake.NewClientBuilder().WithScheme(scheme).
+		WithStatusSubresource(&v1alpha1.BackrestVolSyncBinding{}).
+		WithObjects(b, vs, sec).
+		Build()
+
+	br := &fakeBackrestRepoClient{}
+	r := &BackrestVolSyncBindingReconciler{
+		Client: c,
+		Scheme: scheme,
+		BackrestClientFactory: func(string, backrest.Auth, backrest.Options) backrestRepoClient {
+			return br
+		},
+	}
+	req := ctrl.Request{NamespacedName: types.NamespacedName{Namespace: b.Namespace, Name: b.Name}}
+	for i := 0; i < 2; i++ {
+		if _, err := r.Reconcile(ctx, req); err != nil {
+			t.Fatalf("reconcile: %v", err)
+		}
+	}
+	if len(br.taskCalls) != 2 {
+		t.Fatalf("expected INDEX_SNAPSHOTS and STATS once, got %v", br.taskCalls)
+	}
+	var got v1alpha1.BackrestVolSyncBinding
+	if err := c.Get(ctx, req.NamespacedName, &got); err != nil {
+		t.Fatalf("get: %v", err)
+	}
+	if got.Status.LastRestoredSnapshot != "demo-dest-1" || got.Status.LastRestoreTime != "2026-01-01T00:00:00Z" {
+		t.Fatalf("unexpected restore record: %q %q", got.Status.LastRestoredSnapshot, got.Status.LastRestoreTime)
+	}
+
+	// A later restore into the same image name (copyMethod Direct) still triggers the tasks.
+	var current unstructured.Unstructured
+	current.SetGroupVersionKind(vs.GroupVersionKind())
+	if err := c.Get(ctx, types.NamespacedName{Namespace: "dr", Name: "demo"}, &current); err != nil {
+		t.Fatalf("get vs: %v", err)
+	}
+	if err := unstructured.SetNestedField(current.Object, "2026-01-01T06:00:00Z", "status", "lastSyncTime"); err != nil {
+		t.Fatalf("set: %v", err)
+	}
+	if err := c.Update(ctx, &current); err != nil {
+		t.Fatalf("update vs: %v", err)
+	}
+	if _, err := r.Reconcile(ctx, req); err != nil {
+		t.Fatalf("reconcile #3: %v", err)
+	}
+	if len(br.taskCalls) != 4 {
+		t.Fatalf("expected tasks for the new restore, got %v", br.taskCalls)
+	}
+	if err := c.Get(ctx, req.NamespacedName, &got); err != nil {
+		t.Fatalf("get: %v", err)
+	}
+	if got.Status.LastRestoreTime != "2026-01-01T06:00:00Z" {
+		t.Fatalf("expected restore time updated, got %q", got.Status.LastRestoreTime)
+	}
+}
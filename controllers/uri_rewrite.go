@@ -0,0 +1,86 @@
+package controllers
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/jogotcha/backrest-volsync-operator/api/v1alpha1"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+// uriRewriteRule is a validated URIRewriteRule with its regex compiled.
+type uriRewriteRule struct {
+	match   string
+	re      *regexp.Regexp
+	replace string
+}
+
+// compileURIRewrites checks that each rule sets exactly one of match/regex and compiles the regexes.
+// The compiled rules are only complete when no errors are returned.
+func compileURIRewrites(rules []v1alpha1.URIRewriteRule, path *field.Path) ([]uriRewriteRule, field.ErrorList) {
+	var errs field.ErrorList
+	compiled := make([]uriRewriteRule, 0, len(rules))
+	for i, rule := range rules {
+		p := path.Index(i)
+		switch {
+		case rule.Match == "" && rule.Regex == "":
+			errs = append(errs, field.Required(p, "one of match or regex is required"))
+		case rule.Match != "" && rule.Regex != "":
+			errs = append(errs, field.Forbidden(p.Child("regex"), "must not be set together with match"))
+		case rule.Regex != "":
+			re, err := regexp.Compile(rule.Regex)
+			if err != nil {
+				errs = append(errs, field.Invalid(p.Child("regex"), rule.Regex, err.Error()))
+				continue
+			}
+			compiled = append(compiled, uriRewriteRule{re: re, replace: rule.Replace})
+		default:
+			compiled = append(compiled, uriRewriteRule{match: rule.Match, replace: rule.Replace})
+		}
+	}
+	return compiled, errs
+}
+
+// validateURIRewrites checks rules that are compiled later, when they are copied into a binding.
+func validateURIRewrites(rules []v1alpha1.URIRewriteRule, path *field.Path) field.ErrorList {
+	_, errs := compileURIRewrites(rules, path)
+	return errs
+}
+
+// rewriteRepositoryURI applies the rule lists in order, each rule seeing the previous rule's output.
+func rewriteRepositoryURI(uri string, ruleSets ...[]uriRewriteRule) string {
+	for _, rules := range ruleSets {
+		for _, rule := range rules {
+			if rule.re != nil {
+				uri = rule.re.ReplaceAllString(uri, rule.replace)
+			} else {
+				uri = strings.ReplaceAll(uri, rule.match, rule.replace)
+			}
+		}
+	}
+	return strings.TrimSpace(uri)
+}
+
+// redactRepositoryURI hides the password of a "user:password@" userinfo so the URI can be shown in status.
+// restic URIs are not always parseable by net/url (e.g. "s3:http://host/bucket"), so this works on the
+// last "://" authority section directly.
+func redactRepositoryURI(uri string) string {
+	i := strings.LastIndex(uri, "://")
+	if i < 0 {
+		return uri
+	}
+	start := i + len("://")
+	authority := uri[start:]
+	if slash := strings.IndexByte(authority, '/'); slash >= 0 {
+		authority = authority[:slash]
+	}
+	at := strings.LastIndexByte(authority, '@')
+	if at < 0 {
+		return uri
+	}
+	user, _, hasPassword := strings.Cut(authority[:at], ":")
+	if !hasPassword {
+		return uri
+	}
+	return uri[:start] + user + ":redacted" + uri[start+at:]
+}
@@ -4,8 +4,9 @@ import (
 	"context"
 	"crypto/sha256"
 	"encoding/hex"
-	"reflect"
+	"slices"
 	"strings"
+	"time"
 
 	"github.com/jogotcha/backrest-volsync-operator/api/v1alpha1"
 	"github.com/jogotcha/backrest-volsync-operator/pkg/volsync"
@@ -13,14 +14,16 @@ import (
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
-	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/tools/events"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 )
 
@@ -29,6 +32,13 @@ const (
 	labelManaged          = "backrest.garethgeorge.com/managed"
 	annotationManagedBy   = "backrest.garethgeorge.com/managed-by"
 	annotationVolSyncRef  = "backrest.garethgeorge.com/volsync-ref"
+
+	// annotationDetachedFrom marks a binding detached under orphanPolicy Detach with the UID of its
+	// VolSync object, so the operator adopts it again when that object becomes eligible.
+	annotationDetachedFrom = "backrest.garethgeorge.com/detached-from"
+
+	// bindingDeletionRetryInterval paces checks whether a binding that is being deleted is gone.
+	bindingDeletionRetryInterval = 5 * time.Second
 )
 
 type VolSyncAutoBindingReconciler struct {
@@ -37,6 +47,15 @@ type VolSyncAutoBindingReconciler struct {
 	Recorder events.EventRecorder
 
 	OperatorConfig types.NamespacedName
+
+	// VolSync selects the served VolSync API version per kind. The zero value uses volsync.Version.
+	VolSync volsync.API
+
+	// DryRun collects the changes bindingGeneration.dryRun holds back; it may be nil.
+	DryRun *DryRunRecorder
+
+	// Skips remembers why objects were skipped and rate-limits the events saying so; it may be nil.
+	Skips *SkipRecorder
 }
 
 func (r *VolSyncAutoBindingReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
@@ -53,36 +72,68 @@ func (r *VolSyncAutoBindingReconciler) Reconcile(ctx context.Context, req ctrl.R
 		logger.Info("Operator is paused, skipping")
 		return ctrl.Result{}, nil
 	}
-	if cfg.BindingPolicy == BindingPolicyDisabled {
-		logger.Info("Binding policy is disabled, skipping")
+	if !cfg.Found {
+		logger.Info("No operator config, skipping")
 		return ctrl.Result{}, nil
 	}
 
 	vsObj, kind, err := r.getVolSyncObjectEither(ctx, req.NamespacedName)
 	if err != nil {
+		if apierrors.IsNotFound(err) {
+			// The object is gone; the owner reference deletes its binding, so nothing is pending.
+			for _, k := range []string{"ReplicationSource", "ReplicationDestination"} {
+				r.Skips.Clear(skipKey{kind: k, namespace: req.Namespace, name: req.Name})
+				if cfg.DryRun {
+					r.DryRun.Record(types.NamespacedName{Namespace: req.Namespace, Name: desiredBindingName(k, req.Name)}, dryRunNone)
+				}
+			}
+		}
 		logger.Error(err, "Failed to get VolSync object")
 		return ctrl.Result{}, client.IgnoreNotFound(err)
 	}
 
 	logger.Info("Found VolSync object", "kind", kind, "name", vsObj.GetName())
 
-	if !cfg.IsVolSyncKindAllowed(kind) {
-		logger.Info("Auto-binding not enabled for this kind; skipping", "kind", kind, "name", vsObj.GetName())
+	cfg, err = LoadNamespaceConfig(ctx, r.Client, cfg, req.Namespace)
+	if err != nil {
+		// Reported in the namespace config's status; its next change re-enqueues the namespace.
+		logger.Info("Invalid namespace config; skipping auto-binding in this namespace", "namespace", req.Namespace, "errorHash", hashString(err.Error()))
+		r.recordSkip(vsObj, kind, skipNamespaceConfigInvalid, "the BackrestVolSyncNamespaceConfig of this namespace is invalid; see its status")
 		return ctrl.Result{}, nil
 	}
 
-	allowed := isAutoBindingAllowed(cfg.BindingPolicy, vsObj)
-	if !allowed {
-		logger.Info("Auto-binding not allowed for this object", "kind", kind, "name", vsObj.GetName())
+	skip, reason, err := r.ineligibleReason(ctx, cfg, vsObj, kind)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	if skip != "" {
+		logger.Info("VolSync object is not eligible for auto-binding", "kind", kind, "name", vsObj.GetName(), "reason", reason)
+		if err := r.handleOrphanedBinding(ctx, cfg, vsObj, kind, reason); err != nil {
+			return ctrl.Result{}, err
+		}
+		r.recordSkip(vsObj, kind, skip, reason)
 		return ctrl.Result{}, nil
 	}
 
-	if strings.TrimSpace(cfg.DefaultBackrestURL) == "" {
-		logger.Info("Auto-binding enabled but defaultBackrest.url is empty; skipping", "volsyncKind", kind, "volsyncName", vsObj.GetName())
+	bindingName, conflict, err := r.resolveBindingName(ctx, vsObj, kind)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	if bindingName == "" {
+		logger.Info("Both binding names are taken by other bindings; skipping", "volsyncKind", kind, "volsyncName", vsObj.GetName(), "reason", conflict)
+		if r.Skips.Skip(skipKeyFor(kind, vsObj), skipBindingNameConflict) && r.Recorder != nil {
+			r.Recorder.Eventf(vsObj, nil, corev1.EventTypeWarning, "BindingNameConflict", "ResolveBindingName", "%s; no binding is created", conflict)
+		}
 		return ctrl.Result{}, nil
 	}
+	bindingKey := types.NamespacedName{Namespace: req.Namespace, Name: bindingName}
 
-	bindingName := desiredBindingName(kind, vsObj.GetName())
+	if strings.TrimSpace(cfg.DefaultBackrestURL) == "" && cfg.DefaultBackrestInstanceRef == nil {
+		logger.Info("Auto-binding enabled but defaultBackrest has neither url nor instanceRef; skipping", "volsyncKind", kind, "volsyncName", vsObj.GetName())
+		r.recordSkip(vsObj, kind, skipNoDefaultBackrest, "defaultBackrest has neither url nor instanceRef")
+		r.recordDryRun(cfg, vsObj, bindingKey, dryRunNone, "")
+		return ctrl.Result{}, nil
+	}
 
 	desired := v1alpha1.BackrestVolSyncBinding{
 		ObjectMeta: metav1.ObjectMeta{
@@ -94,169 +145,303 @@ func (r *VolSyncAutoBindingReconciler) Reconcile(ctx context.Context, req ctrl.R
 			Annotations: map[string]string{
 				annotationManagedBy:  "backrest-volsync-operator",
 				annotationVolSyncRef: strings.ToLower(kind) + "/" + vsObj.GetName(),
+				annotationConfigHash: autoBindingConfigHash(cfg),
 			},
 		},
 		Spec: v1alpha1.BackrestVolSyncBindingSpec{
-			Backrest: v1alpha1.BackrestConnection{
-				URL:     cfg.DefaultBackrestURL,
-				AuthRef: cfg.DefaultBackrestAuthRef,
-			},
-			Source: v1alpha1.VolSyncSourceRef{Kind: kind, Name: vsObj.GetName()},
-			Repo:   cfg.DefaultRepo,
+			Backrest: cfg.DefaultBackrestConnection(),
+			Source:   v1alpha1.VolSyncSourceRef{Kind: kind, Name: vsObj.GetName()},
+			Repo:     cfg.DefaultRepo,
 		},
 	}
 
+	for _, msg := range applyVolSyncOverrides(&desired.Spec, cfg.AllowedOverrides, vsObj.GetAnnotations()) {
+		logger.Info("Rejected VolSync override annotation", "volsyncKind", kind, "volsyncName", vsObj.GetName(), "reason", msg)
+		if r.Recorder != nil {
+			r.Recorder.Eventf(vsObj, nil, corev1.EventTypeWarning, "OverrideRejected", "ApplyOverrides", "%s", msg)
+		}
+	}
+
 	setOwnerReference(&desired, vsObj, kind)
 
 	var existing v1alpha1.BackrestVolSyncBinding
-	err = r.Get(ctx, types.NamespacedName{Namespace: req.Namespace, Name: bindingName}, &existing)
+	err = r.Get(ctx, bindingKey, &existing)
+	if err != nil && !apierrors.IsNotFound(err) {
+		return ctrl.Result{}, err
+	}
 	if err != nil {
-		if apierrors.IsNotFound(err) {
-			if err := r.Create(ctx, &desired); err != nil {
-				return ctrl.Result{}, err
-			}
-			logger.Info("Created BackrestVolSyncBinding", "binding", desired.Name, "volsyncKind", kind, "volsyncName", vsObj.GetName())
+		r.Skips.Clear(skipKeyFor(kind, vsObj))
+		if cfg.DryRun {
+			r.recordDryRun(cfg, vsObj, bindingKey, dryRunCreate, "would create BackrestVolSyncBinding "+bindingName)
+			return ctrl.Result{}, nil
+		}
+		if conflict != "" {
+			logger.Info("Binding name is taken; using the alternate name", "binding", bindingName, "reason", conflict)
 			if r.Recorder != nil {
-				r.Recorder.Eventf(vsObj, nil, corev1.EventTypeNormal, "BindingCreated", "CreateBinding", "Created BackrestVolSyncBinding %s", desired.Name)
+				r.Recorder.Eventf(vsObj, nil, corev1.EventTypeWarning, "BindingNameConflict", "ResolveBindingName", "%s; using BackrestVolSyncBinding %s instead", conflict, bindingName)
 			}
-			return ctrl.Result{}, nil
 		}
-		return ctrl.Result{}, err
-	}
-
-	// Do not modify user-managed bindings.
-	if existing.Labels[labelManaged] != "true" {
-		logger.Info("Binding exists but is not managed; skipping", "binding", existing.Name)
+		if _, err := r.applyBinding(ctx, &desired, nil, false); err != nil {
+			return ctrl.Result{}, err
+		}
+		logger.Info("Created BackrestVolSyncBinding", "binding", desired.Name, "volsyncKind", kind, "volsyncName", vsObj.GetName())
+		if r.Recorder != nil {
+			r.Recorder.Eventf(vsObj, nil, corev1.EventTypeNormal, "BindingCreated", "CreateBinding", "Created BackrestVolSyncBinding %s", desired.Name)
+		}
 		return ctrl.Result{}, nil
 	}
 
-	// Patch only if spec/metadata drifted.
-	original := existing.DeepCopy()
-	mutated := existing.DeepCopy()
-	changed := false
-
-	if !reflect.DeepEqual(mutated.Spec, desired.Spec) {
-		mutated.Spec = desired.Spec
-		changed = true
-	}
-	if mutated.Labels == nil {
-		mutated.Labels = map[string]string{}
-		changed = true
-	}
-	if mutated.Labels[labelManaged] != "true" {
-		mutated.Labels[labelManaged] = "true"
-		changed = true
-	}
-	if mutated.Annotations == nil {
-		mutated.Annotations = map[string]string{}
-		changed = true
-	}
-	if mutated.Annotations[annotationManagedBy] != desired.Annotations[annotationManagedBy] {
-		mutated.Annotations[annotationManagedBy] = desired.Annotations[annotationManagedBy]
-		changed = true
-	}
-	if mutated.Annotations[annotationVolSyncRef] != desired.Annotations[annotationVolSyncRef] {
-		mutated.Annotations[annotationVolSyncRef] = desired.Annotations[annotationVolSyncRef]
-		changed = true
-	}
-	if !reflect.DeepEqual(mutated.OwnerReferences, desired.OwnerReferences) {
-		mutated.OwnerReferences = desired.OwnerReferences
-		changed = true
+	// A binding that is being deleted, for example by garbage collection after the VolSync object
+	// was recreated, is created again once it is gone.
+	if !existing.DeletionTimestamp.IsZero() {
+		logger.Info("Binding is being deleted; waiting", "binding", existing.Name)
+		return ctrl.Result{RequeueAfter: bindingDeletionRetryInterval}, nil
 	}
 
-	if !changed {
+	// Do not modify user-managed bindings, except one the operator detached from this object.
+	readopt := existing.Labels[labelManaged] != "true" && detachedFrom(&existing, vsObj)
+	if existing.Labels[labelManaged] != "true" && !readopt {
+		logger.Info("Binding exists but is not managed; skipping", "binding", existing.Name)
+		r.recordSkip(vsObj, kind, skipUnmanagedBinding, "BackrestVolSyncBinding "+existing.Name+" already binds this object and is not managed by the operator")
+		r.recordDryRun(cfg, vsObj, bindingKey, dryRunNone, "")
 		return ctrl.Result{}, nil
 	}
+	r.Skips.Clear(skipKeyFor(kind, vsObj))
+	if readopt && !cfg.DryRun {
+		if err := r.clearDetachedFrom(ctx, &existing); err != nil {
+			return ctrl.Result{}, err
+		}
+	}
 
-	patch := client.MergeFrom(original)
-	if err := r.Patch(ctx, mutated, patch); err != nil {
+	// Server-side apply only touches the fields the operator sets, so fields owned by users or
+	// other tools are kept. In dry run the API server computes the result without persisting it.
+	changed, err := r.applyBinding(ctx, &desired, &existing, cfg.DryRun)
+	if err != nil {
 		return ctrl.Result{}, err
 	}
-	if r.Recorder != nil {
-		r.Recorder.Eventf(vsObj, nil, corev1.EventTypeNormal, "BindingUpdated", "UpdateBinding", "Updated BackrestVolSyncBinding %s", mutated.Name)
+	if cfg.DryRun {
+		action := dryRunNone
+		if changed {
+			action = dryRunUpdate
+		}
+		r.recordDryRun(cfg, vsObj, bindingKey, action, "would update BackrestVolSyncBinding "+bindingName)
+		return ctrl.Result{}, nil
+	}
+	if readopt {
+		logger.Info("Adopted detached BackrestVolSyncBinding", "binding", existing.Name, "volsyncKind", kind, "volsyncName", vsObj.GetName())
+		if r.Recorder != nil {
+			r.Recorder.Eventf(vsObj, nil, corev1.EventTypeNormal, "BindingAdopted", "AdoptBinding", "Adopted detached BackrestVolSyncBinding %s; it is managed by the operator again", desired.Name)
+		}
+		return ctrl.Result{}, nil
+	}
+	if changed && r.Recorder != nil {
+		r.Recorder.Eventf(vsObj, nil, corev1.EventTypeNormal, "BindingUpdated", "UpdateBinding", "Updated BackrestVolSyncBinding %s", desired.Name)
 	}
 
 	return ctrl.Result{}, nil
 }
 
+var volSyncMetadataChanged = predicate.Or(predicate.LabelChangedPredicate{}, predicate.AnnotationChangedPredicate{})
+
 func (r *VolSyncAutoBindingReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	rs := &unstructured.Unstructured{}
-	rs.SetGroupVersionKind(schema.GroupVersionKind{Group: volsync.Group, Version: volsync.Version, Kind: "ReplicationSource"})
+	rs.SetGroupVersionKind(r.VolSync.GVK("ReplicationSource"))
 	rd := &unstructured.Unstructured{}
-	rd.SetGroupVersionKind(schema.GroupVersionKind{Group: volsync.Group, Version: volsync.Version, Kind: "ReplicationDestination"})
+	rd.SetGroupVersionKind(r.VolSync.GVK("ReplicationDestination"))
 
 	return ctrl.NewControllerManagedBy(mgr).
 		Named("volsync-autobinding").
+		// Auto-binding reads only labels and annotations of VolSync objects, so the status updates of
+		// every sync do not reconcile them.
 		Watches(rs, handler.EnqueueRequestsFromMapFunc(func(ctx context.Context, obj client.Object) []reconcile.Request {
 			return []reconcile.Request{{NamespacedName: types.NamespacedName{Namespace: obj.GetNamespace(), Name: obj.GetName()}}}
-		})).
+		}), builder.WithPredicates(volSyncMetadataChanged)).
 		Watches(rd, handler.EnqueueRequestsFromMapFunc(func(ctx context.Context, obj client.Object) []reconcile.Request {
 			return []reconcile.Request{{NamespacedName: types.NamespacedName{Namespace: obj.GetNamespace(), Name: obj.GetName()}}}
-		})).
-		Watches(&v1alpha1.BackrestVolSyncOperatorConfig{}, handler.EnqueueRequestsFromMapFunc(func(ctx context.Context, obj client.Object) []reconcile.Request {
-			if r.OperatorConfig.Name == "" || r.OperatorConfig.Namespace == "" {
-				return nil
-			}
-			if obj.GetNamespace() != r.OperatorConfig.Namespace || obj.GetName() != r.OperatorConfig.Name {
-				return nil
-			}
-			cfg, ok := obj.(*v1alpha1.BackrestVolSyncOperatorConfig)
-			if !ok {
-				return nil
-			}
-			if cfg.Spec.Paused {
-				return nil
-			}
-			policy := strings.TrimSpace(cfg.Spec.BindingGeneration.Policy)
-			if policy == "" || BindingGenerationPolicy(policy) == BindingPolicyDisabled {
-				return nil
-			}
-			allowRS := true
-			allowRD := true
-			if len(cfg.Spec.BindingGeneration.Kinds) > 0 {
-				allowRS = false
-				allowRD = false
-				for _, k := range cfg.Spec.BindingGeneration.Kinds {
-					switch strings.TrimSpace(k) {
-					case "ReplicationSource":
-						allowRS = true
-					case "ReplicationDestination":
-						allowRD = true
-					}
-				}
-			}
-			if !allowRS && !allowRD {
-				return nil
-			}
-			reqs := make([]reconcile.Request, 0)
-			if allowRS {
-				// Enqueue all ReplicationSources cluster-wide.
-				var rsList unstructured.UnstructuredList
-				rsList.SetGroupVersionKind(schema.GroupVersionKind{Group: volsync.Group, Version: volsync.Version, Kind: "ReplicationSourceList"})
-				if err := r.List(ctx, &rsList); err == nil {
-					for i := range rsList.Items {
-						reqs = append(reqs, reconcile.Request{NamespacedName: types.NamespacedName{Namespace: rsList.Items[i].GetNamespace(), Name: rsList.Items[i].GetName()}})
-					}
-				}
-			}
-			if allowRD {
-				// Enqueue all ReplicationDestinations cluster-wide.
-				var rdList unstructured.UnstructuredList
-				rdList.SetGroupVersionKind(schema.GroupVersionKind{Group: volsync.Group, Version: volsync.Version, Kind: "ReplicationDestinationList"})
-				if err := r.List(ctx, &rdList); err == nil {
-					for i := range rdList.Items {
-						reqs = append(reqs, reconcile.Request{NamespacedName: types.NamespacedName{Namespace: rdList.Items[i].GetNamespace(), Name: rdList.Items[i].GetName()}})
-					}
-				}
-			}
-			return reqs
-		})).
+		}), builder.WithPredicates(volSyncMetadataChanged)).
+		// Namespace label changes move VolSync objects into or out of bindingGeneration.namespaceSelector.
+		Watches(&corev1.Namespace{}, handler.EnqueueRequestsFromMapFunc(func(ctx context.Context, obj client.Object) []reconcile.Request {
+			return r.volSyncObjectsInNamespace(ctx, obj.GetName())
+		}), builder.WithPredicates(predicate.LabelChangedPredicate{})).
+		// Spec changes only; the namespace config controller writes its status.
+		Watches(&v1alpha1.BackrestVolSyncNamespaceConfig{}, handler.EnqueueRequestsFromMapFunc(func(ctx context.Context, obj client.Object) []reconcile.Request {
+			return r.volSyncObjectsInNamespace(ctx, obj.GetNamespace())
+		}), builder.WithPredicates(predicate.GenerationChangedPredicate{})).
+		// Only changes to the fields auto-binding reads fan out, and objects whose binding is already
+		// current are skipped; see volSyncObjectsForConfigChange.
+		Watches(&v1alpha1.BackrestVolSyncOperatorConfig{}, handler.EnqueueRequestsFromMapFunc(func(ctx context.Context, _ client.Object) []reconcile.Request {
+			return r.volSyncObjectsForConfigChange(ctx)
+		}), builder.WithPredicates(operatorConfigChanged(r.OperatorConfig, autoBindingConfigFields))).
 		Complete(r)
 }
 
+// volSyncObjects enqueues every ReplicationSource and ReplicationDestination cluster-wide.
+func (r *VolSyncAutoBindingReconciler) volSyncObjects(ctx context.Context) []reconcile.Request {
+	return r.volSyncObjectsInNamespace(ctx, "")
+}
+
+// volSyncObjectsInNamespace enqueues every ReplicationSource and ReplicationDestination of a
+// namespace; an empty namespace lists all namespaces.
+func (r *VolSyncAutoBindingReconciler) volSyncObjectsInNamespace(ctx context.Context, namespace string) []reconcile.Request {
+	var reqs []reconcile.Request
+	for _, kind := range []string{"ReplicationSource", "ReplicationDestination"} {
+		var list unstructured.UnstructuredList
+		list.SetGroupVersionKind(r.VolSync.ListGVK(kind))
+		if err := r.List(ctx, &list, client.InNamespace(namespace)); err != nil {
+			continue
+		}
+		for i := range list.Items {
+			reqs = append(reqs, reconcile.Request{NamespacedName: types.NamespacedName{Namespace: list.Items[i].GetNamespace(), Name: list.Items[i].GetName()}})
+		}
+	}
+	return reqs
+}
+
+// inBindingScope reports whether a VolSync object matches bindingGeneration.objectSelector and, via
+// its namespace labels, namespaceSelector. Objects in a namespace that no longer exists are out of scope.
+func (r *VolSyncAutoBindingReconciler) inBindingScope(ctx context.Context, cfg OperatorConfigSnapshot, vsObj *unstructured.Unstructured) (bool, error) {
+	if cfg.ObjectSelector != nil && !cfg.ObjectSelector.Matches(labels.Set(vsObj.GetLabels())) {
+		return false, nil
+	}
+	if cfg.NamespaceSelector == nil {
+		return true, nil
+	}
+	var ns corev1.Namespace
+	if err := r.Get(ctx, types.NamespacedName{Name: vsObj.GetNamespace()}, &ns); err != nil {
+		if apierrors.IsNotFound(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	return cfg.NamespaceSelector.Matches(labels.Set(ns.Labels)), nil
+}
+
+// ineligibleReason explains why a VolSync object is not eligible for auto-binding, or returns ""
+// when it is.
+func (r *VolSyncAutoBindingReconciler) ineligibleReason(ctx context.Context, cfg OperatorConfigSnapshot, vsObj *unstructured.Unstructured, kind string) (skipReason, string, error) {
+	if cfg.BindingPolicy == BindingPolicyDisabled {
+		return skipPolicyDisabled, "the binding policy is Disabled", nil
+	}
+	if !cfg.IsVolSyncKindAllowed(kind) {
+		return skipKindNotAllowed, kind + " is not in bindingGeneration.kinds", nil
+	}
+	if !isAutoBindingAllowed(cfg.BindingPolicy, vsObj) {
+		return skipNotAnnotated, "the " + annotationAutoBinding + " annotation does not enable auto-binding", nil
+	}
+	inScope, err := r.inBindingScope(ctx, cfg, vsObj)
+	if err != nil {
+		return "", "", err
+	}
+	if !inScope {
+		return skipOutsideSelectors, "the object is outside the bindingGeneration selectors", nil
+	}
+	return "", "", nil
+}
+
+// handleOrphanedBinding applies orphanPolicy to the managed binding of a VolSync object that is no
+// longer eligible: Keep leaves it as it is, Delete removes it, and Detach hands it over to the user by
+// dropping the managed label and annotations and the owner reference. User-managed bindings are left
+// alone.
+func (r *VolSyncAutoBindingReconciler) handleOrphanedBinding(ctx context.Context, cfg OperatorConfigSnapshot, vsObj *unstructured.Unstructured, kind, reason string) error {
+	logger := log.FromContext(ctx)
+	name, _, err := r.resolveBindingName(ctx, vsObj, kind)
+	if err != nil || name == "" {
+		return err
+	}
+	var existing v1alpha1.BackrestVolSyncBinding
+	key := types.NamespacedName{Namespace: vsObj.GetNamespace(), Name: name}
+	if err := r.Get(ctx, key, &existing); err != nil {
+		if apierrors.IsNotFound(err) {
+			r.recordDryRun(cfg, vsObj, key, dryRunNone, "")
+		}
+		return client.IgnoreNotFound(err)
+	}
+	if existing.Labels[labelManaged] != "true" || !existing.DeletionTimestamp.IsZero() || cfg.OrphanPolicy == OrphanPolicyKeep {
+		r.recordDryRun(cfg, vsObj, key, dryRunNone, "")
+		return nil
+	}
+
+	if cfg.DryRun {
+		if cfg.OrphanPolicy == OrphanPolicyDelete {
+			r.recordDryRun(cfg, vsObj, key, dryRunDelete, "would delete BackrestVolSyncBinding "+name+": "+reason)
+		} else {
+			r.recordDryRun(cfg, vsObj, key, dryRunUpdate, "would detach BackrestVolSyncBinding "+name+": "+reason)
+		}
+		return nil
+	}
+
+	if cfg.OrphanPolicy == OrphanPolicyDelete {
+		if err := r.Delete(ctx, &existing); err != nil {
+			return client.IgnoreNotFound(err)
+		}
+		logger.Info("Deleted orphaned BackrestVolSyncBinding", "binding", existing.Name, "volsyncKind", kind, "volsyncName", vsObj.GetName())
+		if r.Recorder != nil {
+			r.Recorder.Eventf(vsObj, nil, corev1.EventTypeNormal, "BindingDeleted", "DeleteBinding", "Deleted BackrestVolSyncBinding %s: %s", existing.Name, reason)
+		}
+		return nil
+	}
+
+	// The detached binding outlives the VolSync object, and the operator no longer owns its fields.
+	// detached-from lets the object adopt it again once it is eligible.
+	original := existing.DeepCopy()
+	delete(existing.Labels, labelManaged)
+	delete(existing.Annotations, annotationManagedBy)
+	delete(existing.Annotations, annotationConfigHash)
+	if existing.Annotations == nil {
+		existing.Annotations = map[string]string{}
+	}
+	existing.Annotations[annotationDetachedFrom] = string(vsObj.GetUID())
+	existing.OwnerReferences = slices.DeleteFunc(existing.OwnerReferences, func(ref metav1.OwnerReference) bool {
+		return ref.UID == vsObj.GetUID()
+	})
+	releaseManagedFields(&existing)
+	if err := r.Patch(ctx, &existing, client.MergeFromWithOptions(original, client.MergeFromWithOptimisticLock{})); err != nil {
+		return client.IgnoreNotFound(err)
+	}
+	logger.Info("Detached orphaned BackrestVolSyncBinding", "binding", existing.Name, "volsyncKind", kind, "volsyncName", vsObj.GetName())
+	if r.Recorder != nil {
+		r.Recorder.Eventf(vsObj, nil, corev1.EventTypeNormal, "BindingDetached", "DetachBinding", "Detached BackrestVolSyncBinding %s: %s; it is no longer managed by the operator", existing.Name, reason)
+	}
+	return nil
+}
+
+// detachedFrom reports whether the operator detached a binding from the VolSync object, which then
+// adopts it again instead of skipping it as user-managed. A binding detached from an earlier object of
+// the same name stays with the user.
+func detachedFrom(b *v1alpha1.BackrestVolSyncBinding, vsObj *unstructured.Unstructured) bool {
+	uid := b.Annotations[annotationDetachedFrom]
+	return uid != "" && uid == string(vsObj.GetUID())
+}
+
+// clearDetachedFrom removes the detached-from annotation before a detached binding is adopted again;
+// the apply cannot remove it because the operator's field manager does not own it.
+func (r *VolSyncAutoBindingReconciler) clearDetachedFrom(ctx context.Context, b *v1alpha1.BackrestVolSyncBinding) error {
+	original := b.DeepCopy()
+	delete(b.Annotations, annotationDetachedFrom)
+	return r.Patch(ctx, b, client.MergeFromWithOptions(original, client.MergeFromWithOptimisticLock{}))
+}
+
+// recordSkip records why auto-binding skipped a VolSync object, with an event on it when the reason
+// changes and at most once per skipEventInterval otherwise.
+func (r *VolSyncAutoBindingReconciler) recordSkip(vsObj *unstructured.Unstructured, kind string, reason skipReason, message string) {
+	if !r.Skips.Skip(skipKeyFor(kind, vsObj), reason) || r.Recorder == nil {
+		return
+	}
+	r.Recorder.Eventf(vsObj, nil, corev1.EventTypeNormal, "BindingSkipped", "SkipBinding", "Auto-binding skipped (%s): %s", reason, message)
+}
+
+// recordDryRun records the action auto-binding would take for a binding while dry run is enabled,
+// with an event on the VolSync object when the action changes.
+func (r *VolSyncAutoBindingReconciler) recordDryRun(cfg OperatorConfigSnapshot, vsObj *unstructured.Unstructured, binding types.NamespacedName, action dryRunAction, note string) {
+	if !cfg.DryRun || !r.DryRun.Record(binding, action) || action == dryRunNone || r.Recorder == nil {
+		return
+	}
+	r.Recorder.Eventf(vsObj, nil, corev1.EventTypeNormal, "Would"+string(action)+"Binding", "DryRun", "Dry run: %s", note)
+}
+
 func (r *VolSyncAutoBindingReconciler) getVolSyncObjectEither(ctx context.Context, nn types.NamespacedName) (*unstructured.Unstructured, string, error) {
 	rs := &unstructured.Unstructured{}
-	rs.SetGroupVersionKind(schema.GroupVersionKind{Group: volsync.Group, Version: volsync.Version, Kind: "ReplicationSource"})
+	rs.SetGroupVersionKind(r.VolSync.GVK("ReplicationSource"))
 	if err := r.Get(ctx, nn, rs); err == nil {
 		return rs, "ReplicationSource", nil
 	} else if !apierrors.IsNotFound(err) {
@@ -264,7 +449,7 @@ func (r *VolSyncAutoBindingReconciler) getVolSyncObjectEither(ctx context.Contex
 	}
 
 	rd := &unstructured.Unstructured{}
-	rd.SetGroupVersionKind(schema.GroupVersionKind{Group: volsync.Group, Version: volsync.Version, Kind: "ReplicationDestination"})
+	rd.SetGroupVersionKind(r.VolSync.GVK("ReplicationDestination"))
 	if err := r.Get(ctx, nn, rd); err != nil {
 		return nil, "", err
 	}
@@ -331,7 +516,7 @@ func setOwnerReference(binding *v1alpha1.BackrestVolSyncBinding, vsObj *unstruct
 	block := true
 	binding.OwnerReferences = []metav1.OwnerReference{
 		{
-			APIVersion:         volsync.Group + "/" + volsync.Version,
+			APIVersion:         vsObj.GetAPIVersion(),
 			Kind:               kind,
 			Name:               vsObj.GetName(),
 			UID:                vsObj.GetUID(),
@@ -13,12 +13,15 @@ import (
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/events"
+	"k8s.io/utils/ptr"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
 )
 
-func testScheme(t *testing.T) *runtime.Scheme {
+func testScheme(t testing.TB) *runtime.Scheme {
 	t.Helper()
 	scheme := runtime.NewScheme()
 	if err := v1alpha1.AddToScheme(scheme); err != nil {
@@ -180,6 +183,245 @@ func TestVolSyncAutoBindingReconcile_DoesNotTouchUserBinding(t *testing.T) {
 	}
 }
 
+func TestVolSyncAutoBindingReconcile_Selectors(t *testing.T) {
+	ctx := context.Background()
+	scheme := testScheme(t)
+
+	cfg := &v1alpha1.BackrestVolSyncOperatorConfig{}
+	cfg.Namespace = "backrest-volsync-operator"
+	cfg.Name = "backrest-volsync-operator"
+	cfg.Spec.BindingGeneration.Policy = string(BindingPolicyAll)
+	cfg.Spec.BindingGeneration.NamespaceSelector = &metav1.LabelSelector{MatchLabels: map[string]string{"backup": "enabled"}}
+	cfg.Spec.BindingGeneration.ObjectSelector = &metav1.LabelSelector{MatchExpressions: []metav1.LabelSelectorRequirement{
+		{Key: "tier", Operator: metav1.LabelSelectorOpNotIn, Values: []string{"scratch"}},
+	}}
+	cfg.Spec.BindingGeneration.OrphanPolicy = string(OrphanPolicyDelete)
+	cfg.Spec.DefaultBackrest.URL = "http://example.invalid"
+
+	ns := &corev1.Namespace{}
+	ns.Name = "workload"
+
+	vs := &unstructured.Unstructured{}
+	vs.SetGroupVersionKind(schema.GroupVersionKind{Group: volsync.Group, Version: volsync.Version, Kind: "ReplicationSource"})
+	vs.SetNamespace("workload")
+	vs.SetName("demo")
+	vs.SetUID(types.UID("1111"))
+
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(cfg, ns, vs).Build()
+	r := &VolSyncAutoBindingReconciler{
+		Client:         c,
+		Scheme:         scheme,
+		OperatorConfig: types.NamespacedName{Namespace: cfg.Namespace, Name: cfg.Name},
+	}
+	req := ctrl.Request{NamespacedName: types.NamespacedName{Namespace: "workload", Name: "demo"}}
+	bindingKey := types.NamespacedName{Namespace: "workload", Name: "bvsb-rs-demo"}
+	reconcileAndCheck := func(step string, wantBinding bool) {
+		t.Helper()
+		if _, err := r.Reconcile(ctx, req); err != nil {
+			t.Fatalf("%s: reconcile: %v", step, err)
+		}
+		var binding v1alpha1.BackrestVolSyncBinding
+		err := c.Get(ctx, bindingKey, &binding)
+		if wantBinding && err != nil {
+			t.Fatalf("%s: expected binding: %v", step, err)
+		}
+		if !wantBinding && err == nil {
+			t.Fatalf("%s: expected no binding", step)
+		}
+	}
+
+	reconcileAndCheck("namespace not selected", false)
+
+	ns.Labels = map[string]string{"backup": "enabled"}
+	if err := c.Update(ctx, ns); err != nil {
+		t.Fatalf("update namespace: %v", err)
+	}
+	if reqs := r.volSyncObjectsInNamespace(ctx, ns.Name); len(reqs) != 1 || reqs[0] != req {
+		t.Fatalf("expected namespace change to enqueue the VolSync object, got %v", reqs)
+	}
+	reconcileAndCheck("namespace selected", true)
+
+	vs.SetLabels(map[string]string{"tier": "scratch"})
+	if err := c.Update(ctx, vs); err != nil {
+		t.Fatalf("update volsync object: %v", err)
+	}
+	reconcileAndCheck("object deselected", false)
+
+	vs.SetLabels(nil)
+	if err := c.Update(ctx, vs); err != nil {
+		t.Fatalf("update volsync object: %v", err)
+	}
+	reconcileAndCheck("object selected again", true)
+
+	ns.Labels = nil
+	if err := c.Update(ctx, ns); err != nil {
+		t.Fatalf("update namespace: %v", err)
+	}
+	reconcileAndCheck("namespace deselected", false)
+}
+
+func TestVolSyncAutoBindingReconcile_OrphanPolicy(t *testing.T) {
+	tests := []struct {
+		name       string
+		policy     string
+		mutate     func(cfg *v1alpha1.BackrestVolSyncOperatorConfig, vs *unstructured.Unstructured)
+		wantReason string
+	}{
+		{
+			name: "policy disabled, keep",
+			mutate: func(cfg *v1alpha1.BackrestVolSyncOperatorConfig, _ *unstructured.Unstructured) {
+				cfg.Spec.BindingGeneration.Policy = string(BindingPolicyDisabled)
+			},
+		},
+		{
+			name:   "policy disabled, detach",
+			policy: string(OrphanPolicyDetach),
+			mutate: func(cfg *v1alpha1.BackrestVolSyncOperatorConfig, _ *unstructured.Unstructured) {
+				cfg.Spec.BindingGeneration.Policy = string(BindingPolicyDisabled)
+			},
+			wantReason: "BindingDetached",
+		},
+		{
+			name:   "kind removed, delete",
+			policy: string(OrphanPolicyDelete),
+			mutate: func(cfg *v1alpha1.BackrestVolSyncOperatorConfig, _ *unstructured.Unstructured) {
+				cfg.Spec.BindingGeneration.Kinds = []string{"ReplicationDestination"}
+			},
+			wantReason: "BindingDeleted",
+		},
+		{
+			name:   "opt-out annotation, delete",
+			policy: string(OrphanPolicyDelete),
+			mutate: func(_ *v1alpha1.BackrestVolSyncOperatorConfig, vs *unstructured.Unstructured) {
+				vs.SetAnnotations(map[string]string{annotationAutoBinding: "false"})
+			},
+			wantReason: "BindingDeleted",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			scheme := testScheme(t)
+
+			cfg := &v1alpha1.BackrestVolSyncOperatorConfig{}
+			cfg.Namespace = "backrest-volsync-operator"
+			cfg.Name = "backrest-volsync-operator"
+			cfg.Spec.BindingGeneration.Policy = string(BindingPolicyAll)
+			cfg.Spec.BindingGeneration.OrphanPolicy = tt.policy
+			cfg.Spec.DefaultBackrest.URL = "http://example.invalid"
+
+			vs := &unstructured.Unstructured{}
+			vs.SetGroupVersionKind(schema.GroupVersionKind{Group: volsync.Group, Version: volsync.Version, Kind: "ReplicationSource"})
+			vs.SetNamespace("workload")
+			vs.SetName("demo")
+			vs.SetUID(types.UID("1111"))
+
+			c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(cfg, vs).WithReturnManagedFields().Build()
+			recorder := events.NewFakeRecorder(10)
+			r := &VolSyncAutoBindingReconciler{
+				Client:         c,
+				Scheme:         scheme,
+				Recorder:       recorder,
+				OperatorConfig: types.NamespacedName{Namespace: cfg.Namespace, Name: cfg.Name},
+			}
+			req := ctrl.Request{NamespacedName: types.NamespacedName{Namespace: "workload", Name: "demo"}}
+			if _, err := r.Reconcile(ctx, req); err != nil {
+				t.Fatalf("reconcile: %v", err)
+			}
+			<-recorder.Events // BindingCreated
+
+			tt.mutate(cfg, vs)
+			if err := c.Update(ctx, cfg); err != nil {
+				t.Fatalf("update config: %v", err)
+			}
+			if err := c.Update(ctx, vs); err != nil {
+				t.Fatalf("update volsync object: %v", err)
+			}
+			if _, err := r.Reconcile(ctx, req); err != nil {
+				t.Fatalf("reconcile: %v", err)
+			}
+
+			var binding v1alpha1.BackrestVolSyncBinding
+			err := c.Get(ctx, types.NamespacedName{Namespace: "workload", Name: "bvsb-rs-demo"}, &binding)
+			switch tt.wantReason {
+			case "":
+				if err != nil {
+					t.Fatalf("expected binding kept: %v", err)
+				}
+				if binding.Labels[labelManaged] != "true" || len(binding.OwnerReferences) != 1 {
+					t.Fatalf("expected the binding left managed, got %#v", binding.ObjectMeta)
+				}
+				if e := <-recorder.Events; !strings.Contains(e, "BindingSkipped") || len(recorder.Events) != 0 {
+					t.Fatalf("expected only a BindingSkipped event, got %q", e)
+				}
+				return
+			case "BindingDeleted":
+				if err == nil {
+					t.Fatalf("expected binding deleted")
+				}
+			default:
+				if err != nil {
+					t.Fatalf("expected binding kept: %v", err)
+				}
+				if _, ok := binding.Labels[labelManaged]; ok {
+					t.Fatalf("expected managed label removed, got %v", binding.Labels)
+				}
+				if _, ok := binding.Annotations[annotationManagedBy]; ok {
+					t.Fatalf("expected managed-by annotation removed, got %v", binding.Annotations)
+				}
+				if len(binding.OwnerReferences) != 0 {
+					t.Fatalf("expected owner reference removed, got %v", binding.OwnerReferences)
+				}
+				if binding.Spec.Backrest.URL != "http://example.invalid" {
+					t.Fatalf("expected spec kept, got %#v", binding.Spec)
+				}
+				for _, entry := range binding.ManagedFields {
+					if entry.Manager == fieldManagerAutoBinding {
+						t.Fatalf("expected fields released by %s, got %v", fieldManagerAutoBinding, binding.ManagedFields)
+					}
+				}
+				if binding.Annotations[annotationDetachedFrom] != "1111" {
+					t.Fatalf("expected the object UID recorded, got %v", binding.Annotations)
+				}
+			}
+			select {
+			case e := <-recorder.Events:
+				if !strings.Contains(e, tt.wantReason) {
+					t.Fatalf("expected %s event, got %q", tt.wantReason, e)
+				}
+			default:
+				t.Fatalf("expected %s event", tt.wantReason)
+			}
+			if tt.wantReason != "BindingDetached" {
+				return
+			}
+
+			<-recorder.Events // BindingSkipped
+
+			// Once eligible again, the object adopts the binding it was detached from.
+			cfg.Spec.BindingGeneration.Policy = string(BindingPolicyAll)
+			if err := c.Update(ctx, cfg); err != nil {
+				t.Fatalf("update config: %v", err)
+			}
+			if _, err := r.Reconcile(ctx, req); err != nil {
+				t.Fatalf("reconcile: %v", err)
+			}
+			if e := <-recorder.Events; !strings.Contains(e, "BindingAdopted") {
+				t.Fatalf("expected BindingAdopted event, got %q", e)
+			}
+			if err := c.Get(ctx, types.NamespacedName{Namespace: "workload", Name: "bvsb-rs-demo"}, &binding); err != nil {
+				t.Fatalf("get binding: %v", err)
+			}
+			if binding.Labels[labelManaged] != "true" || len(binding.OwnerReferences) != 1 || binding.OwnerReferences[0].UID != "1111" {
+				t.Fatalf("expected the binding managed again, got %#v", binding.ObjectMeta)
+			}
+			if _, ok := binding.Annotations[annotationDetachedFrom]; ok {
+				t.Fatalf("expected detached-from removed, got %v", binding.Annotations)
+			}
+		})
+	}
+}
+
 func TestDesiredBindingName_Truncates(t *testing.T) {
 	long := "A" + strings.Repeat("b", 100)
 	name := desiredBindingName("ReplicationSource", long)
@@ -192,3 +434,173 @@ func TestDesiredBindingName_Truncates(t *testing.T) {
 }
 
 var _ client.Object = (*v1alpha1.BackrestVolSyncBinding)(nil)
+
+func TestVolSyncAutoBindingReconcile_ServerSideApply(t *testing.T) {
+	ctx := context.Background()
+	scheme := testScheme(t)
+
+	cfg := &v1alpha1.BackrestVolSyncOperatorConfig{}
+	cfg.Namespace = "backrest-volsync-operator"
+	cfg.Name = "backrest-volsync-operator"
+	cfg.Spec.BindingGeneration.Policy = string(BindingPolicyAll)
+	cfg.Spec.DefaultBackrest.URL = "http://example.invalid"
+
+	vs := &unstructured.Unstructured{}
+	vs.SetGroupVersionKind(schema.GroupVersionKind{Group: volsync.Group, Version: volsync.Version, Kind: "ReplicationSource"})
+	vs.SetNamespace("workload")
+	vs.SetName("demo")
+	vs.SetUID(types.UID("1111"))
+
+	var applies int
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(cfg, vs).
+		WithReturnManagedFields().
+		WithInterceptorFuncs(interceptor.Funcs{
+			Apply: func(ctx context.Context, c client.WithWatch, obj runtime.ApplyConfiguration, opts ...client.ApplyOption) error {
+				applies++
+				return c.Apply(ctx, obj, opts...)
+			},
+		}).
+		Build()
+	recorder := events.NewFakeRecorder(10)
+	r := &VolSyncAutoBindingReconciler{
+		Client:         c,
+		Scheme:         scheme,
+		Recorder:       recorder,
+		OperatorConfig: types.NamespacedName{Namespace: cfg.Namespace, Name: cfg.Name},
+	}
+	req := ctrl.Request{NamespacedName: types.NamespacedName{Namespace: "workload", Name: "demo"}}
+	bindingKey := types.NamespacedName{Namespace: "workload", Name: "bvsb-rs-demo"}
+	reconcile := func() {
+		t.Helper()
+		if _, err := r.Reconcile(ctx, req); err != nil {
+			t.Fatalf("reconcile: %v", err)
+		}
+	}
+	nextEvent := func() string {
+		select {
+		case e := <-recorder.Events:
+			return e
+		default:
+			return ""
+		}
+	}
+
+	reconcile()
+	if e := nextEvent(); !strings.Contains(e, "BindingCreated") {
+		t.Fatalf("expected BindingCreated, got %q", e)
+	}
+	reconcile()
+	if e := nextEvent(); e != "" {
+		t.Fatalf("expected no event for an unchanged binding, got %q", e)
+	}
+	if applies != 1 {
+		t.Fatalf("expected no apply for an unchanged binding, got %d applies", applies)
+	}
+
+	// A field the operator does not set stays with the user.
+	var binding v1alpha1.BackrestVolSyncBinding
+	if err := c.Get(ctx, bindingKey, &binding); err != nil {
+		t.Fatalf("get binding: %v", err)
+	}
+	binding.Spec.Repo.AutoUnlock = ptr.To(true)
+	binding.Labels["team"] = "a"
+	if err := c.Update(ctx, &binding, client.FieldOwner("kubectl-edit")); err != nil {
+		t.Fatalf("update binding: %v", err)
+	}
+	reconcile()
+	if e := nextEvent(); e != "" {
+		t.Fatalf("expected no event, got %q", e)
+	}
+	if err := c.Get(ctx, bindingKey, &binding); err != nil {
+		t.Fatalf("get binding: %v", err)
+	}
+	if !ptr.Deref(binding.Spec.Repo.AutoUnlock, false) || binding.Labels["team"] != "a" {
+		t.Fatalf("expected user-owned fields kept, got %#v %v", binding.Spec.Repo, binding.Labels)
+	}
+	if applies != 1 {
+		t.Fatalf("expected no apply after a change of user-owned fields, got %d applies", applies)
+	}
+
+	// A field the operator sets is reverted and the conflict reported.
+	binding.Spec.Backrest.URL = "http://edited"
+	if err := c.Update(ctx, &binding, client.FieldOwner("kubectl-edit")); err != nil {
+		t.Fatalf("update binding: %v", err)
+	}
+	reconcile()
+	if e := nextEvent(); !strings.Contains(e, "ApplyConflict") || !strings.Contains(e, ".spec.backrest.url") {
+		t.Fatalf("expected ApplyConflict event, got %q", e)
+	}
+	if e := nextEvent(); !strings.Contains(e, "BindingUpdated") {
+		t.Fatalf("expected BindingUpdated, got %q", e)
+	}
+	if err := c.Get(ctx, bindingKey, &binding); err != nil {
+		t.Fatalf("get binding: %v", err)
+	}
+	if binding.Spec.Backrest.URL != "http://example.invalid" || !ptr.Deref(binding.Spec.Repo.AutoUnlock, false) {
+		t.Fatalf("expected operator field reverted and user field kept, got %#v", binding.Spec)
+	}
+}
+
+func TestVolSyncAutoBindingReconcile_UpgradesLegacyManagedFields(t *testing.T) {
+	ctx := context.Background()
+	scheme := testScheme(t)
+
+	cfg := &v1alpha1.BackrestVolSyncOperatorConfig{}
+	cfg.Namespace = "backrest-volsync-operator"
+	cfg.Name = "backrest-volsync-operator"
+	cfg.Spec.BindingGeneration.Policy = string(BindingPolicyAll)
+	cfg.Spec.DefaultBackrest.URL = "http://example.invalid"
+
+	vs := &unstructured.Unstructured{}
+	vs.SetGroupVersionKind(schema.GroupVersionKind{Group: volsync.Group, Version: volsync.Version, Kind: "ReplicationSource"})
+	vs.SetNamespace("workload")
+	vs.SetName("demo")
+	vs.SetUID(types.UID("1111"))
+
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(cfg, vs).WithReturnManagedFields().Build()
+
+	// A binding created by an operator version that wrote bindings with Create and merge patches,
+	// from an override annotation that was removed since.
+	legacy := &v1alpha1.BackrestVolSyncBinding{}
+	legacy.Namespace = "workload"
+	legacy.Name = "bvsb-rs-demo"
+	legacy.Labels = map[string]string{labelManaged: "true"}
+	legacy.Annotations = map[string]string{annotationManagedBy: "backrest-volsync-operator", annotationVolSyncRef: "replicationsource/demo"}
+	legacy.Finalizers = []string{finalizerRepoCleanup}
+	legacy.Spec.Backrest.URL = "http://example.invalid"
+	legacy.Spec.Source = v1alpha1.VolSyncSourceRef{Kind: "ReplicationSource", Name: "demo"}
+	legacy.Spec.Repo.ExtraFlags = []string{"--old"}
+	setOwnerReference(legacy, vs, "ReplicationSource")
+	if err := c.Create(ctx, legacy, client.FieldOwner(legacyFieldManager)); err != nil {
+		t.Fatalf("create binding: %v", err)
+	}
+	legacy.Labels["team"] = "a"
+	if err := c.Update(ctx, legacy, client.FieldOwner("kubectl-edit")); err != nil {
+		t.Fatalf("update binding: %v", err)
+	}
+
+	r := &VolSyncAutoBindingReconciler{
+		Client:         c,
+		Scheme:         scheme,
+		OperatorConfig: types.NamespacedName{Namespace: cfg.Namespace, Name: cfg.Name},
+	}
+	if _, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: types.NamespacedName{Namespace: "workload", Name: "demo"}}); err != nil {
+		t.Fatalf("reconcile: %v", err)
+	}
+
+	var got v1alpha1.BackrestVolSyncBinding
+	if err := c.Get(ctx, client.ObjectKeyFromObject(legacy), &got); err != nil {
+		t.Fatalf("get binding: %v", err)
+	}
+	if len(got.Spec.Repo.ExtraFlags) != 0 {
+		t.Fatalf("expected the dropped field removed, got %v", got.Spec.Repo.ExtraFlags)
+	}
+	if got.Labels["team"] != "a" || len(got.Finalizers) != 1 {
+		t.Fatalf("expected the user label and the finalizer kept, got %v %v", got.Labels, got.Finalizers)
+	}
+	for _, entry := range got.ManagedFields {
+		if entry.Manager == legacyFieldManager && entry.Operation == metav1.ManagedFieldsOperationUpdate && string(entry.FieldsV1.Raw) != `{"f:metadata":{"f:finalizers":{}}}` {
+			t.Fatalf("expected the legacy manager to own the finalizers only, got %s", entry.FieldsV1.Raw)
+		}
+	}
+}
@@ -0,0 +1,233 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"sync/atomic"
+
+	"github.com/jogotcha/backrest-volsync-operator/api/v1alpha1"
+	"github.com/jogotcha/backrest-volsync-operator/pkg/volsync"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+const conditionVolSyncAvailable = "VolSyncAvailable"
+
+var (
+	crdGVK = schema.GroupVersionKind{Group: "apiextensions.k8s.io", Version: "v1", Kind: "CustomResourceDefinition"}
+
+	volsyncCRDNames = []string{
+		"replicationsources." + volsync.Group,
+		"replicationdestinations." + volsync.Group,
+	}
+
+	// volsyncGateRequest is the single work item of the gate; all watched events map to it.
+	volsyncGateRequest = reconcile.Request{NamespacedName: types.NamespacedName{Name: "volsync"}}
+)
+
+// VolSyncCRDReconciler lets the operator start before VolSync is installed. It watches the VolSync
+// CRDs, reports their availability in OperatorConfig status and readiness, and starts the
+// VolSync-dependent controllers once the CRDs are established.
+type VolSyncCRDReconciler struct {
+	client.Client
+
+	OperatorConfig types.NamespacedName
+
+	// Discover resolves the served VolSync API once the CRDs are established.
+	Discover func() (volsync.API, error)
+	// StartControllers registers the VolSync-dependent controllers. It is called until it succeeds
+	// once, so it must skip controllers an earlier, failed call registered; it may be nil when they
+	// were registered at startup.
+	StartControllers func(volsync.API) error
+
+	available   atomic.Bool
+	startFailed atomic.Bool
+	startMu     sync.Mutex
+	started     bool
+}
+
+// MarkStarted records that the VolSync-dependent controllers were registered at startup.
+func (r *VolSyncCRDReconciler) MarkStarted() {
+	r.startMu.Lock()
+	defer r.startMu.Unlock()
+	r.started = true
+	r.available.Store(true)
+}
+
+// ReadyzCheck fails while the VolSync CRDs are missing or the VolSync controllers failed to start.
+// Only the leader runs the gate, so until it reported availability the CRDs are looked up here; a
+// standby replica is ready once they are established.
+func (r *VolSyncCRDReconciler) ReadyzCheck(req *http.Request) error {
+	if r.available.Load() {
+		return nil
+	}
+	if r.startFailed.Load() {
+		return errors.New("VolSync controllers could not be started")
+	}
+	ctx := context.Background()
+	if req != nil {
+		ctx = req.Context()
+	}
+	missing, err := r.missingCRDs(ctx)
+	if err != nil {
+		return err
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("VolSync CRDs are not installed: %v", missing)
+	}
+	return nil
+}
+
+func (r *VolSyncCRDReconciler) Reconcile(ctx context.Context, _ ctrl.Request) (ctrl.Result, error) {
+	logger := log.FromContext(ctx)
+
+	missing, err := r.missingCRDs(ctx)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	cond := metav1.Condition{
+		Type:               conditionVolSyncAvailable,
+		Status:             metav1.ConditionTrue,
+		Reason:             "CRDsEstablished",
+		Message:            "VolSync CRDs are installed; VolSync controllers are running",
+		LastTransitionTime: metav1.Now(),
+	}
+	if len(missing) > 0 {
+		r.available.Store(false)
+		r.startFailed.Store(false)
+		cond.Status = metav1.ConditionFalse
+		cond.Reason = "CRDsMissing"
+		cond.Message = fmt.Sprintf("Waiting for VolSync CRDs: %v", missing)
+		logger.Info("VolSync CRDs not established; VolSync controllers not running", "missing", missing)
+	} else if err := r.startOnce(ctx); err != nil {
+		r.available.Store(false)
+		r.startFailed.Store(true)
+		cond.Status = metav1.ConditionFalse
+		cond.Reason = "StartFailed"
+		cond.Message = fmt.Sprintf("VolSync controllers could not be started (details omitted; errorHash=%s)", hashString(err.Error()))
+		if statusErr := r.setOperatorConfigCondition(ctx, cond); statusErr != nil {
+			return ctrl.Result{}, statusErr
+		}
+		return ctrl.Result{}, err
+	} else {
+		r.available.Store(true)
+		r.startFailed.Store(false)
+	}
+
+	return ctrl.Result{}, r.setOperatorConfigCondition(ctx, cond)
+}
+
+func (r *VolSyncCRDReconciler) startOnce(ctx context.Context) error {
+	r.startMu.Lock()
+	defer r.startMu.Unlock()
+	if r.started {
+		return nil
+	}
+	api := volsync.API{}
+	if r.Discover != nil {
+		discovered, err := r.Discover()
+		if err != nil {
+			return err
+		}
+		api = discovered
+	}
+	if r.StartControllers != nil {
+		if err := r.StartControllers(api); err != nil {
+			return err
+		}
+	}
+	r.started = true
+	log.FromContext(ctx).Info("VolSync CRDs established; started VolSync controllers",
+		"replicationSource", api.APIVersion(volsync.KindReplicationSource),
+		"replicationDestination", api.APIVersion(volsync.KindReplicationDestination),
+	)
+	return nil
+}
+
+// missingCRDs returns the VolSync CRDs that are absent or not yet Established.
+func (r *VolSyncCRDReconciler) missingCRDs(ctx context.Context) ([]string, error) {
+	var missing []string
+	for _, name := range volsyncCRDNames {
+		crd := &unstructured.Unstructured{}
+		crd.SetGroupVersionKind(crdGVK)
+		if err := r.Get(ctx, types.NamespacedName{Name: name}, crd); err != nil {
+			if apierrors.IsNotFound(err) {
+				missing = append(missing, name)
+				continue
+			}
+			return nil, err
+		}
+		if !crdEstablished(crd) {
+			missing = append(missing, name)
+		}
+	}
+	return missing, nil
+}
+
+func crdEstablished(crd *unstructured.Unstructured) bool {
+	conds, _, _ := unstructured.NestedSlice(crd.Object, "status", "conditions")
+	for _, c := range conds {
+		m, ok := c.(map[string]any)
+		if !ok {
+			continue
+		}
+		if m["type"] == "Established" && m["status"] == string(metav1.ConditionTrue) {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *VolSyncCRDReconciler) setOperatorConfigCondition(ctx context.Context, cond metav1.Condition) error {
+	if r.OperatorConfig.Name == "" || r.OperatorConfig.Namespace == "" {
+		return nil
+	}
+	var cfg v1alpha1.BackrestVolSyncOperatorConfig
+	if err := r.Get(ctx, r.OperatorConfig, &cfg); err != nil {
+		return client.IgnoreNotFound(err)
+	}
+	cond.ObservedGeneration = cfg.Generation
+	if !meta.SetStatusCondition(&cfg.Status.Conditions, cond) {
+		return nil
+	}
+	// A conflict is returned as an error so the single gate request is retried with backoff.
+	return r.Status().Update(ctx, &cfg)
+}
+
+func (r *VolSyncCRDReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	crd := &unstructured.Unstructured{}
+	crd.SetGroupVersionKind(crdGVK)
+	isVolSyncCRD := predicate.NewPredicateFuncs(func(obj client.Object) bool {
+		for _, name := range volsyncCRDNames {
+			if obj.GetName() == name {
+				return true
+			}
+		}
+		return false
+	})
+	toGate := handler.EnqueueRequestsFromMapFunc(func(context.Context, client.Object) []reconcile.Request {
+		return []reconcile.Request{volsyncGateRequest}
+	})
+	return ctrl.NewControllerManagedBy(mgr).
+		Named("volsync-crds").
+		Watches(crd, toGate, builder.WithPredicates(isVolSyncCRD)).
+		Watches(&v1alpha1.BackrestVolSyncOperatorConfig{}, toGate, builder.WithPredicates(predicate.NewPredicateFuncs(func(obj client.Object) bool {
+			return obj.GetNamespace() == r.OperatorConfig.Namespace && obj.GetName() == r.OperatorConfig.Name
+		}))).
+		Complete(r)
+}
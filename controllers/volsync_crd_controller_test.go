@@ -0,0 +1,139 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/jogotcha/backrest-volsync-operator/api/v1alpha1"
+	"github.com/jogotcha/backrest-volsync-operator/pkg/volsync"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func establishedCRD(name string) *unstructured.Unstructured {
+	crd := &unstructured.Unstructured{Object: map[string]any{
+		"metadata": map[string]any{"name": name},
+		"status": map[string]any{"conditions": []any{
+			map[string]any{"type": "Established", "status": "True"},
+		}},
+	}}
+	crd.SetGroupVersionKind(crdGVK)
+	return crd
+}
+
+func TestVolSyncCRDReconcile_DegradedUntilCRDsEstablished(t *testing.T) {
+	ctx := context.Background()
+	scheme := bindingTestScheme(t)
+	scheme.AddKnownTypeWithName(crdGVK, &unstructured.Unstructured{})
+
+	cfg := &v1alpha1.BackrestVolSyncOperatorConfig{}
+	cfg.Namespace = "operator"
+	cfg.Name = "config"
+
+	c := fake.NewClientBuilder().WithScheme(scheme).
+		WithStatusSubresource(&v1alpha1.BackrestVolSyncOperatorConfig{}).
+		WithObjects(cfg).
+		Build()
+
+	starts := 0
+	r := &VolSyncCRDReconciler{
+		Client:         c,
+		OperatorConfig: types.NamespacedName{Namespace: cfg.Namespace, Name: cfg.Name},
+		Discover:       func() (volsync.API, error) { return volsync.API{}, nil },
+		StartControllers: func(volsync.API) error {
+			starts++
+			return nil
+		},
+	}
+
+	if _, err := r.Reconcile(ctx, volsyncGateRequest); err != nil {
+		t.Fatalf("reconcile: %v", err)
+	}
+	if starts != 0 {
+		t.Fatalf("controllers must not start without CRDs")
+	}
+	if err := r.ReadyzCheck(nil); err == nil {
+		t.Fatalf("expected readiness to fail while degraded")
+	}
+	var got v1alpha1.BackrestVolSyncOperatorConfig
+	if err := c.Get(ctx, r.OperatorConfig, &got); err != nil {
+		t.Fatalf("get: %v", err)
+	}
+	cond := meta.FindStatusCondition(got.Status.Conditions, conditionVolSyncAvailable)
+	if cond == nil || cond.Reason != "CRDsMissing" || meta.IsStatusConditionTrue(got.Status.Conditions, conditionVolSyncAvailable) {
+		t.Fatalf("expected VolSyncAvailable=False/CRDsMissing, got %#v", cond)
+	}
+
+	// Only one CRD established: still degraded.
+	if err := c.Create(ctx, establishedCRD(volsyncCRDNames[0])); err != nil {
+		t.Fatalf("create crd: %v", err)
+	}
+	if _, err := r.Reconcile(ctx, volsyncGateRequest); err != nil {
+		t.Fatalf("reconcile #2: %v", err)
+	}
+	if starts != 0 {
+		t.Fatalf("controllers must not start with a missing CRD")
+	}
+
+	if err := c.Create(ctx, establishedCRD(volsyncCRDNames[1])); err != nil {
+		t.Fatalf("create crd: %v", err)
+	}
+	for i := 0; i < 2; i++ {
+		if _, err := r.Reconcile(ctx, volsyncGateRequest); err != nil {
+			t.Fatalf("reconcile: %v", err)
+		}
+	}
+	if starts != 1 {
+		t.Fatalf("expected controllers started once, got %d", starts)
+	}
+	if err := r.ReadyzCheck(nil); err != nil {
+		t.Fatalf("expected ready: %v", err)
+	}
+	if err := c.Get(ctx, r.OperatorConfig, &got); err != nil {
+		t.Fatalf("get: %v", err)
+	}
+	if !meta.IsStatusConditionTrue(got.Status.Conditions, conditionVolSyncAvailable) {
+		t.Fatalf("expected VolSyncAvailable=True, got %#v", got.Status.Conditions)
+	}
+}
+
+func TestVolSyncCRDReadyzCheck(t *testing.T) {
+	ctx := context.Background()
+	scheme := bindingTestScheme(t)
+	scheme.AddKnownTypeWithName(crdGVK, &unstructured.Unstructured{})
+	c := fake.NewClientBuilder().WithScheme(scheme).Build()
+
+	// A standby replica never runs the gate; it looks the CRDs up itself.
+	standby := &VolSyncCRDReconciler{Client: c}
+	if err := standby.ReadyzCheck(nil); err == nil {
+		t.Fatalf("expected readiness to fail without CRDs")
+	}
+	for _, name := range volsyncCRDNames {
+		if err := c.Create(ctx, establishedCRD(name)); err != nil {
+			t.Fatalf("create crd: %v", err)
+		}
+	}
+	if err := standby.ReadyzCheck(nil); err != nil {
+		t.Fatalf("expected a standby replica ready once the CRDs exist: %v", err)
+	}
+
+	// On the leader a failed start keeps the replica not ready until a retry succeeds.
+	startErr := errors.New("duplicate controller")
+	leader := &VolSyncCRDReconciler{Client: c, StartControllers: func(volsync.API) error { return startErr }}
+	if _, err := leader.Reconcile(ctx, volsyncGateRequest); err == nil {
+		t.Fatalf("expected the start error returned")
+	}
+	if err := leader.ReadyzCheck(nil); err == nil {
+		t.Fatalf("expected readiness to fail after a failed start")
+	}
+	startErr = nil
+	if _, err := leader.Reconcile(ctx, volsyncGateRequest); err != nil {
+		t.Fatalf("reconcile: %v", err)
+	}
+	if err := leader.ReadyzCheck(nil); err != nil {
+		t.Fatalf("expected ready after the retry: %v", err)
+	}
+}
@@ -0,0 +1,118 @@
+package controllers
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/jogotcha/backrest-volsync-operator/api/v1alpha1"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/utils/ptr"
+)
+
+// Annotations on ReplicationSource/ReplicationDestination objects that override fields of the
+// generated binding. Only overrides listed in bindingGeneration.allowedOverrides are honored.
+const (
+	annotationOverrideRepoID           = "backrest.garethgeorge.com/repo-id"
+	annotationOverrideTriggerTasks     = "backrest.garethgeorge.com/trigger-tasks-on-snapshot"
+	annotationOverrideEnvAllowlist     = "backrest.garethgeorge.com/env-allowlist"
+	annotationOverrideExtraFlags       = "backrest.garethgeorge.com/extra-flags"
+	annotationOverrideBackrestInstance = "backrest.garethgeorge.com/backrest-instance"
+)
+
+// Override names accepted in bindingGeneration.allowedOverrides.
+const (
+	OverrideRepoID           = "repoID"
+	OverrideTriggerTasks     = "triggerTasksOnSnapshot"
+	OverrideEnvAllowlist     = "envAllowlist"
+	OverrideExtraFlags       = "extraFlags"
+	OverrideBackrestInstance = "backrestInstance"
+)
+
+type volSyncOverride struct {
+	name       string
+	annotation string
+	// apply sets the override on the binding spec, or returns why the value is invalid.
+	apply func(spec *v1alpha1.BackrestVolSyncBindingSpec, value string) error
+}
+
+// volSyncOverrides are applied in this order, so rejections are reported deterministically.
+var volSyncOverrides = []volSyncOverride{
+	{name: OverrideRepoID, annotation: annotationOverrideRepoID, apply: func(spec *v1alpha1.BackrestVolSyncBindingSpec, value string) error {
+		if !repoIDPattern.MatchString(value) || len(value) > maxRepoIDLength {
+			return fmt.Errorf("must be at most %d letters, digits, '.', '_' or '-'", maxRepoIDLength)
+		}
+		spec.Repo.IDOverride = value
+		return nil
+	}},
+	{name: OverrideTriggerTasks, annotation: annotationOverrideTriggerTasks, apply: func(spec *v1alpha1.BackrestVolSyncBindingSpec, value string) error {
+		v, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("must be true or false")
+		}
+		spec.Repo.TriggerTasksOnSnapshot = ptr.To(v)
+		return nil
+	}},
+	{name: OverrideEnvAllowlist, annotation: annotationOverrideEnvAllowlist, apply: func(spec *v1alpha1.BackrestVolSyncBindingSpec, value string) error {
+		spec.Repo.EnvAllowlist = splitOverrideList(value)
+		return nil
+	}},
+	{name: OverrideExtraFlags, annotation: annotationOverrideExtraFlags, apply: func(spec *v1alpha1.BackrestVolSyncBindingSpec, value string) error {
+		flags := splitOverrideList(value)
+		for _, f := range flags {
+			if !strings.HasPrefix(f, "-") {
+				return fmt.Errorf("flag %q must start with '-'", f)
+			}
+		}
+		spec.Repo.ExtraFlags = flags
+		return nil
+	}},
+	{name: OverrideBackrestInstance, annotation: annotationOverrideBackrestInstance, apply: func(spec *v1alpha1.BackrestVolSyncBindingSpec, value string) error {
+		if errs := validation.IsDNS1123Subdomain(value); len(errs) > 0 {
+			return fmt.Errorf("must be a BackrestInstance name: %s", strings.Join(errs, "; "))
+		}
+		spec.Backrest = v1alpha1.BackrestConnection{InstanceRef: &v1alpha1.InstanceRef{Name: value}}
+		return nil
+	}},
+}
+
+func isOverrideName(name string) bool {
+	for _, o := range volSyncOverrides {
+		if o.name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// splitOverrideList splits a comma-separated annotation value, dropping empty entries.
+func splitOverrideList(value string) []string {
+	var out []string
+	for _, v := range strings.Split(value, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			out = append(out, v)
+		}
+	}
+	return out
+}
+
+// applyVolSyncOverrides applies the override annotations of a VolSync object to the spec of its
+// generated binding. It returns one message per override that is not allowed or not valid; those
+// leave the spec unchanged.
+func applyVolSyncOverrides(spec *v1alpha1.BackrestVolSyncBindingSpec, allowed map[string]bool, annotations map[string]string) []string {
+	var rejected []string
+	for _, o := range volSyncOverrides {
+		value, ok := annotations[o.annotation]
+		if !ok {
+			continue
+		}
+		if !allowed[o.name] {
+			rejected = append(rejected, fmt.Sprintf("annotation %s ignored: override %s is not in bindingGeneration.allowedOverrides", o.annotation, o.name))
+			continue
+		}
+		if err := o.apply(spec, strings.TrimSpace(value)); err != nil {
+			rejected = append(rejected, fmt.Sprintf("annotation %s ignored: %v", o.annotation, err))
+		}
+	}
+	return rejected
+}
@@ -0,0 +1,137 @@
+package controllers
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/jogotcha/backrest-volsync-operator/api/v1alpha1"
+	"github.com/jogotcha/backrest-volsync-operator/pkg/volsync"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/events"
+	"k8s.io/utils/ptr"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestApplyVolSyncOverrides(t *testing.T) {
+	all := map[string]bool{
+		OverrideRepoID: true, OverrideTriggerTasks: true, OverrideEnvAllowlist: true,
+		OverrideExtraFlags: true, OverrideBackrestInstance: true,
+	}
+	base := v1alpha1.BackrestVolSyncBindingSpec{
+		Backrest: v1alpha1.BackrestConnection{URL: "http://cluster"},
+		Repo:     v1alpha1.BackrestRepoSpec{ExtraFlags: []string{"--cluster"}},
+	}
+	tests := []struct {
+		name         string
+		allowed      map[string]bool
+		annotations  map[string]string
+		want         v1alpha1.BackrestVolSyncBindingSpec
+		wantRejected int
+	}{
+		{name: "no annotations", allowed: all, want: base},
+		{
+			name:    "all overrides",
+			allowed: all,
+			annotations: map[string]string{
+				annotationOverrideRepoID:           "team-a-db",
+				annotationOverrideTriggerTasks:     "true",
+				annotationOverrideEnvAllowlist:     "AWS_REGION, ,B2_ACCOUNT_ID",
+				annotationOverrideExtraFlags:       "--option=s3.storage-class=STANDARD_IA,--limit-upload=1024",
+				annotationOverrideBackrestInstance: "team-a",
+			},
+			want: v1alpha1.BackrestVolSyncBindingSpec{
+				Backrest: v1alpha1.BackrestConnection{InstanceRef: &v1alpha1.InstanceRef{Name: "team-a"}},
+				Repo: v1alpha1.BackrestRepoSpec{
+					IDOverride:             "team-a-db",
+					TriggerTasksOnSnapshot: ptr.To(true),
+					EnvAllowlist:           []string{"AWS_REGION", "B2_ACCOUNT_ID"},
+					ExtraFlags:             []string{"--option=s3.storage-class=STANDARD_IA", "--limit-upload=1024"},
+				},
+			},
+		},
+		{
+			name:         "not allowed",
+			allowed:      map[string]bool{OverrideTriggerTasks: true},
+			annotations:  map[string]string{annotationOverrideRepoID: "team-a-db", annotationOverrideTriggerTasks: "false"},
+			want:         v1alpha1.BackrestVolSyncBindingSpec{Backrest: base.Backrest, Repo: v1alpha1.BackrestRepoSpec{ExtraFlags: []string{"--cluster"}, TriggerTasksOnSnapshot: ptr.To(false)}},
+			wantRejected: 1,
+		},
+		{
+			name:    "invalid values",
+			allowed: all,
+			annotations: map[string]string{
+				annotationOverrideRepoID:           "team a/db",
+				annotationOverrideTriggerTasks:     "sometimes",
+				annotationOverrideExtraFlags:       "--ok,rm",
+				annotationOverrideBackrestInstance: "Team_A",
+			},
+			want:         base,
+			wantRejected: 4,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			spec := base
+			rejected := applyVolSyncOverrides(&spec, tt.allowed, tt.annotations)
+			if len(rejected) != tt.wantRejected {
+				t.Fatalf("expected %d rejections, got %v", tt.wantRejected, rejected)
+			}
+			if !reflect.DeepEqual(spec, tt.want) {
+				t.Fatalf("got %#v, want %#v", spec, tt.want)
+			}
+		})
+	}
+}
+
+func TestVolSyncAutoBindingReconcile_Overrides(t *testing.T) {
+	ctx := context.Background()
+	scheme := testScheme(t)
+
+	cfg := &v1alpha1.BackrestVolSyncOperatorConfig{}
+	cfg.Namespace = "backrest-volsync-operator"
+	cfg.Name = "backrest-volsync-operator"
+	cfg.Spec.BindingGeneration.Policy = string(BindingPolicyAll)
+	cfg.Spec.BindingGeneration.AllowedOverrides = []string{OverrideTriggerTasks}
+	cfg.Spec.DefaultBackrest.URL = "http://example.invalid"
+
+	vs := &unstructured.Unstructured{}
+	vs.SetGroupVersionKind(schema.GroupVersionKind{Group: volsync.Group, Version: volsync.Version, Kind: "ReplicationSource"})
+	vs.SetNamespace("workload")
+	vs.SetName("demo")
+	vs.SetUID(types.UID("1111"))
+	vs.SetAnnotations(map[string]string{
+		annotationOverrideTriggerTasks:     "true",
+		annotationOverrideBackrestInstance: "team-a",
+	})
+
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(cfg, vs).Build()
+	recorder := events.NewFakeRecorder(10)
+	r := &VolSyncAutoBindingReconciler{
+		Client:         c,
+		Scheme:         scheme,
+		Recorder:       recorder,
+		OperatorConfig: types.NamespacedName{Namespace: cfg.Namespace, Name: cfg.Name},
+	}
+	if _, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: types.NamespacedName{Namespace: "workload", Name: "demo"}}); err != nil {
+		t.Fatalf("reconcile: %v", err)
+	}
+
+	var binding v1alpha1.BackrestVolSyncBinding
+	if err := c.Get(ctx, types.NamespacedName{Namespace: "workload", Name: "bvsb-rs-demo"}, &binding); err != nil {
+		t.Fatalf("get binding: %v", err)
+	}
+	if !ptr.Deref(binding.Spec.Repo.TriggerTasksOnSnapshot, false) {
+		t.Fatalf("expected allowed override applied")
+	}
+	if binding.Spec.Backrest.URL != "http://example.invalid" || binding.Spec.Backrest.InstanceRef != nil {
+		t.Fatalf("expected rejected override ignored, got %#v", binding.Spec.Backrest)
+	}
+	if e := <-recorder.Events; !strings.Contains(e, "OverrideRejected") || !strings.Contains(e, annotationOverrideBackrestInstance) {
+		t.Fatalf("expected OverrideRejected event, got %q", e)
+	}
+}
@@ -2,13 +2,19 @@ package backrest
 
 import (
 	"context"
+	"crypto/tls"
+	"crypto/x509"
 	"encoding/base64"
+	"errors"
+	"fmt"
 	"net/http"
 	"time"
 
 	"connectrpc.com/connect"
 	v1 "github.com/garethgeorge/backrest/gen/go/v1"
 	"github.com/garethgeorge/backrest/gen/go/v1/v1connect"
+	"google.golang.org/protobuf/types/known/emptypb"
+	"k8s.io/client-go/util/flowcontrol"
 )
 
 const clientTimeout = 2 * time.Minute
@@ -19,14 +25,52 @@ type Auth struct {
 	BearerToken   string
 }
 
+// Options tunes the HTTP transport used to talk to Backrest. The zero value matches New.
+type Options struct {
+	// Timeout overrides the default 2 minute request timeout when non-zero.
+	Timeout time.Duration
+
+	InsecureSkipVerify bool
+	// CAData holds PEM encoded CA certificates used to verify the server.
+	CAData     []byte
+	ServerName string
+
+	// RateLimiter, when set, is waited on before every request. Share one limiter
+	// between clients to cap the total request rate against an instance.
+	RateLimiter flowcontrol.RateLimiter
+}
+
 type Client struct {
 	backrest v1connect.BackrestClient
 }
 
 func New(baseURL string, auth Auth) *Client {
-	httpClient := &http.Client{Timeout: clientTimeout}
-	interceptors := connect.WithInterceptors(authInterceptor(auth))
-	return &Client{backrest: v1connect.NewBackrestClient(httpClient, baseURL, interceptors)}
+	c, _ := NewWithOptions(baseURL, auth, Options{})
+	return c
+}
+
+func NewWithOptions(baseURL string, auth Auth, opts Options) (*Client, error) {
+	timeout := clientTimeout
+	if opts.Timeout > 0 {
+		timeout = opts.Timeout
+	}
+	httpClient := &http.Client{Timeout: timeout}
+	if opts.InsecureSkipVerify || len(opts.CAData) > 0 || opts.ServerName != "" {
+		// nolint: gosec // InsecureSkipVerify is an explicit user opt-in.
+		tlsConfig := &tls.Config{InsecureSkipVerify: opts.InsecureSkipVerify, ServerName: opts.ServerName}
+		if len(opts.CAData) > 0 {
+			pool := x509.NewCertPool()
+			if !pool.AppendCertsFromPEM(opts.CAData) {
+				return nil, fmt.Errorf("no valid PEM certificates in CA data")
+			}
+			tlsConfig.RootCAs = pool
+		}
+		transport := http.DefaultTransport.(*http.Transport).Clone()
+		transport.TLSClientConfig = tlsConfig
+		httpClient.Transport = transport
+	}
+	interceptors := connect.WithInterceptors(rateLimitInterceptor(opts.RateLimiter), authInterceptor(auth))
+	return &Client{backrest: v1connect.NewBackrestClient(httpClient, baseURL, interceptors)}, nil
 }
 
 func (c *Client) AddRepo(ctx context.Context, repo *v1.Repo) (*v1.Config, error) {
@@ -37,11 +81,55 @@ func (c *Client) AddRepo(ctx context.Context, repo *v1.Repo) (*v1.Config, error)
 	return resp.Msg, nil
 }
 
+// CheckRepoExists asks Backrest to open the repository with the given URI, password and env.
+// A wrong password is reported as an error; a missing repository as exists=false.
+func (c *Client) CheckRepoExists(ctx context.Context, repo *v1.Repo) (bool, error) {
+	resp, err := c.backrest.CheckRepoExists(ctx, connect.NewRequest(&v1.CheckRepoExistsRequest{Repo: repo}))
+	if err != nil {
+		return false, err
+	}
+	if resp.Msg.GetHostKeyUntrusted() {
+		return false, fmt.Errorf("repository host key is not trusted by Backrest")
+	}
+	if msg := resp.Msg.GetError(); msg != "" {
+		return false, errors.New(msg)
+	}
+	return resp.Msg.GetExists(), nil
+}
+
+// RemoveRepo removes a repo from the Backrest config and deletes its operation history. The
+// restic repository itself is not touched.
+func (c *Client) RemoveRepo(ctx context.Context, repoID string) error {
+	_, err := c.backrest.RemoveRepo(ctx, connect.NewRequest(&v1.RemoveRepoRequest{RepoId: repoID}))
+	return err
+}
+
 func (c *Client) DoRepoTask(ctx context.Context, repoID string, task v1.DoRepoTaskRequest_Task) error {
 	_, err := c.backrest.DoRepoTask(ctx, connect.NewRequest(&v1.DoRepoTaskRequest{RepoId: repoID, Task: task}))
 	return err
 }
 
+func (c *Client) GetConfig(ctx context.Context) (*v1.Config, error) {
+	resp, err := c.backrest.GetConfig(ctx, connect.NewRequest(&emptypb.Empty{}))
+	if err != nil {
+		return nil, err
+	}
+	return resp.Msg, nil
+}
+
+func rateLimitInterceptor(limiter flowcontrol.RateLimiter) connect.UnaryInterceptorFunc {
+	return func(next connect.UnaryFunc) connect.UnaryFunc {
+		return func(ctx context.Context, req connect.AnyRequest) (connect.AnyResponse, error) {
+			if limiter != nil {
+				if err := limiter.Wait(ctx); err != nil {
+					return nil, err
+				}
+			}
+			return next(ctx, req)
+		}
+	}
+}
+
 func authInterceptor(auth Auth) connect.UnaryInterceptorFunc {
 	return func(next connect.UnaryFunc) connect.UnaryFunc {
 		return func(ctx context.Context, req connect.AnyRequest) (connect.AnyResponse, error) {
@@ -0,0 +1,149 @@
+package volsync
+
+import (
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/discovery"
+)
+
+const (
+	KindReplicationSource      = "ReplicationSource"
+	KindReplicationDestination = "ReplicationDestination"
+)
+
+// resources maps the VolSync kinds to their API resource names.
+var resources = map[string]string{
+	KindReplicationSource:      "replicationsources",
+	KindReplicationDestination: "replicationdestinations",
+}
+
+// Accessor reads the VolSync fields the operator needs from objects of one API version.
+type Accessor interface {
+	RepositorySecretName(obj *unstructured.Unstructured) (string, error)
+	PVCName(obj *unstructured.Unstructured) (string, error)
+	ReplicationSourceCompletionMarker(obj *unstructured.Unstructured) (string, string, bool, error)
+	ReplicationDestinationCompletionMarker(obj *unstructured.Unstructured) (string, string, bool, error)
+	SyncStatus(obj *unstructured.Unstructured) (SyncStatus, error)
+}
+
+// accessors lists the API versions whose field layout is known.
+var accessors = map[string]Accessor{
+	"v1alpha1": v1alpha1Accessor{},
+}
+
+// IsKnownVersion reports whether the field layout of a VolSync API version is known.
+func IsKnownVersion(version string) bool {
+	_, ok := accessors[version]
+	return ok
+}
+
+// AccessorFor returns the accessor for an object's API version. Unknown versions fall back to the
+// v1alpha1 layout (best effort); ok is false in that case so callers can report it.
+func AccessorFor(obj *unstructured.Unstructured) (Accessor, bool) {
+	if obj != nil {
+		if a, ok := accessors[obj.GroupVersionKind().Version]; ok {
+			return a, true
+		}
+	}
+	return accessors[Version], false
+}
+
+// API holds the served VolSync version per kind. The zero value uses Version for every kind.
+type API struct {
+	versions map[string]string
+}
+
+// NewAPI returns an API using the given kind->version mapping; missing kinds use Version.
+func NewAPI(versions map[string]string) API {
+	return API{versions: versions}
+}
+
+// Version returns the API version used for kind.
+func (a API) Version(kind string) string {
+	if v := a.versions[kind]; v != "" {
+		return v
+	}
+	return Version
+}
+
+func (a API) GVK(kind string) schema.GroupVersionKind {
+	return schema.GroupVersionKind{Group: Group, Version: a.Version(kind), Kind: kind}
+}
+
+func (a API) ListGVK(kind string) schema.GroupVersionKind {
+	return schema.GroupVersionKind{Group: Group, Version: a.Version(kind), Kind: kind + "List"}
+}
+
+// APIVersion returns "group/version" for kind, as used in apiVersion and owner references.
+func (a API) APIVersion(kind string) string {
+	return Group + "/" + a.Version(kind)
+}
+
+// NewObject returns an empty unstructured object of kind at the served version.
+func (a API) NewObject(kind string) *unstructured.Unstructured {
+	u := &unstructured.Unstructured{}
+	u.SetGroupVersionKind(a.GVK(kind))
+	return u
+}
+
+// UnknownVersions lists kinds whose served version has no known field layout, as kind=version.
+func (a API) UnknownVersions() []string {
+	var out []string
+	for _, kind := range []string{KindReplicationSource, KindReplicationDestination} {
+		if v := a.Version(kind); !IsKnownVersion(v) {
+			out = append(out, kind+"="+v)
+		}
+	}
+	return out
+}
+
+// Discover finds the served version of each VolSync kind: the group's preferred version when it
+// serves the resource, otherwise the first other version that does.
+func Discover(dc discovery.DiscoveryInterface) (API, error) {
+	groups, err := dc.ServerGroups()
+	if err != nil {
+		return API{}, fmt.Errorf("discover API groups: %w", err)
+	}
+	var versions []string
+	for _, g := range groups.Groups {
+		if g.Name != Group {
+			continue
+		}
+		versions = append(versions, g.PreferredVersion.Version)
+		for _, v := range g.Versions {
+			if v.Version != g.PreferredVersion.Version {
+				versions = append(versions, v.Version)
+			}
+		}
+	}
+	if len(versions) == 0 {
+		return API{}, fmt.Errorf("API group %s is not served", Group)
+	}
+
+	found := map[string]string{}
+	for _, v := range versions {
+		list, err := dc.ServerResourcesForGroupVersion(Group + "/" + v)
+		if err != nil {
+			return API{}, fmt.Errorf("discover %s/%s resources: %w", Group, v, err)
+		}
+		for kind, resource := range resources {
+			if found[kind] != "" {
+				continue
+			}
+			for _, r := range list.APIResources {
+				if r.Name == resource {
+					found[kind] = v
+					break
+				}
+			}
+		}
+	}
+	for kind, resource := range resources {
+		if found[kind] == "" {
+			return API{}, fmt.Errorf("resource %s.%s is not served", resource, Group)
+		}
+	}
+	return NewAPI(found), nil
+}
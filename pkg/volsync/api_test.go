@@ -0,0 +1,90 @@
+package volsync
+
+import (
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	fakediscovery "k8s.io/client-go/discovery/fake"
+	clienttesting "k8s.io/client-go/testing"
+)
+
+func fakeDiscovery(resources ...*metav1.APIResourceList) *fakediscovery.FakeDiscovery {
+	return &fakediscovery.FakeDiscovery{Fake: &clienttesting.Fake{Resources: resources}}
+}
+
+func TestDiscover(t *testing.T) {
+	t.Run("preferred version serves both kinds", func(t *testing.T) {
+		dc := fakeDiscovery(
+			&metav1.APIResourceList{GroupVersion: Group + "/v1beta1", APIResources: []metav1.APIResource{
+				{Name: "replicationsources"}, {Name: "replicationdestinations"},
+			}},
+			&metav1.APIResourceList{GroupVersion: Group + "/v1alpha1", APIResources: []metav1.APIResource{
+				{Name: "replicationsources"}, {Name: "replicationdestinations"},
+			}},
+		)
+		api, err := Discover(dc)
+		if err != nil {
+			t.Fatalf("discover: %v", err)
+		}
+		if got := api.Version(KindReplicationSource); got != "v1beta1" {
+			t.Fatalf("expected v1beta1, got %q", got)
+		}
+		if got := api.UnknownVersions(); len(got) != 2 {
+			t.Fatalf("expected both kinds reported as unknown layout, got %v", got)
+		}
+	})
+
+	t.Run("falls back to other version per kind", func(t *testing.T) {
+		dc := fakeDiscovery(
+			&metav1.APIResourceList{GroupVersion: Group + "/v1beta1", APIResources: []metav1.APIResource{
+				{Name: "replicationsources"},
+			}},
+			&metav1.APIResourceList{GroupVersion: Group + "/v1alpha1", APIResources: []metav1.APIResource{
+				{Name: "replicationsources"}, {Name: "replicationdestinations"},
+			}},
+		)
+		api, err := Discover(dc)
+		if err != nil {
+			t.Fatalf("discover: %v", err)
+		}
+		if api.Version(KindReplicationSource) != "v1beta1" || api.Version(KindReplicationDestination) != "v1alpha1" {
+			t.Fatalf("unexpected versions: rs=%s rd=%s", api.Version(KindReplicationSource), api.Version(KindReplicationDestination))
+		}
+		if gvk := api.ListGVK(KindReplicationDestination); gvk.Kind != "ReplicationDestinationList" || gvk.Version != "v1alpha1" {
+			t.Fatalf("unexpected list gvk %v", gvk)
+		}
+	})
+
+	t.Run("group not served", func(t *testing.T) {
+		if _, err := Discover(fakeDiscovery()); err == nil {
+			t.Fatalf("expected error")
+		}
+	})
+}
+
+func TestAPI_ZeroValueUsesDefaultVersion(t *testing.T) {
+	var api API
+	if got := api.APIVersion(KindReplicationSource); got != Group+"/"+Version {
+		t.Fatalf("expected default apiVersion, got %q", got)
+	}
+	if got := api.UnknownVersions(); len(got) != 0 {
+		t.Fatalf("expected no unknown versions, got %v", got)
+	}
+}
+
+func TestAccessorFor_UnknownVersionFallsBack(t *testing.T) {
+	obj := &unstructured.Unstructured{Object: map[string]any{
+		"apiVersion": Group + "/v9",
+		"kind":       KindReplicationSource,
+		"spec":       map[string]any{"restic": map[string]any{"repository": "repo-secret"}},
+	}}
+	a, ok := AccessorFor(obj)
+	if ok {
+		t.Fatalf("expected unknown version to be reported")
+	}
+	name, err := a.RepositorySecretName(obj)
+	if err != nil || name != "repo-secret" {
+		t.Fatalf("expected fallback accessor to read repository, got %q %v", name, err)
+	}
+}
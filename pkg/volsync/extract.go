@@ -8,11 +8,51 @@ import (
 )
 
 const (
-	Group   = "volsync.backube"
+	Group = "volsync.backube"
+	// Version is the default VolSync API version, used when the served version is not discovered.
 	Version = "v1alpha1"
 )
 
+// RepositorySecretName returns the name of the restic repository Secret of a VolSync object.
 func RepositorySecretName(obj *unstructured.Unstructured) (string, error) {
+	a, _ := AccessorFor(obj)
+	return a.RepositorySecretName(obj)
+}
+
+// PVCName returns the PVC a VolSync object syncs: spec.sourcePVC of a ReplicationSource or
+// spec.restic.destinationPVC of a ReplicationDestination. It is empty when not set (for example
+// a ReplicationDestination with a dynamically provisioned PVC).
+func PVCName(obj *unstructured.Unstructured) (string, error) {
+	a, _ := AccessorFor(obj)
+	return a.PVCName(obj)
+}
+
+// ReplicationSourceCompletionMarker returns a marker identifying the last completed sync of a
+// ReplicationSource, the last sync time, and whether a completed sync was observed.
+func ReplicationSourceCompletionMarker(obj *unstructured.Unstructured) (string, string, bool, error) {
+	a, _ := AccessorFor(obj)
+	return a.ReplicationSourceCompletionMarker(obj)
+}
+
+// ReplicationDestinationCompletionMarker returns a marker identifying the last completed sync
+// (restore) of a ReplicationDestination, the last sync time, and whether a completed sync was observed.
+func ReplicationDestinationCompletionMarker(obj *unstructured.Unstructured) (string, string, bool, error) {
+	a, _ := AccessorFor(obj)
+	return a.ReplicationDestinationCompletionMarker(obj)
+}
+
+// CompletionMarker returns the completion marker for either VolSync kind.
+func CompletionMarker(obj *unstructured.Unstructured) (string, string, bool, error) {
+	if obj != nil && obj.GetKind() == KindReplicationDestination {
+		return ReplicationDestinationCompletionMarker(obj)
+	}
+	return ReplicationSourceCompletionMarker(obj)
+}
+
+// v1alpha1Accessor reads the volsync.backube/v1alpha1 field layout.
+type v1alpha1Accessor struct{}
+
+func (v1alpha1Accessor) RepositorySecretName(obj *unstructured.Unstructured) (string, error) {
 	secretName, found, err := unstructured.NestedString(obj.Object, "spec", "restic", "repository")
 	if err != nil {
 		return "", fmt.Errorf("read spec.restic.repository: %w", err)
@@ -23,44 +63,23 @@ func RepositorySecretName(obj *unstructured.Unstructured) (string, error) {
 	return secretName, nil
 }
 
-func ReplicationSourceCompletionMarker(obj *unstructured.Unstructured) (string, string, bool, error) {
-	if obj == nil {
-		return "", "", false, fmt.Errorf("volsync object is nil")
+func (v1alpha1Accessor) PVCName(obj *unstructured.Unstructured) (string, error) {
+	path := []string{"spec", "sourcePVC"}
+	if obj.GetKind() == KindReplicationDestination {
+		path = []string{"spec", "restic", "destinationPVC"}
 	}
-	if obj.GetKind() != "ReplicationSource" {
-		return "", "", false, nil
-	}
-
-	lastSyncTime, found, err := unstructured.NestedString(obj.Object, "status", "lastSyncTime")
+	name, _, err := unstructured.NestedString(obj.Object, path...)
 	if err != nil {
-		return "", "", false, fmt.Errorf("read status.lastSyncTime: %w", err)
-	}
-	lastSyncTime = strings.TrimSpace(lastSyncTime)
-	if !found {
-		lastSyncTime = ""
+		return "", fmt.Errorf("read %s: %w", strings.Join(path, "."), err)
 	}
+	return strings.TrimSpace(name), nil
+}
 
-	for _, p := range []struct {
-		key  string
-		path []string
-	}{
-		{key: "lastSnapshotID", path: []string{"status", "lastSnapshotID"}},
-		{key: "lastSnapshot", path: []string{"status", "lastSnapshot"}},
-		{key: "latestImage", path: []string{"status", "latestImage", "name"}},
-		{key: "lastManualSync", path: []string{"status", "lastManualSync"}},
-	} {
-		v, found, err := unstructured.NestedString(obj.Object, p.path...)
-		if err != nil {
-			return "", "", false, fmt.Errorf("read %s: %w", strings.Join(p.path, "."), err)
-		}
-		v = strings.TrimSpace(v)
-		if found && v != "" {
-			return p.key + "=" + v, lastSyncTime, true, nil
-		}
-	}
-	if found && lastSyncTime != "" {
-		return "lastSyncTime=" + lastSyncTime, lastSyncTime, true, nil
-	}
+// The v1alpha1 completion markers are the default marker rules; see DefaultMarkerRules.
+func (v1alpha1Accessor) ReplicationSourceCompletionMarker(obj *unstructured.Unstructured) (string, string, bool, error) {
+	return defaultMarkerExtractor.ReplicationSourceCompletionMarker(obj)
+}
 
-	return "", "", false, nil
+func (v1alpha1Accessor) ReplicationDestinationCompletionMarker(obj *unstructured.Unstructured) (string, string, bool, error) {
+	return defaultMarkerExtractor.ReplicationDestinationCompletionMarker(obj)
 }
@@ -222,3 +222,46 @@ func TestReplicationSourceCompletionMarker(t *testing.T) {
 		}
 	})
 }
+
+func TestReplicationDestinationCompletionMarker(t *testing.T) {
+	tests := []struct {
+		name       string
+		kind       string
+		status     map[string]any
+		wantMarker string
+		wantReady  bool
+	}{
+		{name: "source ignored", kind: "ReplicationSource", status: map[string]any{"lastSyncTime": "2026-02-24T12:00:00Z"}},
+		{name: "no completed sync", kind: "ReplicationDestination", status: map[string]any{"latestImage": map[string]any{"name": "snap-1"}}},
+		{
+			name:       "lastSyncTime only",
+			kind:       "ReplicationDestination",
+			status:     map[string]any{"lastSyncTime": "2026-02-24T12:00:00Z"},
+			wantMarker: "lastSyncTime=2026-02-24T12:00:00Z",
+			wantReady:  true,
+		},
+		{
+			name: "latestImage and lastSyncTime",
+			kind: "ReplicationDestination",
+			status: map[string]any{
+				"lastSyncTime": "2026-02-24T12:00:00Z",
+				"latestImage":  map[string]any{"name": "dest-snap-1"},
+			},
+			wantMarker: "latestImage=dest-snap-1,lastSyncTime=2026-02-24T12:00:00Z",
+			wantReady:  true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			obj := &unstructured.Unstructured{Object: map[string]any{"status": tt.status}}
+			obj.SetKind(tt.kind)
+			marker, _, ready, err := ReplicationDestinationCompletionMarker(obj)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if ready != tt.wantReady || marker != tt.wantMarker {
+				t.Fatalf("got marker=%q ready=%v, want marker=%q ready=%v", marker, ready, tt.wantMarker, tt.wantReady)
+			}
+		})
+	}
+}
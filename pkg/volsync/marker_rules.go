@@ -0,0 +1,267 @@
+package volsync
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/util/jsonpath"
+)
+
+// MarkerRule reads one candidate completion marker; the marker is Name + "=" + value.
+type MarkerRule struct {
+	Name     string
+	JSONPath string
+}
+
+var (
+	// DefaultMarkerRules is the ReplicationSource marker priority of the VolSync v1alpha1 layout.
+	DefaultMarkerRules = []MarkerRule{
+		{Name: "lastSnapshotID", JSONPath: "{.status.lastSnapshotID}"},
+		{Name: "lastSnapshot", JSONPath: "{.status.lastSnapshot}"},
+		{Name: "latestImage", JSONPath: "{.status.latestImage.name}"},
+		{Name: "lastManualSync", JSONPath: "{.status.lastManualSync}"},
+	}
+	// DefaultDestinationMarkerRules is the ReplicationDestination marker priority of the VolSync
+	// v1alpha1 layout.
+	DefaultDestinationMarkerRules = []MarkerRule{
+		{Name: "latestImage", JSONPath: "{.status.latestImage.name}"},
+	}
+	// DefaultSyncTimePaths reads the last sync time of the VolSync v1alpha1 layout.
+	DefaultSyncTimePaths = []string{"{.status.lastSyncTime}"}
+
+	defaultMarkerExtractor = mustMarkerExtractor(DefaultMarkerRules, DefaultDestinationMarkerRules, DefaultSyncTimePaths)
+)
+
+// compiledPath is a validated JSONPath expression. It is parsed again for every lookup: a parsed
+// *jsonpath.JSONPath keeps evaluation state ({range} ... {end}) and must not be reused.
+type compiledPath struct {
+	expr string
+}
+
+type compiledRule struct {
+	name string
+	path compiledPath
+}
+
+// MarkerExtractor finds the completion marker and last sync time of VolSync objects using ordered
+// JSONPath rules. A nil *MarkerExtractor uses the accessor of the object's API version.
+type MarkerExtractor struct {
+	rules     []compiledRule
+	destRules []compiledRule
+	syncTime  []compiledPath
+}
+
+// NewMarkerExtractor validates and compiles the ReplicationSource marker rules, the
+// ReplicationDestination marker rules and the sync-time rules. Empty lists use the defaults, so each
+// list can be customized alone.
+func NewMarkerExtractor(rules, destinationRules []MarkerRule, syncTimePaths []string) (*MarkerExtractor, error) {
+	if len(rules) == 0 {
+		rules = DefaultMarkerRules
+	}
+	if len(destinationRules) == 0 {
+		destinationRules = DefaultDestinationMarkerRules
+	}
+	if len(syncTimePaths) == 0 {
+		syncTimePaths = DefaultSyncTimePaths
+	}
+	e := &MarkerExtractor{}
+	var err error
+	if e.rules, err = compileRules("rules", rules); err != nil {
+		return nil, err
+	}
+	if e.destRules, err = compileRules("destinationRules", destinationRules); err != nil {
+		return nil, err
+	}
+	for i, expr := range syncTimePaths {
+		p, err := compilePath(expr)
+		if err != nil {
+			return nil, fmt.Errorf("syncTime[%d]: %w", i, err)
+		}
+		e.syncTime = append(e.syncTime, p)
+	}
+	return e, nil
+}
+
+// compileRules compiles one list of marker rules; field names the list in errors.
+func compileRules(field string, rules []MarkerRule) ([]compiledRule, error) {
+	var out []compiledRule
+	seen := map[string]bool{}
+	for i, r := range rules {
+		name := strings.TrimSpace(r.Name)
+		if name == "" || strings.ContainsAny(name, "=,") {
+			return nil, fmt.Errorf("%s[%d].name %q must be non-empty and must not contain '=' or ','", field, i, r.Name)
+		}
+		if seen[name] {
+			return nil, fmt.Errorf("%s[%d].name %q is duplicated", field, i, name)
+		}
+		seen[name] = true
+		p, err := compilePath(r.JSONPath)
+		if err != nil {
+			return nil, fmt.Errorf("%s[%d].jsonPath: %w", field, i, err)
+		}
+		out = append(out, compiledRule{name: name, path: p})
+	}
+	return out, nil
+}
+
+func mustMarkerExtractor(rules, destinationRules []MarkerRule, syncTimePaths []string) *MarkerExtractor {
+	e, err := NewMarkerExtractor(rules, destinationRules, syncTimePaths)
+	if err != nil {
+		panic(err)
+	}
+	return e
+}
+
+// compilePath accepts kubectl-style expressions with or without the surrounding braces.
+func compilePath(expr string) (compiledPath, error) {
+	expr = strings.TrimSpace(expr)
+	if expr == "" {
+		return compiledPath{}, fmt.Errorf("empty JSONPath")
+	}
+	if !strings.HasPrefix(expr, "{") {
+		expr = "{" + expr + "}"
+	}
+	p := compiledPath{expr: expr}
+	if _, err := p.parse(); err != nil {
+		return compiledPath{}, err
+	}
+	return p, nil
+}
+
+func (p compiledPath) parse() (*jsonpath.JSONPath, error) {
+	jp := jsonpath.New("marker").AllowMissingKeys(true)
+	if err := jp.Parse(p.expr); err != nil {
+		return nil, fmt.Errorf("parse %q: %w", p.expr, err)
+	}
+	return jp, nil
+}
+
+// lookup returns the trimmed string the path selects, or "" when it selects nothing. Selecting more
+// than one value or a non-string value is an error.
+func (p compiledPath) lookup(obj *unstructured.Unstructured) (string, error) {
+	jp, err := p.parse()
+	if err != nil {
+		return "", err
+	}
+	results, err := jp.FindResults(obj.Object)
+	if err != nil {
+		return "", fmt.Errorf("evaluate %s: %w", p.expr, err)
+	}
+	var values []reflect.Value
+	for _, r := range results {
+		values = append(values, r...)
+	}
+	switch len(values) {
+	case 0:
+		return "", nil
+	case 1:
+	default:
+		return "", fmt.Errorf("evaluate %s: selected %d values, want one", p.expr, len(values))
+	}
+	v := values[0]
+	for v.Kind() == reflect.Interface || v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			return "", nil
+		}
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.String {
+		return "", fmt.Errorf("evaluate %s: expected a string, got %s", p.expr, v.Kind())
+	}
+	return strings.TrimSpace(v.String()), nil
+}
+
+func (e *MarkerExtractor) lastSyncTime(obj *unstructured.Unstructured) (string, error) {
+	for _, p := range e.syncTime {
+		v, err := p.lookup(obj)
+		if err != nil {
+			return "", err
+		}
+		if v != "" {
+			return v, nil
+		}
+	}
+	return "", nil
+}
+
+// firstMarker returns "<name>=<value>" for the first rule selecting a non-empty string, or "".
+func firstMarker(rules []compiledRule, obj *unstructured.Unstructured) (string, error) {
+	for _, r := range rules {
+		v, err := r.path.lookup(obj)
+		if err != nil {
+			return "", err
+		}
+		if v != "" {
+			return r.name + "=" + v, nil
+		}
+	}
+	return "", nil
+}
+
+// ReplicationSourceCompletionMarker returns the marker of the first matching rule, falling back to
+// "lastSyncTime=<time>", the last sync time, and whether a completed sync was observed.
+func (e *MarkerExtractor) ReplicationSourceCompletionMarker(obj *unstructured.Unstructured) (string, string, bool, error) {
+	if e == nil {
+		return ReplicationSourceCompletionMarker(obj)
+	}
+	if obj == nil {
+		return "", "", false, fmt.Errorf("volsync object is nil")
+	}
+	if obj.GetKind() != KindReplicationSource {
+		return "", "", false, nil
+	}
+	lastSyncTime, err := e.lastSyncTime(obj)
+	if err != nil {
+		return "", "", false, err
+	}
+	marker, err := firstMarker(e.rules, obj)
+	if err != nil {
+		return "", "", false, err
+	}
+	if marker != "" {
+		return marker, lastSyncTime, true, nil
+	}
+	if lastSyncTime != "" {
+		return "lastSyncTime=" + lastSyncTime, lastSyncTime, true, nil
+	}
+	return "", "", false, nil
+}
+
+// ReplicationDestinationCompletionMarker combines the marker of the first matching destination rule
+// with the last sync time; with copyMethod Direct, latestImage names the same PVC after every sync.
+func (e *MarkerExtractor) ReplicationDestinationCompletionMarker(obj *unstructured.Unstructured) (string, string, bool, error) {
+	if e == nil {
+		return ReplicationDestinationCompletionMarker(obj)
+	}
+	if obj == nil {
+		return "", "", false, fmt.Errorf("volsync object is nil")
+	}
+	if obj.GetKind() != KindReplicationDestination {
+		return "", "", false, nil
+	}
+	lastSyncTime, err := e.lastSyncTime(obj)
+	if err != nil {
+		return "", "", false, err
+	}
+	if lastSyncTime == "" {
+		return "", "", false, nil
+	}
+	marker, err := firstMarker(e.destRules, obj)
+	if err != nil {
+		return "", "", false, err
+	}
+	if marker != "" {
+		return marker + ",lastSyncTime=" + lastSyncTime, lastSyncTime, true, nil
+	}
+	return "lastSyncTime=" + lastSyncTime, lastSyncTime, true, nil
+}
+
+// CompletionMarker returns the completion marker for either VolSync kind.
+func (e *MarkerExtractor) CompletionMarker(obj *unstructured.Unstructured) (string, string, bool, error) {
+	if obj != nil && obj.GetKind() == KindReplicationDestination {
+		return e.ReplicationDestinationCompletionMarker(obj)
+	}
+	return e.ReplicationSourceCompletionMarker(obj)
+}
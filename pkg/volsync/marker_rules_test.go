@@ -0,0 +1,242 @@
+package volsync
+
+import (
+	"testing"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestNewMarkerExtractor_Validation(t *testing.T) {
+	tests := []struct {
+		name      string
+		rules     []MarkerRule
+		destRules []MarkerRule
+		syncTime  []string
+		wantErr   bool
+	}{
+		{name: "defaults"},
+		{name: "braces optional", rules: []MarkerRule{{Name: "snap", JSONPath: ".status.snapshot"}}, syncTime: []string{".status.finishedAt"}},
+		{name: "empty name", rules: []MarkerRule{{JSONPath: "{.status.snapshot}"}}, wantErr: true},
+		{name: "name with separator", rules: []MarkerRule{{Name: "a=b", JSONPath: "{.status.snapshot}"}}, wantErr: true},
+		{name: "duplicate name", rules: []MarkerRule{{Name: "a", JSONPath: "{.status.x}"}, {Name: "a", JSONPath: "{.status.y}"}}, wantErr: true},
+		{name: "empty path", rules: []MarkerRule{{Name: "a"}}, wantErr: true},
+		{name: "unparsable path", rules: []MarkerRule{{Name: "a", JSONPath: "{.status[}"}}, wantErr: true},
+		{name: "destination rule with separator", destRules: []MarkerRule{{Name: "a,b", JSONPath: "{.status.image}"}}, wantErr: true},
+		{name: "unparsable destination path", destRules: []MarkerRule{{Name: "a", JSONPath: "{.status[}"}}, wantErr: true},
+		{name: "unparsable sync time", syncTime: []string{"{.status.("}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewMarkerExtractor(tt.rules, tt.destRules, tt.syncTime)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err=%v, wantErr=%v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestMarkerExtractor_CompletionMarker(t *testing.T) {
+	forkRules := []MarkerRule{
+		{Name: "backup", JSONPath: "{.status.backup.id}"},
+		{Name: "lastSnapshotID", JSONPath: "{.status.lastSnapshotID}"},
+	}
+	forkDestRules := []MarkerRule{
+		{Name: "restore", JSONPath: "{.status.restore.id}"},
+		{Name: "latestImage", JSONPath: "{.status.latestImage.name}"},
+	}
+	forkSyncTime := []string{"{.status.backup.finishedAt}", "{.status.lastSyncTime}"}
+
+	tests := []struct {
+		name         string
+		rules        []MarkerRule
+		destRules    []MarkerRule
+		syncTime     []string
+		kind         string
+		status       map[string]any
+		wantMarker   string
+		wantSyncTime string
+		wantReady    bool
+		wantErr      bool
+	}{
+		{
+			name:         "defaults keep the v1alpha1 priority",
+			kind:         KindReplicationSource,
+			status:       map[string]any{"lastSyncTime": "t1", "lastSnapshot": "a", "lastManualSync": "m"},
+			wantMarker:   "lastSnapshot=a",
+			wantSyncTime: "t1",
+			wantReady:    true,
+		},
+		{
+			name:         "defaults fall back to lastSyncTime",
+			kind:         KindReplicationSource,
+			status:       map[string]any{"lastSyncTime": "t1"},
+			wantMarker:   "lastSyncTime=t1",
+			wantSyncTime: "t1",
+			wantReady:    true,
+		},
+		{
+			name:         "custom rule wins in order",
+			rules:        forkRules,
+			syncTime:     forkSyncTime,
+			kind:         KindReplicationSource,
+			status:       map[string]any{"backup": map[string]any{"id": "b-7", "finishedAt": "t2"}, "lastSnapshotID": "s", "lastSyncTime": "t1"},
+			wantMarker:   "backup=b-7",
+			wantSyncTime: "t2",
+			wantReady:    true,
+		},
+		{
+			name:         "custom rules fall through to later entries",
+			rules:        forkRules,
+			syncTime:     forkSyncTime,
+			kind:         KindReplicationSource,
+			status:       map[string]any{"backup": map[string]any{"id": " "}, "lastSnapshotID": "s", "lastSyncTime": "t1"},
+			wantMarker:   "lastSnapshotID=s",
+			wantSyncTime: "t1",
+			wantReady:    true,
+		},
+		{
+			name:     "custom rules ignore default fields",
+			rules:    forkRules,
+			syncTime: forkSyncTime,
+			kind:     KindReplicationSource,
+			status:   map[string]any{"lastSnapshot": "a"},
+		},
+		{
+			name:     "non-string value errors",
+			rules:    forkRules,
+			syncTime: forkSyncTime,
+			kind:     KindReplicationSource,
+			status:   map[string]any{"backup": map[string]any{"id": int64(7)}},
+			wantErr:  true,
+		},
+		{
+			name:    "multiple values error",
+			rules:   []MarkerRule{{Name: "ids", JSONPath: "{.status.ids[*]}"}},
+			kind:    KindReplicationSource,
+			status:  map[string]any{"ids": []any{"a", "b"}},
+			wantErr: true,
+		},
+		{
+			name:         "destination defaults combine latestImage with the sync time",
+			kind:         KindReplicationDestination,
+			status:       map[string]any{"lastSyncTime": "t1", "latestImage": map[string]any{"name": "img"}},
+			wantMarker:   "latestImage=img,lastSyncTime=t1",
+			wantSyncTime: "t1",
+			wantReady:    true,
+		},
+		{
+			name:         "destination defaults fall back to lastSyncTime",
+			kind:         KindReplicationDestination,
+			status:       map[string]any{"lastSyncTime": "t1"},
+			wantMarker:   "lastSyncTime=t1",
+			wantSyncTime: "t1",
+			wantReady:    true,
+		},
+		{
+			name:   "destination needs a sync time",
+			kind:   KindReplicationDestination,
+			status: map[string]any{"latestImage": map[string]any{"name": "img"}},
+		},
+		{
+			name:         "destination ignores source rules",
+			rules:        forkRules,
+			kind:         KindReplicationDestination,
+			status:       map[string]any{"lastSyncTime": "t1", "backup": map[string]any{"id": "b-7"}},
+			wantMarker:   "lastSyncTime=t1",
+			wantSyncTime: "t1",
+			wantReady:    true,
+		},
+		{
+			name:         "custom destination rule wins in order",
+			destRules:    forkDestRules,
+			kind:         KindReplicationDestination,
+			status:       map[string]any{"lastSyncTime": "t1", "restore": map[string]any{"id": "r-3"}, "latestImage": map[string]any{"name": "img"}},
+			wantMarker:   "restore=r-3,lastSyncTime=t1",
+			wantSyncTime: "t1",
+			wantReady:    true,
+		},
+		{
+			name:         "custom destination rules fall through to later entries",
+			destRules:    forkDestRules,
+			kind:         KindReplicationDestination,
+			status:       map[string]any{"lastSyncTime": "t1", "latestImage": map[string]any{"name": "img"}},
+			wantMarker:   "latestImage=img,lastSyncTime=t1",
+			wantSyncTime: "t1",
+			wantReady:    true,
+		},
+		{
+			name:         "custom destination rules ignore default fields",
+			destRules:    []MarkerRule{{Name: "restore", JSONPath: "{.status.restore.id}"}},
+			kind:         KindReplicationDestination,
+			status:       map[string]any{"lastSyncTime": "t1", "latestImage": map[string]any{"name": "img"}},
+			wantMarker:   "lastSyncTime=t1",
+			wantSyncTime: "t1",
+			wantReady:    true,
+		},
+		{
+			name:      "destination non-string value errors",
+			destRules: forkDestRules,
+			kind:      KindReplicationDestination,
+			status:    map[string]any{"lastSyncTime": "t1", "restore": map[string]any{"id": int64(3)}},
+			wantErr:   true,
+		},
+		{
+			name:         "destination uses custom sync time",
+			syncTime:     forkSyncTime,
+			kind:         KindReplicationDestination,
+			status:       map[string]any{"backup": map[string]any{"finishedAt": "t2"}, "latestImage": map[string]any{"name": "img"}},
+			wantMarker:   "latestImage=img,lastSyncTime=t2",
+			wantSyncTime: "t2",
+			wantReady:    true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e, err := NewMarkerExtractor(tt.rules, tt.destRules, tt.syncTime)
+			if err != nil {
+				t.Fatalf("NewMarkerExtractor: %v", err)
+			}
+			obj := &unstructured.Unstructured{Object: map[string]any{"status": tt.status}}
+			obj.SetKind(tt.kind)
+			marker, syncTime, ready, err := e.CompletionMarker(obj)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("expected error, got marker=%q", marker)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if marker != tt.wantMarker || syncTime != tt.wantSyncTime || ready != tt.wantReady {
+				t.Fatalf("got marker=%q syncTime=%q ready=%v, want marker=%q syncTime=%q ready=%v",
+					marker, syncTime, ready, tt.wantMarker, tt.wantSyncTime, tt.wantReady)
+			}
+		})
+	}
+}
+
+func TestMarkerExtractor_NilUsesAccessor(t *testing.T) {
+	var e *MarkerExtractor
+	obj := &unstructured.Unstructured{Object: map[string]any{"status": map[string]any{"lastSnapshotID": "s"}}}
+	obj.SetKind(KindReplicationSource)
+	marker, _, ready, err := e.CompletionMarker(obj)
+	if err != nil || !ready || marker != "lastSnapshotID=s" {
+		t.Fatalf("got marker=%q ready=%v err=%v", marker, ready, err)
+	}
+}
+
+func TestMarkerExtractor_RepeatedLookups(t *testing.T) {
+	e, err := NewMarkerExtractor([]MarkerRule{{Name: "x", JSONPath: "{range .status.items[*]}{.id}{end}"}}, nil, nil)
+	if err != nil {
+		t.Fatalf("NewMarkerExtractor: %v", err)
+	}
+	obj := &unstructured.Unstructured{Object: map[string]any{"status": map[string]any{"items": []any{map[string]any{"id": "a"}}}}}
+	obj.SetKind(KindReplicationSource)
+	for i := 0; i < 2; i++ {
+		marker, _, ready, err := e.CompletionMarker(obj)
+		if err != nil || !ready || marker != "x=a" {
+			t.Fatalf("lookup %d: got marker=%q ready=%v err=%v", i, marker, ready, err)
+		}
+	}
+}
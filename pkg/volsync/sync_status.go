@@ -0,0 +1,91 @@
+package volsync
+
+import (
+	"fmt"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+const (
+	// MoverResultSuccessful and MoverResultFailed are the values of status.latestMoverStatus.result.
+	MoverResultSuccessful = "Successful"
+	MoverResultFailed     = "Failed"
+
+	// ConditionSynchronizing is the VolSync condition reporting whether a sync is in progress.
+	ConditionSynchronizing = "Synchronizing"
+)
+
+// SyncStatus is the sync state of a ReplicationSource or ReplicationDestination. Empty fields were
+// not reported by VolSync.
+type SyncStatus struct {
+	LastSyncTime      string
+	LastSyncStartTime string
+	LastSyncDuration  string
+	NextSyncTime      string
+	LastManualSync    string
+	// LatestImage is the VolumeSnapshot or PVC holding the data of the last sync (status.latestImage.name).
+	LatestImage string
+	// MoverResult is the result of the latest mover run (Successful or Failed).
+	MoverResult string
+	// Synchronizing mirrors the Synchronizing condition; nil when VolSync has not set it.
+	Synchronizing *SyncCondition
+}
+
+// SyncCondition is the subset of a VolSync status condition the operator reports.
+type SyncCondition struct {
+	Status string
+	Reason string
+}
+
+// MoverFailed reports whether the latest mover run failed.
+func (s SyncStatus) MoverFailed() bool {
+	return s.MoverResult == MoverResultFailed
+}
+
+// ParseSyncStatus reads the sync status of a VolSync object.
+func ParseSyncStatus(obj *unstructured.Unstructured) (SyncStatus, error) {
+	a, _ := AccessorFor(obj)
+	return a.SyncStatus(obj)
+}
+
+func (v1alpha1Accessor) SyncStatus(obj *unstructured.Unstructured) (SyncStatus, error) {
+	if obj == nil {
+		return SyncStatus{}, fmt.Errorf("volsync object is nil")
+	}
+	var s SyncStatus
+	for _, f := range []struct {
+		dst  *string
+		path []string
+	}{
+		{dst: &s.LastSyncTime, path: []string{"status", "lastSyncTime"}},
+		{dst: &s.LastSyncStartTime, path: []string{"status", "lastSyncStartTime"}},
+		{dst: &s.LastSyncDuration, path: []string{"status", "lastSyncDuration"}},
+		{dst: &s.NextSyncTime, path: []string{"status", "nextSyncTime"}},
+		{dst: &s.LastManualSync, path: []string{"status", "lastManualSync"}},
+		{dst: &s.LatestImage, path: []string{"status", "latestImage", "name"}},
+		{dst: &s.MoverResult, path: []string{"status", "latestMoverStatus", "result"}},
+	} {
+		v, _, err := unstructured.NestedString(obj.Object, f.path...)
+		if err != nil {
+			return SyncStatus{}, fmt.Errorf("read %s: %w", strings.Join(f.path, "."), err)
+		}
+		*f.dst = strings.TrimSpace(v)
+	}
+
+	conds, _, err := unstructured.NestedSlice(obj.Object, "status", "conditions")
+	if err != nil {
+		return SyncStatus{}, fmt.Errorf("read status.conditions: %w", err)
+	}
+	for _, c := range conds {
+		m, ok := c.(map[string]any)
+		if !ok || m["type"] != ConditionSynchronizing {
+			continue
+		}
+		status, _ := m["status"].(string)
+		reason, _ := m["reason"].(string)
+		s.Synchronizing = &SyncCondition{Status: status, Reason: reason}
+		break
+	}
+	return s, nil
+}
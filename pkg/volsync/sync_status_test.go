@@ -0,0 +1,60 @@
+package volsync
+
+import (
+	"testing"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestParseSyncStatus(t *testing.T) {
+	obj := &unstructured.Unstructured{Object: map[string]any{
+		"apiVersion": Group + "/" + Version,
+		"kind":       KindReplicationSource,
+		"status": map[string]any{
+			"lastSyncTime":     "2026-01-01T00:00:00Z",
+			"lastSyncDuration": "1m30s",
+			"nextSyncTime":     "2026-01-01T01:00:00Z",
+			"lastManualSync":   "manual-1",
+			"latestMoverStatus": map[string]any{
+				"result": "Failed",
+				"logs":   "not mirrored",
+			},
+			"conditions": []any{
+				map[string]any{"type": "Other", "status": "True"},
+				map[string]any{"type": "Synchronizing", "status": "False", "reason": "WaitingForSchedule"},
+			},
+		},
+	}}
+	s, err := ParseSyncStatus(obj)
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	if s.LastSyncTime != "2026-01-01T00:00:00Z" || s.LastSyncDuration != "1m30s" || s.NextSyncTime != "2026-01-01T01:00:00Z" || s.LastManualSync != "manual-1" {
+		t.Fatalf("unexpected times: %#v", s)
+	}
+	if !s.MoverFailed() {
+		t.Fatalf("expected failed mover result, got %q", s.MoverResult)
+	}
+	if s.Synchronizing == nil || s.Synchronizing.Status != "False" || s.Synchronizing.Reason != "WaitingForSchedule" {
+		t.Fatalf("unexpected Synchronizing: %#v", s.Synchronizing)
+	}
+
+	t.Run("empty status", func(t *testing.T) {
+		s, err := ParseSyncStatus(&unstructured.Unstructured{Object: map[string]any{"kind": KindReplicationDestination}})
+		if err != nil {
+			t.Fatalf("parse: %v", err)
+		}
+		if s != (SyncStatus{}) {
+			t.Fatalf("expected zero status, got %#v", s)
+		}
+	})
+
+	t.Run("wrong type", func(t *testing.T) {
+		_, err := ParseSyncStatus(&unstructured.Unstructured{Object: map[string]any{
+			"status": map[string]any{"nextSyncTime": 5},
+		}})
+		if err == nil {
+			t.Fatalf("expected error")
+		}
+	})
+}